@@ -0,0 +1,301 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// ArchiveLimits limits the reading of an archive, including the archives
+// nested in it, to guard against archives crafted to exhaust memory or time,
+// such as zip bombs. The zero value of each field means its default.
+type ArchiveLimits struct {
+	// MaxDepth is the maximum nesting depth of archives,
+	// counting the outermost archive as 1. The default is 4.
+	MaxDepth int
+
+	// MaxBytes is the maximum number of bytes to decompress,
+	// or to read into memory to open a zip file read from a stream,
+	// from the archive and the archives nested in it. The default is 1 GB.
+	MaxBytes int64
+
+	// MaxEntries is the maximum number of entries to read
+	// from the archive and the archives nested in it. The default is 100,000.
+	MaxEntries int
+}
+
+// ErrArchiveLimit is the error wrapped by the errors returned when
+// an archive exceeds one of its ArchiveLimits.
+var ErrArchiveLimit = errors.New("archive limit exceeded")
+
+// archiveKinds maps the file name extensions of archives to their kinds.
+// A Go module zip, as served by a module proxy, is an ordinary zip file.
+var archiveKinds = map[string]string{
+	".zip":     "zip",
+	".jar":     "zip",
+	".war":     "zip",
+	".ear":     "zip",
+	".whl":     "zip",
+	".tar":     "tar",
+	".tar.gz":  "tar.gz",
+	".tgz":     "tar.gz",
+	".tar.bz2": "tar.bz2",
+	".tbz2":    "tar.bz2",
+	".tbz":     "tar.bz2",
+}
+
+// archiveKind returns the kind of archive named by file,
+// or "" if the name is not that of a known kind of archive.
+func archiveKind(file string) string {
+	base := strings.ToLower(path.Base(file))
+	if i := strings.LastIndex(base, ".tar."); i >= 0 {
+		if k, ok := archiveKinds[base[i:]]; ok {
+			return k
+		}
+	}
+	return archiveKinds[path.Ext(base)]
+}
+
+// IsArchive reports whether the file name is that of an archive
+// that ScanArchive can read: a .zip, .jar, .war, .ear, or .whl zip file,
+// or a .tar, .tar.gz, .tgz, .tar.bz2, .tbz2, or .tbz tar file.
+func IsArchive(name string) bool {
+	return archiveKind(name) != ""
+}
+
+// licenseFileRE matches the base names of conventional license files.
+var licenseFileRE = regexp.MustCompile(`(?i)^(un)?licen[cs]e|^copying|^copyright|^notice|^legal|^patents`)
+
+// ScanArchive scans the license files in the archive read from r,
+// which holds size bytes, using the built-in license set.
+// See the ScanArchive method for details.
+func ScanArchive(name string, r io.ReaderAt, size int64, limits ArchiveLimits) ([]FileCoverage, error) {
+	return builtinScanner.ScanArchive(name, r, size, limits)
+}
+
+// ScanArchiveStream scans the license files in the archive read from r,
+// using the built-in license set.
+// See the ScanArchiveStream method for details.
+func ScanArchiveStream(name string, r io.Reader, limits ArchiveLimits) ([]FileCoverage, error) {
+	return builtinScanner.ScanArchiveStream(name, r, limits)
+}
+
+// ScanArchive scans the license files in the archive read from r,
+// which holds size bytes. The kind of archive is determined by its name
+// (see IsArchive). ScanArchive returns the coverage of the license files
+// in which at least one license was found, in the order they appear
+// in the archive. The license files are those with names like
+// LICENSE, COPYING, or NOTICE, and ScanArchive descends into the
+// archives nested in the archive, such as the jar files in a war file.
+//
+// The Path of each result is the name of the archive and the path of the
+// file within it, separated by "!/", as in "archive.tar.gz!/pkg/LICENSE",
+// or "app.war!/WEB-INF/lib/lib.jar!/META-INF/LICENSE" for a nested archive.
+//
+// If the archive exceeds the limits, ScanArchive returns an error
+// wrapping ErrArchiveLimit.
+func (s *Scanner) ScanArchive(name string, r io.ReaderAt, size int64, limits ArchiveLimits) ([]FileCoverage, error) {
+	a := newArchiveScan(s, limits)
+	if err := a.readerAt(name, archiveKind(name), r, size, 1); err != nil {
+		return nil, err
+	}
+	return a.list, nil
+}
+
+// ScanArchiveStream is like ScanArchive but reads the archive from a stream.
+// A tar file is read in a single pass; a zip file, which cannot be,
+// is read into memory first.
+func (s *Scanner) ScanArchiveStream(name string, r io.Reader, limits ArchiveLimits) ([]FileCoverage, error) {
+	a := newArchiveScan(s, limits)
+	if err := a.stream(name, archiveKind(name), r, 1); err != nil {
+		return nil, err
+	}
+	return a.list, nil
+}
+
+// An archiveScan holds the state of a single call to ScanArchive.
+type archiveScan struct {
+	s       *Scanner
+	limits  ArchiveLimits
+	bytes   int64 // bytes read so far
+	entries int   // entries read so far
+	list    []FileCoverage
+}
+
+func newArchiveScan(s *Scanner, limits ArchiveLimits) *archiveScan {
+	if limits.MaxDepth <= 0 {
+		limits.MaxDepth = 4
+	}
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = 1 << 30
+	}
+	if limits.MaxEntries <= 0 {
+		limits.MaxEntries = 100000
+	}
+	return &archiveScan{s: s, limits: limits}
+}
+
+// A countReader counts the bytes read through it against the limit.
+type countReader struct {
+	a    *archiveScan
+	name string
+	r    io.Reader
+}
+
+func (c *countReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.a.bytes += int64(n)
+	if c.a.bytes > c.a.limits.MaxBytes {
+		return n, fmt.Errorf("%s: %w: more than %d bytes", c.name, ErrArchiveLimit, c.a.limits.MaxBytes)
+	}
+	return n, err
+}
+
+// counter returns a reader that counts the bytes read from r,
+// which are decompressed or read into memory from the archive name.
+func (a *archiveScan) counter(name string, r io.Reader) io.Reader {
+	return &countReader{a, name, r}
+}
+
+// entry counts an entry read from the archive name.
+func (a *archiveScan) entry(name string) error {
+	a.entries++
+	if a.entries > a.limits.MaxEntries {
+		return fmt.Errorf("%s: %w: more than %d entries", name, ErrArchiveLimit, a.limits.MaxEntries)
+	}
+	return nil
+}
+
+// entryPath returns the path of the entry named entry in the archive name,
+// as in "archive.tar.gz!/pkg/LICENSE".
+// The entry name is cleaned, so that "./pkg/LICENSE" and "pkg/x/../LICENSE"
+// give the same path in any kind of archive.
+func entryPath(name, entry string) string {
+	return name + "!/" + strings.TrimPrefix(path.Clean("/"+entry), "/")
+}
+
+// readerAt scans the archive name of the given kind read from r.
+func (a *archiveScan) readerAt(name, kind string, r io.ReaderAt, size int64, depth int) error {
+	if kind != "zip" {
+		return a.stream(name, kind, io.NewSectionReader(r, 0, size), depth)
+	}
+	if depth > a.limits.MaxDepth {
+		return fmt.Errorf("%s: %w: nested more than %d deep", name, ErrArchiveLimit, a.limits.MaxDepth)
+	}
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+	for _, f := range z.File {
+		if err := a.entry(name); err != nil {
+			return err
+		}
+		if !f.Mode().IsRegular() || archiveKind(f.Name) == "" && !licenseFileRE.MatchString(path.Base(f.Name)) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("%s: %v", entryPath(name, f.Name), err)
+		}
+		file := entryPath(name, f.Name)
+		err = a.file(file, a.counter(file, rc), depth)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stream scans the archive name of the given kind read from r.
+func (a *archiveScan) stream(name, kind string, r io.Reader, depth int) error {
+	if depth > a.limits.MaxDepth {
+		return fmt.Errorf("%s: %w: nested more than %d deep", name, ErrArchiveLimit, a.limits.MaxDepth)
+	}
+	switch kind {
+	default:
+		return fmt.Errorf("%s: unknown archive type", name)
+
+	case "zip":
+		// Reading a zip file needs random access.
+		data, err := ioutil.ReadAll(a.counter(name, r))
+		if err != nil {
+			if errors.Is(err, ErrArchiveLimit) {
+				return err
+			}
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		return a.readerAt(name, kind, bytes.NewReader(data), int64(len(data)), depth)
+
+	case "tar.gz":
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		r = a.counter(name, zr)
+
+	case "tar.bz2":
+		r = a.counter(name, bzip2.NewReader(r))
+
+	case "tar":
+		// Not compressed.
+	}
+
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if errors.Is(err, ErrArchiveLimit) {
+				return err
+			}
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		if err := a.entry(name); err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || archiveKind(hdr.Name) == "" && !licenseFileRE.MatchString(path.Base(hdr.Name)) {
+			continue
+		}
+		if err := a.file(entryPath(name, hdr.Name), tr, depth); err != nil {
+			return err
+		}
+	}
+}
+
+// file scans the license file or nested archive named file read from r.
+func (a *archiveScan) file(file string, r io.Reader, depth int) error {
+	if kind := archiveKind(file); kind != "" {
+		return a.stream(file, kind, r, depth+1)
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, maxScanFSFile+1))
+	if err != nil {
+		if errors.Is(err, ErrArchiveLimit) {
+			return err
+		}
+		return fmt.Errorf("%s: %v", file, err)
+	}
+	if len(data) > maxScanFSFile || isBinary(data) {
+		return nil
+	}
+	cov := a.s.Scan(data)
+	if len(cov.Match) > 0 {
+		a.list = append(a.list, FileCoverage{Path: file, Data: data, Coverage: cov})
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck/internal/archivetest"
+)
+
+// tarBz2 is a bzip2-compressed tar file holding pkg/NOTICE,
+// which refers to the Apache 2.0 license by URL.
+// (The standard library cannot write bzip2.)
+var tarBz2 = "" +
+	"\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\x53\x7b\xfc\xff\x00\x00" +
+	"\x86\xdf\x81\xca\x90\x40\x03\xdd\x90\x0a\x25\x8d\x00\x6e\xed\xde" +
+	"\x80\x01\x00\x08\x08\x20\x00\x74\x1a\x4d\x40\x4d\x1e\x83\x53\xd0" +
+	"\xd4\xc1\xa2\x0c\xd4\xf5\x36\xa0\xd2\x40\xd3\x4c\x80\x34\x00\x00" +
+	"\x00\x8d\xef\x8c\x60\x08\x03\xb4\x08\x02\x0a\xe2\x17\x44\x29\x41" +
+	"\xc8\x02\x06\x20\x67\x2f\x4a\x3c\xc5\x7d\x66\x17\x2a\x81\x5a\x93" +
+	"\x04\x1a\x64\x0e\xbb\x98\xb0\x86\x4a\xc9\x93\x80\xc3\x44\x37\x3b" +
+	"\x20\x86\x34\x6a\x90\xfb\x7f\x70\x3e\x64\xf7\x77\x70\xf2\x7f\x7d" +
+	"\xc7\xaa\xdf\x52\xf9\x48\xb2\xa8\x28\xe3\x82\x27\x50\x9e\xb9\x95" +
+	"\x4c\x1c\x29\xb8\x88\x80\xfe\x2e\xe4\x8a\x70\xa1\x20\xa6\xf7\xf9" +
+	"\xfe"
+
+// checkArchive checks that list holds matches for the given
+// files, listed as path, license ID pairs.
+func checkArchive(t *testing.T, name string, list []FileCoverage, want ...string) {
+	t.Helper()
+	var have []string
+	for _, f := range list {
+		have = append(have, f.Path)
+		if inArchive := strings.Contains(f.Path, "!/"); inArchive != (f.Data != nil) {
+			t.Errorf("%s: %s: have %d bytes of Data, want Data only for files in archives", name, f.Path, len(f.Data))
+			continue
+		}
+		for _, m := range f.Match {
+			have = append(have, m.ID)
+			if f.Data != nil && (m.Start < 0 || m.End > len(f.Data)) {
+				t.Errorf("%s: %s: match [%d:%d] outside data of length %d", name, f.Path, m.Start, m.End, len(f.Data))
+			}
+		}
+	}
+	if strings.Join(have, " ") != strings.Join(want, " ") {
+		t.Errorf("%s:\nhave %q\nwant %q", name, have, want)
+	}
+}
+
+func TestScanArchive(t *testing.T) {
+	jar := archivetest.Zip(t,
+		"META-INF/MANIFEST.MF", "Manifest-Version: 1.0\n",
+		"META-INF/LICENSE", license_MIT,
+		"Main.class", "\xca\xfe\xba\xbe\x00"+license_MIT,
+	)
+	modZip := archivetest.Zip(t,
+		"example.com/mod@v1.0.0/LICENSE", license_MIT,
+		"example.com/mod@v1.0.0/main.go", "// "+license_MIT,
+		"example.com/mod@v1.0.0/lib/inner.jar", string(jar),
+	)
+	list, err := ScanArchive("mod.zip", bytes.NewReader(modZip), int64(len(modZip)), ArchiveLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArchive(t, "ScanArchive(mod.zip)", list,
+		"mod.zip!/example.com/mod@v1.0.0/LICENSE", "MIT",
+		"mod.zip!/example.com/mod@v1.0.0/lib/inner.jar!/META-INF/LICENSE", "MIT",
+	)
+
+	list, err = ScanArchiveStream("mod.zip", bytes.NewReader(modZip), ArchiveLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArchive(t, "ScanArchiveStream(mod.zip)", list,
+		"mod.zip!/example.com/mod@v1.0.0/LICENSE", "MIT",
+		"mod.zip!/example.com/mod@v1.0.0/lib/inner.jar!/META-INF/LICENSE", "MIT",
+	)
+
+	tgz := archivetest.TarGz(t,
+		"./pkg/README", "Licensed under https://www.apache.org/licenses/LICENSE-2.0\n",
+		"./pkg/COPYING", license_MIT,
+		"./pkg/dist/pkg.whl", string(archivetest.Zip(t, "pkg-1.0.dist-info/LICENSE.txt", license_MIT)),
+	)
+	list, err = ScanArchiveStream("pkg.tar.gz", bytes.NewReader(tgz), ArchiveLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArchive(t, "ScanArchiveStream(pkg.tar.gz)", list,
+		"pkg.tar.gz!/pkg/COPYING", "MIT",
+		"pkg.tar.gz!/pkg/dist/pkg.whl!/pkg-1.0.dist-info/LICENSE.txt", "MIT",
+	)
+
+	// Entry names are cleaned the same way in zip and tar files.
+	odd := []string{"./pkg/LICENSE", license_MIT, "pkg/x/../COPYING", license_MIT}
+	for _, name := range []string{"odd.zip", "odd.tar.gz"} {
+		data := archivetest.Zip(t, odd...)
+		if name == "odd.tar.gz" {
+			data = archivetest.TarGz(t, odd...)
+		}
+		list, err = ScanArchive(name, bytes.NewReader(data), int64(len(data)), ArchiveLimits{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkArchive(t, "ScanArchive("+name+")", list, name+"!/pkg/LICENSE", "MIT", name+"!/pkg/COPYING", "MIT")
+	}
+
+	list, err = ScanArchive("pkg.tbz2", strings.NewReader(tarBz2), int64(len(tarBz2)), ArchiveLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArchive(t, "ScanArchive(pkg.tbz2)", list, "pkg.tbz2!/pkg/NOTICE", "Apache-2.0")
+
+	if _, err := ScanArchive("x.txt", bytes.NewReader(modZip), int64(len(modZip)), ArchiveLimits{}); err == nil {
+		t.Errorf("ScanArchive(x.txt) succeeded, want error")
+	}
+	if _, err := ScanArchiveStream("x.tar.gz", strings.NewReader(license_MIT), ArchiveLimits{}); err == nil || errors.Is(err, ErrArchiveLimit) {
+		t.Errorf("ScanArchiveStream(x.tar.gz) with text = %v, want gzip error", err)
+	}
+}
+
+func TestScanArchiveLimits(t *testing.T) {
+	jar := archivetest.Zip(t, "META-INF/LICENSE", license_MIT)
+	war := archivetest.Zip(t, "WEB-INF/LICENSE", license_MIT, "WEB-INF/lib/lib.jar", string(jar))
+	bomb := archivetest.TarGz(t, "pkg/LICENSE", license_MIT, "pkg/zeros", strings.Repeat("\x00", 4<<20))
+
+	tests := []struct {
+		name   string
+		data   []byte
+		limits ArchiveLimits
+		ok     bool
+	}{
+		{"app.war", war, ArchiveLimits{}, true},
+		{"app.war", war, ArchiveLimits{MaxDepth: 2}, true},
+		{"app.war", war, ArchiveLimits{MaxDepth: 1}, false},
+		{"app.war", war, ArchiveLimits{MaxEntries: 3}, true},
+		{"app.war", war, ArchiveLimits{MaxEntries: 2}, false},
+		{"bomb.tgz", bomb, ArchiveLimits{}, true},
+		{"bomb.tgz", bomb, ArchiveLimits{MaxBytes: 1 << 20}, false},
+	}
+	for _, tt := range tests {
+		list, err := ScanArchive(tt.name, bytes.NewReader(tt.data), int64(len(tt.data)), tt.limits)
+		if tt.ok && (err != nil || len(list) == 0) {
+			t.Errorf("ScanArchive(%s, %+v) = %d files, %v, want success", tt.name, tt.limits, len(list), err)
+		}
+		if !tt.ok && !errors.Is(err, ErrArchiveLimit) {
+			t.Errorf("ScanArchive(%s, %+v) = %d files, %v, want ErrArchiveLimit", tt.name, tt.limits, len(list), err)
+		}
+	}
+}
+
+func TestScanFSArchives(t *testing.T) {
+	fsys := fstest.MapFS{
+		"LICENSE":              {Data: []byte(license_MIT)},
+		"dist/pkg-1.0.tar.gz":  {Data: archivetest.TarGz(t, "pkg-1.0/LICENSE", license_MIT)},
+		"dist/pkg-1.0.zip":     {Data: archivetest.Zip(t, "pkg-1.0/LICENSE", license_MIT)},
+		"testdata/corrupt.zip": {Data: []byte("not a zip")},
+	}
+	list, err := ScanFS(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArchive(t, "ScanFS", list, "LICENSE", "MIT")
+
+	list, err = ScanFSWithOptions(fsys, ".", ScanFSOptions{Archives: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkArchive(t, "ScanFSWithOptions(Archives)", list,
+		"LICENSE", "MIT",
+		"dist/pkg-1.0.tar.gz!/pkg-1.0/LICENSE", "MIT",
+		"dist/pkg-1.0.zip!/pkg-1.0/LICENSE", "MIT",
+	)
+
+	_, err = ScanFSWithOptions(fsys, ".", ScanFSOptions{Archives: true, ArchiveLimits: ArchiveLimits{MaxEntries: 1}})
+	if err != nil {
+		t.Errorf("ScanFSWithOptions(MaxEntries: 1) = %v, want limit per archive", err)
+	}
+
+	fsys["dist/big.tgz"] = &fstest.MapFile{Data: archivetest.TarGz(t, "a", "", "b", "")}
+	_, err = ScanFSWithOptions(fsys, ".", ScanFSOptions{Archives: true, ArchiveLimits: ArchiveLimits{MaxEntries: 1}})
+	if !errors.Is(err, ErrArchiveLimit) {
+		t.Errorf("ScanFSWithOptions(MaxEntries: 1) with two-entry archive = %v, want ErrArchiveLimit", err)
+	}
+}
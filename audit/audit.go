@@ -0,0 +1,421 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package audit compares the licenses that packages declare
+// in their manifests with the licenses found in their files.
+//
+// A package is a directory containing one or more manifests
+// recognized by the manifest package, together with the files
+// beneath it that do not belong to a nested package.
+// Check reports, for each package, the ways in which
+// the declared and detected licenses disagree:
+//
+//   - a license file whose text is not a declared license (Mismatch);
+//   - a declared license with no license text in the package (MissingText);
+//   - a file under a copyleft license that is not declared (ExtraCopyleft);
+//   - a declaration that cannot be recognized at all (Unrecognized);
+//   - a manifest that cannot be parsed (Malformed).
+package audit
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/vcs"
+	"github.com/google/licensecheck/manifest"
+)
+
+// A Kind is a kind of Problem.
+type Kind int
+
+const (
+	// Mismatch indicates that a license file contains
+	// the text of a license that is not declared.
+	Mismatch Kind = 1 + iota
+
+	// MissingText indicates that a declared license
+	// does not appear in any of the package's license files.
+	MissingText
+
+	// ExtraCopyleft indicates that a source file carries
+	// a copyleft license that is not declared.
+	ExtraCopyleft
+
+	// Unrecognized indicates that the package's manifests
+	// declare licenses that could not be recognized,
+	// so that no other comparison was possible.
+	Unrecognized
+
+	// Malformed indicates that a manifest could not be parsed.
+	// Its declaration, if any, is missing from the comparisons.
+	Malformed
+)
+
+var kindNames = []string{
+	Mismatch:      "Mismatch",
+	MissingText:   "MissingText",
+	ExtraCopyleft: "ExtraCopyleft",
+	Unrecognized:  "Unrecognized",
+	Malformed:     "Malformed",
+}
+
+func (k Kind) String() string {
+	if 0 < k && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// A Problem describes a single discrepancy between
+// a package's declared and detected licenses.
+type Problem struct {
+	Kind Kind
+	ID   string // license ID concerned, if any
+	Path string // file concerned, if any
+	Err  error  // parse error, for Malformed
+}
+
+func (p Problem) String() string {
+	switch p.Kind {
+	case Mismatch:
+		return fmt.Sprintf("%s: license text is %s, which is not declared", p.Path, p.ID)
+	case MissingText:
+		return fmt.Sprintf("declared license %s has no license text", p.ID)
+	case ExtraCopyleft:
+		return fmt.Sprintf("%s: copyleft license %s is not declared", p.Path, p.ID)
+	case Unrecognized:
+		return fmt.Sprintf("%s: declared license %q not recognized", p.Path, p.ID)
+	case Malformed:
+		// The parse error begins with the file name already.
+		return fmt.Sprintf("%s: malformed manifest: %s", p.Path, strings.TrimPrefix(fmt.Sprint(p.Err), p.Path+": "))
+	}
+	return fmt.Sprintf("%v %s %s", p.Kind, p.ID, p.Path)
+}
+
+// A Package is the audit result for a single package.
+type Package struct {
+	// Dir is the package directory.
+	Dir string
+
+	// Declared lists the declarations parsed from the package's manifests.
+	Declared []*manifest.Declaration
+
+	// Expression is the combined declared license expression,
+	// or the empty string if no license is declared
+	// or a declaration could not be recognized.
+	// When several manifests declare licenses, the result
+	// is the conjunction (AND) of their distinct declarations.
+	Expression string
+
+	// LicenseFiles lists the scan results for the package's license files:
+	// files named like LICENSE, COPYING, or NOTICE,
+	// and files named by the manifests.
+	LicenseFiles []licensecheck.FileCoverage
+
+	// Files lists the scan results for the package's other files
+	// in which a license was found.
+	Files []licensecheck.FileCoverage
+
+	// Problems lists the discrepancies found.
+	Problems []Problem
+}
+
+// licenseFileRE matches the base names of conventional license files.
+var licenseFileRE = regexp.MustCompile(`(?i)^(un)?licen[cs]e|^copying|^copyright|^notice|^legal|^patents`)
+
+// copyleft is the set of license types that Check treats as copyleft.
+const copyleft = licensecheck.ShareChanges | licensecheck.ShareProgram | licensecheck.ShareServer
+
+// copyleftIDRE matches the IDs of copyleft license families.
+// Most built-in licenses do not yet record a Type,
+// so isCopyleft also recognizes these by ID.
+var copyleftIDRE = regexp.MustCompile(`^(A|L)?GPL-|^(MPL|EPL|EUPL|CDDL|CPL|CPAL|OSL|RPL|QPL|SSPL|APSL|CECILL|MS-RL|Sleepycat|ODbL|CC-BY(-NC)?-SA|GFDL)\b`)
+
+// isCopyleft reports whether m is a match for a copyleft license.
+func isCopyleft(m licensecheck.Match) bool {
+	return m.Type&copyleft != 0 || copyleftIDRE.MatchString(m.ID)
+}
+
+// Check audits the packages in the file tree rooted at root in fsys,
+// returning one Package for each directory that contains a manifest,
+// in lexical order by directory.
+func Check(fsys fs.FS, root string) ([]*Package, error) {
+	var pkgs []*Package
+	byDir := make(map[string]*Package)
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Skip the same directories as ScanFS below,
+			// so that a manifest is audited only if its files are scanned.
+			if file != root && vcs.IsMetadataDir(path.Base(file)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !manifest.IsManifest(file) {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		dir := path.Dir(file)
+		p := byDir[dir]
+		if p == nil {
+			p = &Package{Dir: dir}
+			byDir[dir] = p
+			pkgs = append(pkgs, p)
+		}
+		decl, err := manifest.Parse(file, data)
+		if err != nil {
+			// One bad manifest should not stop the audit of the others.
+			p.Problems = append(p.Problems, Problem{Kind: Malformed, Path: file, Err: err})
+			return nil
+		}
+		p.Declared = append(p.Declared, decl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) == 0 {
+		return nil, nil
+	}
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Dir < pkgs[j].Dir })
+
+	files, err := licensecheck.ScanFS(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		p := owner(byDir, f.Path)
+		if p == nil {
+			continue
+		}
+		if isLicenseFile(p, f.Path) {
+			p.LicenseFiles = append(p.LicenseFiles, f)
+		} else {
+			p.Files = append(p.Files, f)
+		}
+	}
+	for _, p := range pkgs {
+		p.check()
+	}
+	return pkgs, nil
+}
+
+// owner returns the package owning the file,
+// the one with the longest directory containing it.
+func owner(byDir map[string]*Package, file string) *Package {
+	for dir := path.Dir(file); ; dir = path.Dir(dir) {
+		if p := byDir[dir]; p != nil {
+			return p
+		}
+		if dir == "." || dir == "/" {
+			return nil
+		}
+	}
+}
+
+// isLicenseFile reports whether file is a license file of package p.
+func isLicenseFile(p *Package, file string) bool {
+	if path.Dir(file) == p.Dir && licenseFileRE.MatchString(path.Base(file)) {
+		return true
+	}
+	rel := strings.TrimPrefix(file, p.Dir+"/")
+	if p.Dir == "." {
+		rel = file
+	}
+	for _, d := range p.Declared {
+		for _, pattern := range d.Files {
+			pattern = strings.TrimPrefix(strings.ReplaceAll(pattern, "\\", "/"), "./")
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// check computes p.Expression and p.Problems.
+func (p *Package) check() {
+	var exprs []string
+	seen := make(map[string]bool)
+	unrecognized := false
+	for _, d := range p.Declared {
+		if len(d.Names) == 0 {
+			continue
+		}
+		if d.Expression == "" {
+			p.Problems = append(p.Problems, Problem{Kind: Unrecognized, ID: strings.Join(d.Names, ", "), Path: d.File})
+			unrecognized = true
+			continue
+		}
+		if !seen[d.Expression] {
+			seen[d.Expression] = true
+			exprs = append(exprs, d.Expression)
+		}
+	}
+	if unrecognized || len(exprs) == 0 {
+		return
+	}
+	if len(exprs) == 1 {
+		p.Expression = exprs[0]
+	} else {
+		for i, x := range exprs {
+			if strings.Contains(x, " ") {
+				exprs[i] = "(" + x + ")"
+			}
+		}
+		p.Expression = strings.Join(exprs, " AND ")
+	}
+
+	declared := make(map[string]bool)
+	for _, id := range exprIDs(p.Expression) {
+		declared[idKey(id)] = true
+	}
+
+	found := make(map[string]bool)
+	for _, f := range p.LicenseFiles {
+		reported := make(map[string]bool)
+		for _, m := range f.Match {
+			k := idKey(m.ID)
+			if !m.IsURL {
+				found[k] = true
+			}
+			if !declared[k] && !reported[k] {
+				reported[k] = true
+				p.Problems = append(p.Problems, Problem{Kind: Mismatch, ID: m.ID, Path: f.Path})
+			}
+		}
+	}
+	for _, id := range missingIDs(p.Expression, found) {
+		p.Problems = append(p.Problems, Problem{Kind: MissingText, ID: id})
+	}
+	for _, f := range p.Files {
+		reported := make(map[string]bool)
+		for _, m := range f.Match {
+			k := idKey(m.ID)
+			if isCopyleft(m) && !declared[k] && !reported[k] {
+				reported[k] = true
+				p.Problems = append(p.Problems, Problem{Kind: ExtraCopyleft, ID: m.ID, Path: f.Path})
+			}
+		}
+	}
+}
+
+// exprIDs returns the license IDs in the SPDX expression x,
+// omitting operators and exception IDs.
+func exprIDs(x string) []string {
+	var ids []string
+	f := strings.Fields(strings.NewReplacer("(", " ", ")", " ").Replace(x))
+	for i := 0; i < len(f); i++ {
+		switch f[i] {
+		case "AND", "OR":
+			continue
+		case "WITH":
+			i++ // skip exception
+			continue
+		}
+		ids = append(ids, f[i])
+	}
+	return ids
+}
+
+// missingIDs returns the license IDs in the SPDX expression x
+// whose texts must be found for x to be satisfied but are not in found,
+// which holds the idKeys of the license texts found.
+// A conjunction (AND) needs the texts of all its terms,
+// but a disjunction (OR) needs only the text of one:
+// if no term is satisfied, the IDs missing from all terms are returned.
+// LicenseRef- IDs have no standard text and are always satisfied.
+func missingIDs(x string, found map[string]bool) []string {
+	f := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(x))
+	missing := exprMissing(&f, found)
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range missing {
+		if k := idKey(id); !seen[k] {
+			seen[k] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// exprMissing parses the disjunction at the start of the tokens *f,
+// advancing *f past it, and returns its missing IDs (see missingIDs).
+// AND binds more tightly than OR.
+func exprMissing(f *[]string, found map[string]bool) []string {
+	var missing []string
+	ok := false
+	for {
+		m := termMissing(f, found)
+		if len(m) == 0 {
+			ok = true
+		}
+		missing = append(missing, m...)
+		if len(*f) == 0 || (*f)[0] != "OR" {
+			break
+		}
+		*f = (*f)[1:]
+	}
+	if ok {
+		return nil
+	}
+	return missing
+}
+
+// termMissing parses the conjunction at the start of the tokens *f,
+// advancing *f past it, and returns its missing IDs (see missingIDs).
+func termMissing(f *[]string, found map[string]bool) []string {
+	var missing []string
+	for len(*f) > 0 {
+		switch tok := (*f)[0]; tok {
+		case "(":
+			*f = (*f)[1:]
+			missing = append(missing, exprMissing(f, found)...)
+			if len(*f) > 0 && (*f)[0] == ")" {
+				*f = (*f)[1:]
+			}
+		case ")", "AND", "OR", "WITH":
+			// Malformed expression: stop.
+			return missing
+		default:
+			*f = (*f)[1:]
+			if !found[idKey(tok)] && !strings.HasPrefix(tok, "LicenseRef-") {
+				missing = append(missing, tok)
+			}
+		}
+		if len(*f) >= 2 && (*f)[0] == "WITH" {
+			*f = (*f)[2:] // skip exception
+		}
+		if len(*f) == 0 || (*f)[0] != "AND" {
+			break
+		}
+		*f = (*f)[1:]
+	}
+	return missing
+}
+
+// idKey returns the key used to compare license IDs.
+// It ignores case and GNU-style version suffixes,
+// since license texts say nothing about "only" versus "or later":
+// that choice is made in the notices that apply the license.
+func idKey(id string) string {
+	if c, ok := licensecheck.CanonicalID(id); ok {
+		id = c
+	}
+	id = strings.TrimSuffix(id, "+")
+	id = strings.TrimSuffix(id, "-only")
+	id = strings.TrimSuffix(id, "-or-later")
+	return strings.ToLower(id)
+}
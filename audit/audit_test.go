@@ -0,0 +1,179 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"errors"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// testText returns the license text from ../testdata/name,
+// without the test header.
+func testText(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.Index(string(data), "\n\n")
+	if i < 0 {
+		t.Fatalf("%s: malformed test file", name)
+	}
+	return data[i+2:]
+}
+
+func TestCheck(t *testing.T) {
+	mit := testText(t, "MIT.t1")
+	bsd := testText(t, "BSD-3-Clause.t1")
+	agpl := testText(t, "AGPL-3.0-Header.t1")
+
+	fsys := fstest.MapFS{
+		// Correctly labeled.
+		"good/package.json": {Data: []byte(`{"license": "MIT"}`)},
+		"good/LICENSE":      {Data: mit},
+		"good/index.js":     {Data: []byte("module.exports = 1\n")},
+
+		// Declared MIT, but the license is BSD, and a source file is AGPL.
+		"bad/Cargo.toml":  {Data: []byte("[package]\nlicense = \"MIT\"\n")},
+		"bad/LICENSE":     {Data: bsd},
+		"bad/src/main.rs": {Data: append([]byte("// "), agpl...)},
+
+		// Nested package owns its own files.
+		"bad/vendor/dep/composer.json": {Data: []byte(`{"license": "BSD-3-Clause"}`)},
+		"bad/vendor/dep/COPYING":       {Data: bsd},
+
+		// License file named by the manifest; unrecognized second manifest.
+		"multi/pyproject.toml": {Data: []byte("[project]\nlicense = {file = \"docs/terms.txt\"}\nclassifiers = [\"License :: OSI Approved :: MIT License\"]\n")},
+		"multi/docs/terms.txt": {Data: mit},
+		"multi/PKG-INFO":       {Data: []byte("Metadata-Version: 2.1\nLicense: Frobozz Public License\n")},
+
+		// AGPL declared as or-later, matching the header.
+		"gnu/DESCRIPTION": {Data: []byte("Package: gnu\nLicense: AGPL (>= 3) + file LICENSE\n")},
+		"gnu/LICENSE":     {Data: agpl},
+		"gnu/R/gnu.R":     {Data: append([]byte("# "), agpl...)},
+
+		// Dual-licensed: either license text satisfies the declaration.
+		"dual/Cargo.toml": {Data: []byte("[package]\nlicense = \"MIT OR Apache-2.0\"\n")},
+		"dual/LICENSE":    {Data: mit},
+
+		// A malformed manifest is a problem in its package only.
+		"broken/package.json": {Data: []byte(`{"license": `)},
+		"broken/LICENSE":      {Data: mit},
+
+		// Manifests in version control metadata are not packages.
+		"good/.git/package.json": {Data: []byte(`{"license": "GPL-2.0"}`)},
+		".hg/store/Cargo.toml":   {Data: []byte("[package]\nlicense = \"MIT\"\n")},
+
+		// Files outside any package are ignored.
+		"README": {Data: agpl},
+	}
+
+	pkgs, err := Check(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type result struct {
+		dir      string
+		expr     string
+		problems []Problem
+	}
+	want := []result{
+		{"bad", "MIT", []Problem{
+			{Kind: Mismatch, ID: "BSD-3-Clause", Path: "bad/LICENSE"},
+			{Kind: MissingText, ID: "MIT"},
+			{Kind: ExtraCopyleft, ID: "AGPL-3.0-or-later", Path: "bad/src/main.rs"},
+		}},
+		{"bad/vendor/dep", "BSD-3-Clause", nil},
+		{"broken", "", []Problem{
+			{Kind: Malformed, Path: "broken/package.json"},
+		}},
+		{"dual", "MIT OR Apache-2.0", nil},
+		{"gnu", "AGPL-3.0-or-later", nil},
+		{"good", "MIT", nil},
+		{"multi", "", []Problem{
+			{Kind: Unrecognized, ID: "Frobozz Public License", Path: "multi/PKG-INFO"},
+		}},
+	}
+	var have []result
+	for _, p := range pkgs {
+		for i := range p.Problems {
+			if p.Problems[i].Kind == Malformed {
+				if p.Problems[i].Err == nil {
+					t.Errorf("%s: Malformed problem without error", p.Dir)
+				}
+				p.Problems[i].Err = nil
+			}
+		}
+		have = append(have, result{p.Dir, p.Expression, p.Problems})
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("Check:\nhave %+v\nwant %+v", have, want)
+	}
+
+	for _, p := range pkgs {
+		if p.Dir == "multi" {
+			if len(p.LicenseFiles) != 1 || p.LicenseFiles[0].Path != "multi/docs/terms.txt" {
+				t.Errorf("multi: LicenseFiles = %+v, want multi/docs/terms.txt", p.LicenseFiles)
+			}
+		}
+	}
+}
+
+func TestExprIDs(t *testing.T) {
+	for _, tt := range []struct {
+		in  string
+		out []string
+	}{
+		{"MIT", []string{"MIT"}},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", []string{"MIT", "Apache-2.0", "BSD-3-Clause"}},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0 OR MIT", []string{"GPL-2.0-or-later", "MIT"}},
+	} {
+		if out := exprIDs(tt.in); !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("exprIDs(%q) = %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestMissingIDs(t *testing.T) {
+	found := map[string]bool{"mit": true, "bsd-3-clause": true}
+	for _, tt := range []struct {
+		in  string
+		out []string
+	}{
+		{"MIT", nil},
+		{"Apache-2.0", []string{"Apache-2.0"}},
+		{"MIT OR Apache-2.0", nil},
+		{"Apache-2.0 OR GPL-2.0-only", []string{"Apache-2.0", "GPL-2.0-only"}},
+		{"MIT AND Apache-2.0", []string{"Apache-2.0"}},
+		{"(MIT OR Apache-2.0) AND BSD-3-Clause", nil},
+		{"(Apache-2.0 OR ISC) AND MIT", []string{"Apache-2.0", "ISC"}},
+		{"Apache-2.0 AND ISC OR MIT", nil},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0 OR MIT", nil},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0 AND MIT", []string{"GPL-2.0-or-later"}},
+		{"LicenseRef-Proprietary", nil},
+	} {
+		if out := missingIDs(tt.in, found); !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("missingIDs(%q) = %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestProblemString(t *testing.T) {
+	p := Problem{Kind: Mismatch, ID: "BSD-3-Clause", Path: "x/LICENSE"}
+	if s, want := p.String(), "x/LICENSE: license text is BSD-3-Clause, which is not declared"; s != want {
+		t.Errorf("String() = %q, want %q", s, want)
+	}
+	p = Problem{Kind: Malformed, Path: "x/package.json", Err: errors.New("x/package.json: unexpected end of JSON input")}
+	if s, want := p.String(), "x/package.json: malformed manifest: unexpected end of JSON input"; s != want {
+		t.Errorf("String() = %q, want %q", s, want)
+	}
+	if s, want := Kind(99).String(), "Kind(99)"; s != want {
+		t.Errorf("Kind(99).String() = %q, want %q", s, want)
+	}
+}
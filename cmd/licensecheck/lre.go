@@ -0,0 +1,25 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Loading custom licenses from .lre files.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/licensecheck"
+)
+
+// loadLRE returns the licenses defined by the .lre files in dir,
+// sorted by ID. The files can use the templates defined in each other
+// and in the built-in license files (see licensecheck.LoadLicenses).
+func loadLRE(dir string) ([]licensecheck.License, error) {
+	list, err := licensecheck.LoadLicenses(os.DirFS(dir), "*.lre")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", dir, err)
+	}
+	return list, nil
+}
@@ -0,0 +1,108 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The "lre lint" subcommand.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/lre"
+)
+
+const lreLintUsage = "usage: licensecheck lre lint [-lre dir] [-states n] [-v]\n"
+
+// lreLint runs the "lre lint" subcommand with the given arguments
+// and returns the exit status.
+func lreLint(args []string) int {
+	fs := flag.NewFlagSet("lre lint", flag.ContinueOnError)
+	var dirs stringList
+	fs.Var(&dirs, "lre", "lint the licenses from .lre files in `dir`")
+	states := fs.Int("states", 100000, "report licenses whose DFA has more than `n` states")
+	verbose := fs.Bool("v", false, "print the DFA state count for each license")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, lreLintUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	// Lint the custom licenses if any, else the built-in ones.
+	// Either way, check for start phrase collisions with all licenses.
+	var custom []licensecheck.License
+	for _, dir := range dirs {
+		list, err := loadLRE(dir)
+		if err != nil {
+			log.Print(err)
+			return exitError
+		}
+		custom = append(custom, list...)
+	}
+	type key struct{ id, lang string }
+	ids := make(map[key]bool)
+	for _, l := range custom {
+		ids[key{l.ID, l.Language}] = true
+	}
+	var builtin []licensecheck.License
+	for _, l := range licensecheck.BuiltinLicenses() {
+		if l.LRE != "" && !ids[key{l.ID, l.Language}] {
+			builtin = append(builtin, l)
+		}
+	}
+
+	var d lre.Dict
+	parse := func(licenses []licensecheck.License) ([]*lre.LRE, error) {
+		var list []*lre.LRE
+		for _, l := range licenses {
+			file := l.ID + ".lre"
+			if l.Language != "" {
+				file = l.ID + "." + l.Language + ".lre"
+			}
+			re, err := lre.ParseLRE(&d, file, l.LRE)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, re)
+		}
+		return list, nil
+	}
+	builtinREs, err := parse(builtin)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	customREs, err := parse(custom)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+	list, context := customREs, builtinREs
+	if len(dirs) == 0 {
+		list, context = builtinREs, nil
+	}
+
+	if *verbose {
+		for _, re := range list {
+			fmt.Printf("%s: %d DFA states\n", re.File(), re.NumStates())
+		}
+	}
+	diags := lre.Lint(list, context, *states)
+	for _, d := range diags {
+		fmt.Println(d)
+	}
+	if len(diags) > 0 {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,90 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The "lre" subcommand and "lre test".
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/licensecheck/lretest"
+)
+
+const lreTestUsage = "usage: licensecheck lre test [-lre dir] [-update] [-v] testdir ...\n"
+
+// lreMain runs the "lre" subcommand with the given arguments
+// and returns the exit status.
+func lreMain(args []string) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "test":
+			return lreTest(args[1:])
+		case "lint":
+			return lreLint(args[1:])
+		}
+	}
+	fmt.Fprint(os.Stderr, lreTestUsage, lreLintUsage)
+	return exitUsage
+}
+
+// lreTest runs the "lre test" subcommand with the given arguments
+// and returns the exit status.
+func lreTest(args []string) int {
+	fs := flag.NewFlagSet("lre test", flag.ContinueOnError)
+	var dirs stringList
+	fs.Var(&dirs, "lre", "add licenses from .lre files in `dir`")
+	update := fs.Bool("update", false, "rewrite the headers of failing test files to match the results")
+	verbose := fs.Bool("v", false, "print the name of each test file as it passes")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, lreTestUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return exitUsage
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	scanner, err := newScanner(dirs)
+	if err != nil {
+		log.Print(err)
+		return exitError
+	}
+
+	status := exitOK
+	for _, dir := range fs.Args() {
+		results, err := lretest.RunDir(scanner, dir)
+		if err != nil {
+			log.Print(err)
+			status = exitError
+			continue
+		}
+		for _, r := range results {
+			if !r.Failed() {
+				if *verbose {
+					fmt.Printf("ok   %s\n", r.File)
+				}
+				continue
+			}
+			if *update {
+				if err := r.Update(); err != nil {
+					log.Print(err)
+					status = exitError
+					continue
+				}
+				fmt.Printf("updated %s\n", r.File)
+				continue
+			}
+			fmt.Printf("FAIL %s: diff -want +have:\n%s", r.File, r.Diff)
+			status = exitError
+		}
+	}
+	return status
+}
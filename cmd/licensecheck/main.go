@@ -0,0 +1,299 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Licensecheck scans files for license texts and license URLs.
+//
+// Usage:
+//
+//	licensecheck [-format text|json|csv] [-lre dir] [-allow ids] [-deny ids] [-deny-type types] [path ...]
+//	licensecheck lre test [-lre dir] [-update] [-v] testdir ...
+//	licensecheck lre lint [-lre dir] [-states n] [-v]
+//
+// Licensecheck scans each named file, and each file in the tree rooted
+// at each named directory, printing the licenses it finds.
+// With no arguments, or with the argument "-", it scans standard input.
+// When scanning directories, licensecheck skips version control metadata,
+// binary files, and files larger than 4 MB, and it lists only files
+// in which it finds a license.
+//
+// Licensecheck also scans the license files, such as LICENSE or COPYING,
+// in archives: zip, jar, war, and wheel files, Go module zips, and tar files,
+// optionally compressed with gzip or bzip2. It finds archives by their
+// names, both when named on the command line and in directories,
+// and it descends into archives nested in them. A file in an archive
+// is listed as archive!/path, as in dist/pkg.tar.gz!/pkg/LICENSE.
+// To guard against archives crafted to exhaust memory, such as zip bombs,
+// licensecheck gives up on an archive that nests archives more than 4 deep,
+// decompresses more than 1 GB, or has more than 100,000 entries.
+//
+// A named file with the extension .docx or .odt (or a related one,
+// such as .dotx or .ott) is read as a word processing document:
+// licensecheck scans the text of its paragraphs, and the line and column
+// of each match are the paragraph and character numbers in the document.
+//
+// The -format flag sets the output format:
+//
+//	text  one line per match: path:line:column: ID (percent of file covered)
+//	json  a JSON array with one object per file
+//	csv   a CSV table with one row per match
+//
+// The -lre flag adds the licenses defined by the .lre files in the
+// directory to the built-in licenses (see licenses/README.md for the
+// format). Each file defines the license named by its base name,
+// replacing any built-in license with that ID; a file named ID.LANG.lre,
+// such as EUPL-1.2.de.lre, defines a translation into the language LANG. The files may use
+// templates defined in any .lre file in the same directory
+// and in the built-in license files. The flag may be repeated.
+//
+// The -allow, -deny, and -deny-type flags set a policy,
+// each taking a comma-separated list. A match violates the policy
+// if -allow is set and the match's license ID is not listed,
+// if its ID is listed in -deny, or if its license type has
+// any of the type bits (such as ShareProgram or NonCommercial)
+// listed in -deny-type. Licensecheck prints each violation
+// to standard error.
+//
+// The exit status is 0 on success, 1 if a file could not be read,
+// 2 for a usage error, and 3 if any match violates the policy.
+// To scan a file or directory named "lre", write it as "./lre".
+//
+// The "lre test" subcommand runs the license test files (named Kind.tN)
+// in each test directory, using the built-in licenses plus those added
+// by the -lre flag, and prints a diff for each file whose expected results,
+// recorded in its header, differ from the scan results.
+// See the lretest package for the test file format.
+// The -update flag rewrites the headers of the failing files instead.
+// The -v flag prints the names of the passing files too.
+// The exit status is 0 if all tests pass and 1 otherwise.
+//
+// The "lre lint" subcommand checks the licenses added by the -lre flag,
+// or the built-in licenses if there is no -lre flag, for patterns that are
+// likely mistakes or needlessly expensive to match: alternatives that can
+// never be chosen, optional groups that repeat neighboring words, wildcards
+// whose implicit cut is delayed by optional text, licenses whose DFA has
+// more than the number of states set by -states, and leading phrases
+// shared with other licenses. The -v flag prints each license's DFA
+// state count. The exit status is 0 if no problems are found and 1 otherwise.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/extract"
+)
+
+// Exit codes.
+const (
+	exitOK        = 0
+	exitError     = 1
+	exitUsage     = 2
+	exitViolation = 3
+)
+
+var (
+	format   = flag.String("format", "text", "output `format`: text, json, or csv")
+	allow    = flag.String("allow", "", "comma-separated license `ids` to allow")
+	deny     = flag.String("deny", "", "comma-separated license `ids` to deny")
+	denyType = flag.String("deny-type", "", "comma-separated license `types` to deny")
+	lreDirs  stringList
+)
+
+func init() {
+	flag.Var(&lreDirs, "lre", "add licenses from .lre files in `dir`")
+}
+
+// A stringList is a flag.Value accumulating repeated flag values.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(s string) error { *l = append(*l, s); return nil }
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: licensecheck [flags] [path ...]\n")
+	fmt.Fprint(os.Stderr, "       licensecheck lre test [-lre dir] [-update] [-v] testdir ...\n")
+	fmt.Fprint(os.Stderr, "       licensecheck lre lint [-lre dir] [-states n] [-v]\n")
+	flag.PrintDefaults()
+	os.Exit(exitUsage)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("licensecheck: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.Arg(0) == "lre" {
+		os.Exit(lreMain(flag.Args()[1:]))
+	}
+
+	out, ok := formatters[*format]
+	if !ok {
+		log.Printf("unknown format %q", *format)
+		usage()
+	}
+	pol, err := newPolicy(*allow, *deny, *denyType)
+	if err != nil {
+		log.Print(err)
+		usage()
+	}
+	scanner, err := newScanner(lreDirs)
+	if err != nil {
+		log.Print(err)
+		os.Exit(exitError)
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	status := exitOK
+	var files []*file
+	for _, arg := range args {
+		list, err := scan(scanner, arg)
+		if err != nil {
+			log.Print(err)
+			status = exitError
+			continue
+		}
+		files = append(files, list...)
+	}
+
+	if err := out(os.Stdout, files); err != nil {
+		log.Fatal(err)
+	}
+
+	violated := false
+	for _, f := range files {
+		for _, m := range f.Match {
+			if msg := pol.check(m); msg != "" {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", f.Path, msg)
+				violated = true
+			}
+		}
+	}
+	if violated && status == exitOK {
+		status = exitViolation
+	}
+	os.Exit(status)
+}
+
+// A file is the scan result for a single file.
+type file struct {
+	Path string
+	Data []byte
+	licensecheck.Coverage
+}
+
+// newScanner returns a scanner that adds the licenses loaded from dirs
+// to the built-in licenses. If dirs is empty, it returns nil,
+// meaning to use the built-in scanner.
+func newScanner(dirs []string) (*licensecheck.Scanner, error) {
+	if len(dirs) == 0 {
+		return nil, nil
+	}
+	type key struct{ id, lang string }
+	var custom []licensecheck.License
+	ids := make(map[key]bool)
+	for _, dir := range dirs {
+		list, err := loadLRE(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, l := range list {
+			ids[key{l.ID, l.Language}] = true
+		}
+		custom = append(custom, list...)
+	}
+	var all []licensecheck.License
+	for _, l := range licensecheck.BuiltinLicenses() {
+		if !ids[key{l.ID, l.Language}] {
+			all = append(all, l)
+		}
+	}
+	return licensecheck.NewScanner(append(all, custom...))
+}
+
+// scan scans the file or directory named by arg,
+// or standard input if arg is "-", using scanner,
+// or the built-in scanner if scanner is nil.
+func scan(scanner *licensecheck.Scanner, arg string) ([]*file, error) {
+	scanText, scanFS, scanDoc, scanArchive := licensecheck.Scan, licensecheck.ScanFSWithOptions, licensecheck.ScanDocument, licensecheck.ScanArchive
+	if scanner != nil {
+		scanText, scanFS, scanDoc, scanArchive = scanner.Scan, scanner.ScanFSWithOptions, scanner.ScanDocument, scanner.ScanArchive
+	}
+
+	if arg == "-" {
+		data, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return []*file{{Path: "-", Data: data, Coverage: scanText(data)}}, nil
+	}
+
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() && licensecheck.IsArchive(arg) {
+		f, err := os.Open(arg)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		list, err := scanArchive(filepath.ToSlash(arg), f, info.Size(), licensecheck.ArchiveLimits{})
+		if err != nil {
+			return nil, err
+		}
+		var files []*file
+		for _, fc := range list {
+			files = append(files, &file{Path: fc.Path, Data: fc.Data, Coverage: fc.Coverage})
+		}
+		return files, nil
+	}
+	if !info.IsDir() {
+		data, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return nil, err
+		}
+		if extract.IsOffice(arg) {
+			dc, err := scanDoc(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %v", arg, err)
+			}
+			f := &file{Path: filepath.ToSlash(arg), Data: dc.Text}
+			f.Percent, f.Corrections = dc.Percent, dc.Corrections
+			for _, m := range dc.Match {
+				f.Match = append(f.Match, m.Match)
+			}
+			return []*file{f}, nil
+		}
+		return []*file{{Path: filepath.ToSlash(arg), Data: data, Coverage: scanText(data)}}, nil
+	}
+
+	fsys := os.DirFS(arg)
+	list, err := scanFS(fsys, ".", licensecheck.ScanFSOptions{Archives: true})
+	if err != nil {
+		return nil, err
+	}
+	var files []*file
+	for _, fc := range list {
+		data := fc.Data
+		if data == nil {
+			data, err = ioutil.ReadFile(filepath.Join(arg, filepath.FromSlash(fc.Path)))
+			if err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, &file{Path: path.Join(filepath.ToSlash(arg), fc.Path), Data: data, Coverage: fc.Coverage})
+	}
+	return files, nil
+}
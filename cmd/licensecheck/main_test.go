@@ -0,0 +1,131 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+var policyTests = []struct {
+	allow, deny, denyType string
+	m                     licensecheck.Match
+	bad                   bool
+}{
+	{"", "", "", licensecheck.Match{ID: "MIT"}, false},
+	{"MIT, Apache-2.0", "", "", licensecheck.Match{ID: "MIT"}, false},
+	{"mit", "", "", licensecheck.Match{ID: "MIT"}, false},
+	{"MIT", "", "", licensecheck.Match{ID: "BSD-3-Clause"}, true},
+	{"", "gpl-2.0", "", licensecheck.Match{ID: "GPL-2.0"}, true},
+	{"", "BSD-3-Clause", "", licensecheck.Match{ID: "MIT"}, false},
+	{"", "", "ShareProgram,NonCommercial", licensecheck.Match{ID: "X", Type: licensecheck.NonCommercial | licensecheck.Notice}, true},
+	{"", "", "ShareProgram", licensecheck.Match{ID: "X", Type: licensecheck.Notice}, false},
+}
+
+func TestPolicy(t *testing.T) {
+	for _, tt := range policyTests {
+		p, err := newPolicy(tt.allow, tt.deny, tt.denyType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if msg := p.check(tt.m); (msg != "") != tt.bad {
+			t.Errorf("policy(%q, %q, %q).check(%+v) = %q, want violation=%v", tt.allow, tt.deny, tt.denyType, tt.m, msg, tt.bad)
+		}
+	}
+	if _, err := newPolicy("", "", "Bogus"); err == nil {
+		t.Errorf("newPolicy with -deny-type=Bogus succeeded")
+	}
+}
+
+func TestLoadLRE(t *testing.T) {
+	dir, err := ioutil.TempDir("", "licensecheck-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"common.lre": `{{define "grant"}}you may frobnicate this software freely{{end}}`,
+		"Frob.lre":   "{{Type \"Notice\"}}\nThe Frobozz License\n\n{{template \"grant\"}} provided that this notice is retained in all copies of it\n",
+	}
+	for name, text := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	list, err := loadLRE(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != "Frob" || list[0].Type != licensecheck.Notice || !strings.Contains(list[0].LRE, "frobnicate") {
+		t.Fatalf("loadLRE = %+v, want Frob", list)
+	}
+
+	// Built-in templates are available too.
+	mit := "{{template \"mit-grant\"}}\n{{template \"mit-disclaimer\"}}\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "MyMIT.lre"), []byte(mit), 0666); err != nil {
+		t.Fatal(err)
+	}
+	list, err = loadLRE(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[1].ID != "MyMIT" || !strings.Contains(list[1].LRE, "Permission is hereby granted") {
+		t.Fatalf("loadLRE = %+v, want Frob, MyMIT", list)
+	}
+
+	s, err := newScanner([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := "The Frobozz License\n\nYou may frobnicate this software freely, provided that this notice is retained in all copies of it.\n"
+	cov := s.Scan([]byte(text))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "Frob" {
+		t.Errorf("Scan with custom license = %+v", cov)
+	}
+
+	if _, err := loadLRE(filepath.Join(dir, "missing")); err == nil {
+		t.Errorf("loadLRE of missing directory succeeded")
+	}
+}
+
+func TestOutput(t *testing.T) {
+	files := []*file{{
+		Path: "a/LICENSE",
+		Data: []byte("Header\n  text, with \"quotes\"\n"),
+		Coverage: licensecheck.Coverage{Percent: 75, Match: []licensecheck.Match{
+			{ID: "MIT", Start: 9, End: 20},
+			{ID: "Apache-2.0", Start: 0, End: 6, IsURL: true},
+		}},
+	}}
+	want := map[string]string{
+		"text": "a/LICENSE:2:3: MIT (75.0%)\na/LICENSE:1:1: Apache-2.0 URL\n",
+		"csv": "path,percent,id,type,start,end,line,column,url\n" +
+			"a/LICENSE,75.0,MIT,Unknown,9,20,2,3,false\n" +
+			"a/LICENSE,75.0,Apache-2.0,Unknown,0,6,1,1,true\n",
+	}
+	for name, out := range want {
+		var buf bytes.Buffer
+		if err := formatters[name](&buf, files); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != out {
+			t.Errorf("-format=%s:\nhave:\n%s\nwant:\n%s", name, buf.String(), out)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+	if s := buf.String(); !strings.Contains(s, `"path": "a/LICENSE"`) || !strings.Contains(s, `"isURL": true`) || !strings.Contains(s, `"line": 2`) {
+		t.Errorf("-format=json:\n%s", s)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Output formats.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/google/licensecheck/internal/textpos"
+)
+
+// formatters maps each -format value to its output function.
+var formatters = map[string]func(io.Writer, []*file) error{
+	"text": writeText,
+	"json": writeJSON,
+	"csv":  writeCSV,
+}
+
+// writeText writes one line per match, in the form
+// path:line:column: ID (percent of file), with "URL"
+// in place of the percent for license URL matches.
+func writeText(w io.Writer, files []*file) error {
+	for _, f := range files {
+		tab := textpos.NewTable(f.Data)
+		for _, m := range f.Match {
+			pos := tab.Position(m.Start)
+			what := fmt.Sprintf("(%.1f%%)", f.Percent)
+			if m.IsURL {
+				what = "URL"
+			}
+			if _, err := fmt.Fprintf(w, "%s:%d:%d: %s %s\n", f.Path, pos.Line, pos.Column, m.ID, what); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type jsonFile struct {
+	Path    string      `json:"path"`
+	Percent float64     `json:"percent"`
+	Matches []jsonMatch `json:"matches"`
+}
+
+type jsonMatch struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	IsURL  bool   `json:"isURL,omitempty"`
+	Lang   string `json:"language,omitempty"`
+}
+
+// writeJSON writes a JSON array with one object per file.
+func writeJSON(w io.Writer, files []*file) error {
+	list := []jsonFile{}
+	for _, f := range files {
+		tab := textpos.NewTable(f.Data)
+		jf := jsonFile{Path: f.Path, Percent: f.Percent, Matches: []jsonMatch{}}
+		for _, m := range f.Match {
+			pos := tab.Position(m.Start)
+			jf.Matches = append(jf.Matches, jsonMatch{m.ID, m.Type.String(), m.Start, m.End, pos.Line, pos.Column, m.IsURL, m.Language})
+		}
+		list = append(list, jf)
+	}
+	js, err := json.MarshalIndent(list, "", "\t")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(js, '\n'))
+	return err
+}
+
+// writeCSV writes a CSV table with a header row and one row per match.
+func writeCSV(w io.Writer, files []*file) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"path", "percent", "id", "type", "start", "end", "line", "column", "url"})
+	for _, f := range files {
+		tab := textpos.NewTable(f.Data)
+		for _, m := range f.Match {
+			pos := tab.Position(m.Start)
+			cw.Write([]string{
+				f.Path,
+				fmt.Sprintf("%.1f", f.Percent),
+				m.ID,
+				m.Type.String(),
+				fmt.Sprint(m.Start),
+				fmt.Sprint(m.End),
+				fmt.Sprint(pos.Line),
+				fmt.Sprint(pos.Column),
+				fmt.Sprint(m.IsURL),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,80 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// License policy.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// A policy decides which matches are acceptable.
+type policy struct {
+	allow    map[string]bool // if non-nil, the only IDs allowed
+	deny     map[string]bool
+	denyType licensecheck.Type
+}
+
+// newPolicy returns the policy set by the -allow, -deny, and -deny-type flags.
+func newPolicy(allow, deny, denyType string) (*policy, error) {
+	p := &policy{deny: idSet(deny)}
+	if allow != "" {
+		p.allow = idSet(allow)
+	}
+	for _, f := range split(denyType) {
+		t, err := licensecheck.ParseType(f)
+		if err != nil {
+			return nil, fmt.Errorf("-deny-type: %v", err)
+		}
+		p.denyType |= t
+	}
+	return p, nil
+}
+
+// check returns a description of how m violates the policy,
+// or the empty string if it does not.
+func (p *policy) check(m licensecheck.Match) string {
+	id := canonical(m.ID)
+	switch {
+	case p.deny[id]:
+		return fmt.Sprintf("license %s is denied", m.ID)
+	case p.allow != nil && !p.allow[id]:
+		return fmt.Sprintf("license %s is not allowed", m.ID)
+	case m.Type&p.denyType != 0:
+		return fmt.Sprintf("license %s has denied type %v", m.ID, m.Type&p.denyType)
+	}
+	return ""
+}
+
+// idSet returns the set of canonical IDs in the comma-separated list.
+func idSet(list string) map[string]bool {
+	m := make(map[string]bool)
+	for _, id := range split(list) {
+		m[canonical(id)] = true
+	}
+	return m
+}
+
+// canonical returns the canonical form of id, for comparisons.
+func canonical(id string) string {
+	if c, ok := licensecheck.CanonicalID(id); ok {
+		id = c
+	}
+	return strings.ToLower(id)
+}
+
+// split splits the comma-separated list into its non-empty, trimmed elements.
+func split(list string) []string {
+	var out []string
+	for _, f := range strings.Split(list, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,156 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Line-based unified diffs.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// diffContext is the number of unchanged lines shown around each change.
+const diffContext = 3
+
+// diff returns a unified diff from old to new for the named file,
+// or nil if they are the same.
+func diff(file string, old, new []byte) []byte {
+	if bytes.Equal(old, new) {
+		return nil
+	}
+	x, y := lines(old), lines(new)
+	ops := editScript(x, y)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "--- %s.orig\n+++ %s\n", file, file)
+
+	// Group the edit script into hunks, each covering a run of changes
+	// plus diffContext lines of context on either side.
+	for i := 0; i < len(ops); {
+		if ops[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// Find the end of the run of unchanged lines.
+			j := end
+			for j < len(ops) && ops[j].kind == ' ' {
+				j++
+			}
+			if j == len(ops) || j-end > 2*diffContext {
+				end += diffContext
+				if end > len(ops) {
+					end = len(ops)
+				}
+				break
+			}
+			end = j
+		}
+
+		xl, yl := ops[start].x, ops[start].y
+		var xn, yn int
+		for _, op := range ops[start:end] {
+			if op.kind != '+' {
+				xn++
+			}
+			if op.kind != '-' {
+				yn++
+			}
+		}
+		fmt.Fprintf(&buf, "@@ -%s +%s @@\n", hunkRange(xl, xn), hunkRange(yl, yn))
+		for _, op := range ops[start:end] {
+			fmt.Fprintf(&buf, "%c%s", op.kind, op.text)
+			if !strings.HasSuffix(op.text, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	return buf.Bytes()
+}
+
+// hunkRange formats the line range for a hunk header,
+// given the 0-based index of its first line and its line count.
+func hunkRange(start, n int) string {
+	if n == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if n == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, n)
+}
+
+// lines splits data into lines, each including its terminating newline.
+func lines(data []byte) []string {
+	var list []string
+	s := string(data)
+	for s != "" {
+		i := strings.Index(s, "\n")
+		if i < 0 {
+			i = len(s) - 1
+		}
+		list = append(list, s[:i+1])
+		s = s[i+1:]
+	}
+	return list
+}
+
+// A diffOp is a single line in an edit script.
+type diffOp struct {
+	kind byte   // ' ' for an unchanged line, '-' for a deleted line, '+' for an inserted line
+	x, y int    // index of line in old and new
+	text string // line text
+}
+
+// editScript returns a shortest edit script from x to y,
+// computed from their longest common subsequence.
+// The quadratic algorithm is fine for license-sized files.
+func editScript(x, y []string) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			ops = append(ops, diffOp{' ', i, j, x[i]})
+			i++
+			j++
+		case j == len(y) || i < len(x) && lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', i, j, x[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', i, j, y[j]})
+			j++
+		}
+	}
+	return ops
+}
@@ -0,0 +1,28 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+var diffTests = []struct {
+	old, new string
+	diff     string
+}{
+	{"a\nb\n", "a\nb\n", ""},
+	{"a\nb\nc\n", "a\nB\nc\n", "--- f.orig\n+++ f\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+	{"a\n", "a\nb\n", "--- f.orig\n+++ f\n@@ -1 +1,2 @@\n a\n+b\n"},
+	{"a", "a\n", "--- f.orig\n+++ f\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n"},
+	{"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "0\n2\n3\n4\n5\n6\n7\n8\n9\nX\n",
+		"--- f.orig\n+++ f\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+X\n"},
+}
+
+func TestDiff(t *testing.T) {
+	for _, tt := range diffTests {
+		d := string(diff("f", []byte(tt.old), []byte(tt.new)))
+		if d != tt.diff {
+			t.Errorf("diff(%q, %q):\nhave %q\nwant %q", tt.old, tt.new, d, tt.diff)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Lrefmt formats license regular expression (.lre) files.
+//
+// Usage:
+//
+//	lrefmt [-d | -l | -w] [path ...]
+//
+// Lrefmt rewrites each named .lre file, and each .lre file in each
+// named directory, in the canonical layout described by lre.Format.
+// The layout keeps comments and template actions, and it does not change
+// the text the file matches. With no arguments, lrefmt formats standard
+// input to standard output.
+//
+// By default, lrefmt prints the formatted files to standard output.
+// The -d flag prints a diff for each file whose layout is not canonical
+// instead, and the -l flag prints only the names of those files.
+// With -d or -l, the exit status is 1 if any file needs formatting,
+// so that the command can be used as a check in continuous integration.
+// The -w flag writes the formatted result back to each file.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/licensecheck/lre"
+)
+
+var (
+	diffMode  = flag.Bool("d", false, "print diffs instead of formatted files")
+	listMode  = flag.Bool("l", false, "list files whose layout is not canonical")
+	writeMode = flag.Bool("w", false, "write result to source file instead of standard output")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: lrefmt [-d | -l | -w] [path ...]\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("lrefmt: ")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		if *writeMode {
+			log.Print("cannot use -w with standard input")
+			usage()
+		}
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, changed, err := process("<standard input>", src)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if changed && (*diffMode || *listMode) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	status := 0
+	for _, arg := range flag.Args() {
+		files, err := lreFiles(arg)
+		if err != nil {
+			log.Print(err)
+			status = 2
+			continue
+		}
+		for _, file := range files {
+			changed, err := processFile(file)
+			if err != nil {
+				log.Print(err)
+				status = 2
+				continue
+			}
+			if changed && (*diffMode || *listMode) && status == 0 {
+				status = 1
+			}
+		}
+	}
+	os.Exit(status)
+}
+
+// lreFiles returns the .lre files named by arg:
+// arg itself if it is a file, or the .lre files in the tree rooted at arg
+// if it is a directory.
+func lreFiles(arg string) ([]string, error) {
+	info, err := os.Stat(arg)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{arg}, nil
+	}
+	var files []string
+	err = filepath.Walk(arg, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".lre") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// processFile formats the named file and reports whether its layout changed.
+func processFile(file string) (bool, error) {
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	out, changed, err := process(file, src)
+	if err != nil {
+		return false, err
+	}
+	if changed && *writeMode {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		if err := ioutil.WriteFile(file, out, info.Mode()); err != nil {
+			return false, err
+		}
+	}
+	return changed, nil
+}
+
+// process formats src, read from the named file, printing the result
+// as directed by the -d and -l flags, or to standard output if neither
+// they nor -w is set. It returns the formatted result and reports
+// whether the layout changed.
+func process(file string, src []byte) ([]byte, bool, error) {
+	out, err := lre.Format(src)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %v", file, err)
+	}
+	changed := !bytes.Equal(src, out)
+	switch {
+	case *listMode:
+		if changed {
+			fmt.Println(file)
+		}
+	case *diffMode:
+		if changed {
+			os.Stdout.Write(diff(file, src, out))
+		}
+	case !*writeMode:
+		os.Stdout.Write(out)
+	}
+	return out, changed, nil
+}
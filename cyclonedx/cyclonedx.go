@@ -0,0 +1,252 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cyclonedx writes the results of scanning file trees
+// as CycloneDX 1.5 bills of materials, in JSON or XML format.
+//
+// A BOM holds one or more components, each describing one scanned tree.
+// A component's licenses list the license it declares, if known,
+// or else the licenses found in its files: a single license is written
+// as a license entry, with license.id for SPDX IDs and license.name
+// plus the license text for IDs that licensecheck defines itself,
+// and multiple licenses are combined into a single expression.
+//
+// The component's evidence records each license match, with the file,
+// byte offsets, and coverage percent as license properties,
+// along with the file locations and the copyright notices found.
+//
+// For example:
+//
+//	bom := cyclonedx.New()
+//	if _, err := bom.AddComponent(&cyclonedx.Component{Name: "example"}, os.DirFS(dir), ".", nil); err != nil {
+//		log.Fatal(err)
+//	}
+//	bom.WriteJSON(os.Stdout)
+package cyclonedx
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/sbom"
+)
+
+// A BOM is a CycloneDX bill of materials describing scanned components.
+type BOM struct {
+	SerialNumber string // "urn:uuid:..."
+	Version      int
+	Timestamp    time.Time
+	Components   []*Component
+}
+
+// A Component describes a single component in a BOM.
+// The caller of AddComponent sets the identifying fields;
+// AddComponent sets the rest from the scan.
+type Component struct {
+	// Set by the caller.
+	Type     string // CycloneDX component type; empty means "library"
+	Name     string
+	Version  string
+	PURL     string // package URL
+	Declared string // declared license expression, if known
+
+	// Set by AddComponent.
+	BOMRef     string
+	Licenses   []License // licenses: a single license or a single expression
+	Evidence   []Evidence
+	Copyrights []string
+}
+
+// A License is a single entry in a CycloneDX licenses list.
+// Exactly one of ID, Name, or Expression is set.
+type License struct {
+	ID         string // SPDX license ID
+	Name       string // licensecheck ID not defined by SPDX
+	Text       string // license text, for licenses with a Name
+	Expression string // SPDX license expression
+}
+
+// An Evidence records a single license match in a component's files.
+type Evidence struct {
+	License License
+	File    string  // slash-separated path relative to the component root
+	Start   int     // start byte offset of match in file
+	End     int     // end byte offset of match in file
+	Percent float64 // percentage of the file covered by licenses
+	IsURL   bool    // whether the match is a license URL
+}
+
+// New returns a new, empty BOM with a random serial number,
+// version 1, and the current time as its timestamp.
+func New() *BOM {
+	var u [16]byte
+	if _, err := rand.Read(u[:]); err != nil {
+		panic("cyclonedx: reading random UUID: " + err.Error())
+	}
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // variant 10
+	return &BOM{
+		SerialNumber: fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]),
+		Version:      1,
+		Timestamp:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// AddComponent adds the component c, whose files are the file tree rooted at root
+// in fsys, to the BOM. The scan is the result of licensecheck.ScanFS (or a Scanner's
+// ScanFS method) for the same tree; if scan is nil, AddComponent calls licensecheck.ScanFS.
+//
+// AddComponent fills in c's BOMRef, Licenses, Evidence, and Copyrights fields.
+// It returns c.
+func (b *BOM) AddComponent(c *Component, fsys fs.FS, root string, scan []licensecheck.FileCoverage) (*Component, error) {
+	files, err := sbom.Walk(fsys, root, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	c.BOMRef = b.uniqueRef(c.Name, c.Version)
+	c.Licenses = nil
+	c.Evidence = nil
+	c.Copyrights = nil
+
+	var ids []string
+	texts := make(map[string]string) // licensecheck ID -> license text
+	seenCopyright := make(map[string]bool)
+	for _, f := range files {
+		rel := strings.TrimPrefix(f.Path, root+"/")
+		if root == "." {
+			rel = f.Path
+		}
+		for i, m := range f.Match {
+			if _, ok := texts[m.ID]; !ok {
+				ids = append(ids, m.ID)
+				texts[m.ID] = ""
+			}
+			if !m.IsURL && texts[m.ID] == "" {
+				texts[m.ID] = f.Texts[i]
+			}
+			c.Evidence = append(c.Evidence, Evidence{
+				License: License{ID: m.ID}, // rewritten below
+				File:    rel,
+				Start:   m.Start,
+				End:     m.End,
+				Percent: f.Percent,
+				IsURL:   m.IsURL,
+			})
+		}
+		for _, cr := range f.Copyrights {
+			if !seenCopyright[cr] {
+				seenCopyright[cr] = true
+				c.Copyrights = append(c.Copyrights, cr)
+			}
+		}
+	}
+	for i := range c.Evidence {
+		// The license text appears once, in c.Licenses, not in each match.
+		c.Evidence[i].License = license(c.Evidence[i].License.ID, "")
+	}
+
+	switch {
+	case c.Declared != "":
+		x := strings.TrimSpace(c.Declared)
+		if id, ok := licensecheck.CanonicalID(x); ok {
+			x = id
+		}
+		if strings.ContainsAny(x, " ()+") {
+			c.Licenses = []License{{Expression: licensecheck.SPDXExpression(x)}}
+		} else {
+			c.Licenses = []License{license(x, texts[x])}
+		}
+	case len(ids) == 1:
+		c.Licenses = []License{license(ids[0], texts[ids[0]])}
+	case len(ids) > 1:
+		sort.Strings(ids)
+		c.Licenses = []License{{Expression: licensecheck.SPDXExpression(strings.Join(ids, " AND "))}}
+	}
+
+	b.Components = append(b.Components, c)
+	return c, nil
+}
+
+// license returns the License entry for the licensecheck ID id.
+func license(id, text string) License {
+	if licensecheck.IsSPDX(id) {
+		return License{ID: id}
+	}
+	return License{Name: id, Text: text}
+}
+
+// uniqueRef returns a new bom-ref for a component with the given name and version.
+func (b *BOM) uniqueRef(name, version string) string {
+	base := name
+	if version != "" {
+		base += "@" + version
+	}
+	if base == "" {
+		base = "component"
+	}
+	ref := base
+	for n := 2; ; n++ {
+		dup := false
+		for _, c := range b.Components {
+			if c.BOMRef == ref {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			return ref
+		}
+		ref = fmt.Sprintf("%s#%d", base, n)
+	}
+}
+
+// componentType returns the CycloneDX type for c.
+func (c *Component) componentType() string {
+	if c.Type == "" {
+		return "library"
+	}
+	return c.Type
+}
+
+// Property names used in license evidence.
+const (
+	propFile    = "licensecheck:file"
+	propStart   = "licensecheck:start"
+	propEnd     = "licensecheck:end"
+	propPercent = "licensecheck:percent"
+	propIsURL   = "licensecheck:url"
+)
+
+// properties returns the CycloneDX properties recording e's location.
+func (e *Evidence) properties() [][2]string {
+	props := [][2]string{
+		{propFile, e.File},
+		{propStart, fmt.Sprint(e.Start)},
+		{propEnd, fmt.Sprint(e.End)},
+		{propPercent, fmt.Sprintf("%.1f", e.Percent)},
+	}
+	if e.IsURL {
+		props = append(props, [2]string{propIsURL, "true"})
+	}
+	return props
+}
+
+// occurrences returns the distinct files in c's evidence, in order.
+func (c *Component) occurrences() []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, e := range c.Evidence {
+		if !seen[e.File] {
+			seen[e.File] = true
+			list = append(list, e.File)
+		}
+	}
+	return list
+}
@@ -0,0 +1,189 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cyclonedx
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testText returns the license text from ../testdata/name,
+// without the test header.
+func testText(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.Index(string(data), "\n\n")
+	if i < 0 {
+		t.Fatalf("%s: malformed test file", name)
+	}
+	return data[i+2:]
+}
+
+func testBOM(t *testing.T) *BOM {
+	mit := testText(t, "MIT.t1")
+	patents := testText(t, "GooglePatentsFile.t1")
+	fsys := fstest.MapFS{
+		// Single SPDX license.
+		"a/LICENSE": {Data: append([]byte("Copyright 2020 A Authors\n\n"), mit...)},
+		"a/a.go":    {Data: []byte("package a\n")},
+
+		// Combined result, including a licensecheck-only ID.
+		"b/LICENSE": {Data: mit},
+		"b/PATENTS": {Data: patents},
+
+		// Single licensecheck-only license.
+		"c/PATENTS": {Data: patents},
+	}
+	b := New()
+	b.SerialNumber = "urn:uuid:00000000-0000-4000-8000-000000000000"
+	b.Timestamp = time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range []*Component{
+		{Name: "a", Version: "1.0", PURL: "pkg:golang/a@1.0"},
+		{Name: "b"},
+		{Name: "c"},
+		{Name: "d", Declared: "MIT OR GooglePatentsFile"},
+	} {
+		root := c.Name
+		if root == "d" {
+			root = "b"
+		}
+		if _, err := b.AddComponent(c, fsys, root, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b
+}
+
+func TestAddComponent(t *testing.T) {
+	b := testBOM(t)
+	if len(b.Components) != 4 {
+		t.Fatalf("have %d components, want 4", len(b.Components))
+	}
+	a, bb, c, d := b.Components[0], b.Components[1], b.Components[2], b.Components[3]
+
+	if a.BOMRef != "a@1.0" || len(a.Licenses) != 1 || a.Licenses[0] != (License{ID: "MIT"}) {
+		t.Errorf("a: %s %+v, want a@1.0 [MIT]", a.BOMRef, a.Licenses)
+	}
+	if len(a.Evidence) != 1 || a.Evidence[0].File != "LICENSE" || a.Evidence[0].Start != 0 || a.Evidence[0].End == 0 || a.Evidence[0].Percent < 90 {
+		t.Errorf("a: evidence %+v", a.Evidence)
+	}
+	if len(a.Copyrights) != 1 || a.Copyrights[0] != "Copyright 2020 A Authors" {
+		t.Errorf("a: copyrights %q", a.Copyrights)
+	}
+
+	if len(bb.Licenses) != 1 || bb.Licenses[0].Expression != "LicenseRef-GooglePatentsFile AND MIT" {
+		t.Errorf("b: %+v, want expression", bb.Licenses)
+	}
+	if len(bb.Evidence) != 2 || bb.Evidence[1].License != (License{Name: "GooglePatentsFile"}) {
+		t.Errorf("b: evidence %+v", bb.Evidence)
+	}
+
+	if len(c.Licenses) != 1 || c.Licenses[0].Name != "GooglePatentsFile" || !strings.Contains(c.Licenses[0].Text, "patent") {
+		t.Errorf("c: %+v, want GooglePatentsFile with text", c.Licenses)
+	}
+
+	if len(d.Licenses) != 1 || d.Licenses[0].Expression != "MIT OR LicenseRef-GooglePatentsFile" {
+		t.Errorf("d: %+v, want declared expression", d.Licenses)
+	}
+}
+
+func TestNew(t *testing.T) {
+	b1, b2 := New(), New()
+	re := regexp.MustCompile(`^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !re.MatchString(b1.SerialNumber) || b1.SerialNumber == b2.SerialNumber || b1.Version != 1 {
+		t.Errorf("New() = %+v, %+v", b1, b2)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testBOM(t).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var js struct {
+		BOMFormat   string
+		SpecVersion string
+		Components  []struct {
+			Name     string
+			Licenses []struct {
+				License *struct {
+					ID   string
+					Name string
+					Text *struct{ Content string }
+				}
+				Expression string
+			}
+			Evidence *struct {
+				Occurrences []struct{ Location string }
+				Licenses    []struct {
+					License struct {
+						ID         string
+						Properties []struct{ Name, Value string }
+					}
+				}
+				Copyright []struct{ Text string }
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &js); err != nil {
+		t.Fatal(err)
+	}
+	ok := js.BOMFormat == "CycloneDX" && js.SpecVersion == "1.5" && len(js.Components) == 4
+	if ok {
+		a := js.Components[0]
+		ok = len(a.Licenses) == 1 && a.Licenses[0].License != nil && a.Licenses[0].License.ID == "MIT" &&
+			a.Evidence != nil && len(a.Evidence.Occurrences) == 1 && a.Evidence.Occurrences[0].Location == "LICENSE" &&
+			len(a.Evidence.Licenses) == 1 && len(a.Evidence.Licenses[0].License.Properties) == 4 &&
+			a.Evidence.Licenses[0].License.Properties[0].Value == "LICENSE" &&
+			len(a.Evidence.Copyright) == 1
+		c := js.Components[2]
+		ok = ok && len(c.Licenses) == 1 && c.Licenses[0].License != nil && c.Licenses[0].License.Name == "GooglePatentsFile" &&
+			c.Licenses[0].License.Text != nil && c.Licenses[0].License.Text.Content != ""
+		bb := js.Components[1]
+		ok = ok && len(bb.Licenses) == 1 && bb.Licenses[0].License == nil && bb.Licenses[0].Expression != ""
+	}
+	if !ok {
+		t.Errorf("WriteJSON output:\n%s", buf.String())
+	}
+}
+
+func TestWriteXML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testBOM(t).WriteXML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, s := range []string{
+		`<bom xmlns="http://cyclonedx.org/schema/bom/1.5" serialNumber="urn:uuid:00000000-0000-4000-8000-000000000000" version="1">`,
+		`<timestamp>2020-12-01T00:00:00Z</timestamp>`,
+		`<component type="library" bom-ref="a@1.0">`,
+		`<license>` + "\n\t\t\t\t\t" + `<id>MIT</id>`,
+		`<expression>LicenseRef-GooglePatentsFile AND MIT</expression>`,
+		`<name>GooglePatentsFile</name>`,
+		`<text content-type="text/plain">`,
+		`<occurrence>` + "\n\t\t\t\t\t\t" + `<location>LICENSE</location>`,
+		`<property name="licensecheck:file">LICENSE</property>`,
+		`<text>Copyright 2020 A Authors</text>`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("WriteXML output missing %q", s)
+		}
+	}
+	if err := xml.Unmarshal(buf.Bytes(), new(struct{})); err != nil {
+		t.Errorf("WriteXML output does not parse: %v", err)
+	}
+	if t.Failed() {
+		t.Logf("output:\n%s", out)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CycloneDX JSON format.
+
+package cyclonedx
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// The json* types mirror the CycloneDX 1.5 JSON schema.
+
+type jsonBOM struct {
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber,omitempty"`
+	Version      int             `json:"version"`
+	Metadata     jsonMetadata    `json:"metadata"`
+	Components   []jsonComponent `json:"components,omitempty"`
+}
+
+type jsonMetadata struct {
+	Timestamp string    `json:"timestamp"`
+	Tools     jsonTools `json:"tools"`
+}
+
+type jsonTools struct {
+	Components []jsonTool `json:"components"`
+}
+
+type jsonTool struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type jsonComponent struct {
+	Type     string        `json:"type"`
+	BOMRef   string        `json:"bom-ref,omitempty"`
+	Name     string        `json:"name"`
+	Version  string        `json:"version,omitempty"`
+	Licenses []jsonLicense `json:"licenses,omitempty"`
+	PURL     string        `json:"purl,omitempty"`
+	Evidence *jsonEvidence `json:"evidence,omitempty"`
+}
+
+// A jsonLicense is a license choice: either a license or an expression.
+type jsonLicense struct {
+	License    *jsonLicenseInfo `json:"license,omitempty"`
+	Expression string           `json:"expression,omitempty"`
+}
+
+type jsonLicenseInfo struct {
+	ID         string         `json:"id,omitempty"`
+	Name       string         `json:"name,omitempty"`
+	Text       *jsonText      `json:"text,omitempty"`
+	Properties []jsonProperty `json:"properties,omitempty"`
+}
+
+type jsonText struct {
+	ContentType string `json:"contentType"`
+	Content     string `json:"content"`
+}
+
+type jsonProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type jsonEvidence struct {
+	Occurrences []jsonOccurrence `json:"occurrences,omitempty"`
+	Licenses    []jsonLicense    `json:"licenses,omitempty"`
+	Copyright   []jsonCopyright  `json:"copyright,omitempty"`
+}
+
+type jsonOccurrence struct {
+	Location string `json:"location"`
+}
+
+type jsonCopyright struct {
+	Text string `json:"text"`
+}
+
+// WriteJSON writes the BOM to w in CycloneDX 1.5 JSON format.
+func (b *BOM) WriteJSON(w io.Writer) error {
+	js, err := b.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(js, '\n'))
+	return err
+}
+
+// MarshalJSON returns the BOM in CycloneDX 1.5 JSON format.
+func (b *BOM) MarshalJSON() ([]byte, error) {
+	jb := jsonBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: b.SerialNumber,
+		Version:      b.Version,
+		Metadata: jsonMetadata{
+			Timestamp: b.Timestamp.UTC().Format(time.RFC3339),
+			Tools:     jsonTools{[]jsonTool{{"application", "licensecheck"}}},
+		},
+	}
+	for _, c := range b.Components {
+		jc := jsonComponent{
+			Type:    c.componentType(),
+			BOMRef:  c.BOMRef,
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+		for _, l := range c.Licenses {
+			jc.Licenses = append(jc.Licenses, jsonLicenseChoice(l, nil))
+		}
+		if len(c.Evidence) > 0 || len(c.Copyrights) > 0 {
+			ev := new(jsonEvidence)
+			for _, file := range c.occurrences() {
+				ev.Occurrences = append(ev.Occurrences, jsonOccurrence{file})
+			}
+			for i := range c.Evidence {
+				e := &c.Evidence[i]
+				ev.Licenses = append(ev.Licenses, jsonLicenseChoice(e.License, e.properties()))
+			}
+			for _, cr := range c.Copyrights {
+				ev.Copyright = append(ev.Copyright, jsonCopyright{cr})
+			}
+			jc.Evidence = ev
+		}
+		jb.Components = append(jb.Components, jc)
+	}
+	return json.MarshalIndent(jb, "", "\t")
+}
+
+func jsonLicenseChoice(l License, props [][2]string) jsonLicense {
+	if l.Expression != "" {
+		return jsonLicense{Expression: l.Expression}
+	}
+	info := &jsonLicenseInfo{ID: l.ID, Name: l.Name}
+	if l.Text != "" {
+		info.Text = &jsonText{"text/plain", l.Text}
+	}
+	for _, p := range props {
+		info.Properties = append(info.Properties, jsonProperty{p[0], p[1]})
+	}
+	return jsonLicense{License: info}
+}
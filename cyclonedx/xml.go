@@ -0,0 +1,146 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// CycloneDX XML format.
+
+package cyclonedx
+
+import (
+	"encoding/xml"
+	"io"
+	"time"
+)
+
+// The xml* types mirror the CycloneDX 1.5 XML schema.
+// Element order matters: it follows the schema's sequences.
+
+type xmlBOM struct {
+	XMLName      xml.Name       `xml:"http://cyclonedx.org/schema/bom/1.5 bom"`
+	SerialNumber string         `xml:"serialNumber,attr,omitempty"`
+	Version      int            `xml:"version,attr"`
+	Timestamp    string         `xml:"metadata>timestamp"`
+	Tools        []xmlTool      `xml:"metadata>tools>components>component"`
+	Components   []xmlComponent `xml:"components>component"`
+}
+
+type xmlTool struct {
+	Type string `xml:"type,attr"`
+	Name string `xml:"name"`
+}
+
+type xmlComponent struct {
+	Type     string       `xml:"type,attr"`
+	BOMRef   string       `xml:"bom-ref,attr,omitempty"`
+	Name     string       `xml:"name"`
+	Version  string       `xml:"version,omitempty"`
+	Licenses *xmlLicenses `xml:"licenses"`
+	PURL     string       `xml:"purl,omitempty"`
+	Evidence *xmlEvidence `xml:"evidence"`
+}
+
+// An xmlLicenses is a license choice: licenses or a single expression.
+type xmlLicenses struct {
+	License    []xmlLicense `xml:"license"`
+	Expression string       `xml:"expression,omitempty"`
+}
+
+type xmlLicense struct {
+	ID         string         `xml:"id,omitempty"`
+	Name       string         `xml:"name,omitempty"`
+	Text       *xmlText       `xml:"text"`
+	Properties *xmlProperties `xml:"properties"`
+}
+
+type xmlText struct {
+	ContentType string `xml:"content-type,attr"`
+	Content     string `xml:",chardata"`
+}
+
+type xmlProperties struct {
+	Property []xmlProperty `xml:"property"`
+}
+
+type xmlProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlOccurrence struct {
+	Location string `xml:"location"`
+}
+
+type xmlEvidence struct {
+	Occurrences []xmlOccurrence `xml:"occurrences>occurrence"`
+	Licenses    *xmlLicenses    `xml:"licenses"`
+	Copyright   []string        `xml:"copyright>text"`
+}
+
+// WriteXML writes the BOM to w in CycloneDX 1.5 XML format.
+func (b *BOM) WriteXML(w io.Writer) error {
+	xb := xmlBOM{
+		SerialNumber: b.SerialNumber,
+		Version:      b.Version,
+		Timestamp:    b.Timestamp.UTC().Format(time.RFC3339),
+		Tools:        []xmlTool{{"application", "licensecheck"}},
+	}
+	for _, c := range b.Components {
+		xc := xmlComponent{
+			Type:    c.componentType(),
+			BOMRef:  c.BOMRef,
+			Name:    c.Name,
+			Version: c.Version,
+			PURL:    c.PURL,
+		}
+		if len(c.Licenses) > 0 {
+			xc.Licenses = new(xmlLicenses)
+			for _, l := range c.Licenses {
+				xc.Licenses.add(l, nil)
+			}
+		}
+		if len(c.Evidence) > 0 || len(c.Copyrights) > 0 {
+			ev := &xmlEvidence{Copyright: c.Copyrights}
+			for _, file := range c.occurrences() {
+				ev.Occurrences = append(ev.Occurrences, xmlOccurrence{file})
+			}
+			if len(c.Evidence) > 0 {
+				ev.Licenses = new(xmlLicenses)
+				for i := range c.Evidence {
+					e := &c.Evidence[i]
+					ev.Licenses.add(e.License, e.properties())
+				}
+			}
+			xc.Evidence = ev
+		}
+		xb.Components = append(xb.Components, xc)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "\t")
+	if err := enc.Encode(xb); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (x *xmlLicenses) add(l License, props [][2]string) {
+	if l.Expression != "" {
+		x.Expression = l.Expression
+		return
+	}
+	xl := xmlLicense{ID: l.ID, Name: l.Name}
+	if l.Text != "" {
+		xl.Text = &xmlText{"text/plain", l.Text}
+	}
+	if len(props) > 0 {
+		xl.Properties = new(xmlProperties)
+		for _, p := range props {
+			xl.Properties.Property = append(xl.Properties.Property, xmlProperty{p[0], p[1]})
+		}
+	}
+	x.License = append(x.License, xl)
+}
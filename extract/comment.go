@@ -0,0 +1,421 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Comment extraction from source files.
+
+package extract
+
+import (
+	"bytes"
+	"path"
+	"strings"
+)
+
+// Comments returns the text of the comments in the source file
+// with the given name and contents, with the comment markers removed.
+// Each comment line becomes a line of the result, without the
+// comment marker (such as //, #, --, ;, REM, or dnl) and without
+// the decorations commonly used in block comments, such as
+// the leading * on each line of a /* */ comment.
+// In Python files, docstrings are treated as comments.
+//
+// The comment syntax is chosen by the file name's extension
+// or base name (such as "Makefile"), or else by the interpreter
+// named on a #! line at the start of the file.
+// If neither identifies a known language, Comments returns nil, false.
+func Comments(name string, data []byte) (*Text, bool) {
+	syn := commentSyntaxFor(name, data)
+	if syn == nil {
+		return nil, false
+	}
+	return syn.extract(data), true
+}
+
+// A commentSyntax describes the comment syntax of a family of languages.
+type commentSyntax struct {
+	line       []string // line comment markers
+	block      []delims // block comment delimiters
+	nest       bool     // block comments nest
+	quotes     string   // quotes for strings ending at the closing quote or end of line
+	rawQuotes  string   // quotes for strings that can span lines, without escapes
+	triple     bool     // triple-quoted strings can span lines
+	docstrings bool     // triple-quoted strings beginning a line are comments
+	wordStart  bool     // line comment markers must begin a word
+	fold       bool     // line comment markers are case-insensitive
+	escape     bool     // backslash escapes the next byte outside strings and comments
+}
+
+// delims are the delimiters of a block comment.
+// Lines inside the comment can be decorated by a run of
+// bullet bytes at their start or end, which Comments removes.
+type delims struct {
+	open, close string
+	bullet      byte
+}
+
+var (
+	cStyle      = delims{"/*", "*/", '*'}
+	mlStyle     = delims{"(*", "*)", '*'}
+	haskellBlk  = delims{"{-", "-}", '-'}
+	luaBlk      = delims{"--[[", "]]", '-'}
+	lispBlk     = delims{"#|", "|#", '|'}
+	psBlk       = delims{"<#", "#>", '#'}
+	pascalBlk   = delims{"{", "}", 0}
+	hashComment = []string{"#"}
+)
+
+var (
+	cSyntax       = &commentSyntax{line: []string{"//"}, block: []delims{cStyle}, quotes: `"'`, rawQuotes: "`"}
+	rustSyntax    = &commentSyntax{line: []string{"//"}, block: []delims{cStyle}, nest: true, quotes: `"`}
+	cssSyntax     = &commentSyntax{block: []delims{cStyle}, quotes: `"'`}
+	phpSyntax     = &commentSyntax{line: []string{"//", "#"}, block: []delims{cStyle}, quotes: `"'`}
+	hashSyntax    = &commentSyntax{line: hashComment, quotes: `"'`}
+	shellSyntax   = &commentSyntax{line: hashComment, quotes: `"'`, wordStart: true}
+	pythonSyntax  = &commentSyntax{line: hashComment, quotes: `"'`, triple: true, docstrings: true}
+	psSyntax      = &commentSyntax{line: hashComment, block: []delims{psBlk}, quotes: `"'`}
+	sqlSyntax     = &commentSyntax{line: []string{"--"}, block: []delims{cStyle}, quotes: `"'`}
+	luaSyntax     = &commentSyntax{line: []string{"--"}, block: []delims{luaBlk}, quotes: `"'`}
+	haskellSyntax = &commentSyntax{line: []string{"--"}, block: []delims{haskellBlk}, nest: true, quotes: `"`}
+	adaSyntax     = &commentSyntax{line: []string{"--"}, quotes: `"`}
+	lispSyntax    = &commentSyntax{line: []string{";"}, block: []delims{lispBlk}, quotes: `"`}
+	asmSyntax     = &commentSyntax{line: []string{";"}, quotes: `"'`}
+	iniSyntax     = &commentSyntax{line: []string{";", "#"}, wordStart: true}
+	texSyntax     = &commentSyntax{line: []string{"%"}, escape: true}
+	erlangSyntax  = &commentSyntax{line: []string{"%"}, quotes: `"`}
+	batchSyntax   = &commentSyntax{line: []string{"rem", "::"}, wordStart: true, fold: true}
+	m4Syntax      = &commentSyntax{line: []string{"dnl", "#"}, wordStart: true}
+	vbSyntax      = &commentSyntax{line: []string{"'", "rem"}, quotes: `"`, fold: true}
+	fortranSyntax = &commentSyntax{line: []string{"!"}, quotes: `"'`}
+	mlSyntax      = &commentSyntax{block: []delims{mlStyle}, nest: true, quotes: `"`}
+	pascalSyntax  = &commentSyntax{line: []string{"//"}, block: []delims{mlStyle, pascalBlk}, quotes: `'`}
+)
+
+// commentSyntaxByExt maps file extensions to comment syntaxes.
+var commentSyntaxByExt = map[string]*commentSyntax{
+	".c": cSyntax, ".h": cSyntax, ".cc": cSyntax, ".cpp": cSyntax, ".cxx": cSyntax,
+	".hh": cSyntax, ".hpp": cSyntax, ".hxx": cSyntax, ".m": cSyntax, ".mm": cSyntax,
+	".java": cSyntax, ".js": cSyntax, ".mjs": cSyntax, ".cjs": cSyntax, ".jsx": cSyntax,
+	".ts": cSyntax, ".tsx": cSyntax, ".go": cSyntax, ".cs": cSyntax, ".kt": cSyntax,
+	".kts": cSyntax, ".scala": cSyntax, ".groovy": cSyntax, ".gradle": cSyntax,
+	".dart": cSyntax, ".proto": cSyntax, ".sol": cSyntax, ".zig": cSyntax,
+	".rs": rustSyntax, ".swift": rustSyntax,
+	".css": cssSyntax, ".php": phpSyntax,
+	".py": pythonSyntax, ".pyi": pythonSyntax, ".pyx": pythonSyntax,
+	".sh": shellSyntax, ".bash": shellSyntax, ".zsh": shellSyntax, ".ksh": shellSyntax,
+	".rb": hashSyntax, ".pl": hashSyntax, ".pm": hashSyntax, ".r": hashSyntax,
+	".tcl": hashSyntax, ".yaml": hashSyntax, ".yml": hashSyntax, ".toml": hashSyntax,
+	".cmake": hashSyntax, ".mk": hashSyntax, ".nix": hashSyntax, ".jl": hashSyntax,
+	".ex": hashSyntax, ".exs": hashSyntax, ".awk": hashSyntax, ".tf": hashSyntax,
+	".bzl": hashSyntax, ".bazel": hashSyntax,
+	".ps1": psSyntax, ".psm1": psSyntax,
+	".sql": sqlSyntax, ".lua": luaSyntax,
+	".hs": haskellSyntax, ".elm": haskellSyntax,
+	".ada": adaSyntax, ".adb": adaSyntax, ".ads": adaSyntax, ".vhd": adaSyntax, ".vhdl": adaSyntax,
+	".lisp": lispSyntax, ".lsp": lispSyntax, ".el": lispSyntax, ".cl": lispSyntax,
+	".clj": lispSyntax, ".scm": lispSyntax, ".rkt": lispSyntax,
+	".asm": asmSyntax, ".s": asmSyntax,
+	".ini": iniSyntax, ".cfg": iniSyntax, ".conf": iniSyntax,
+	".tex": texSyntax, ".sty": texSyntax, ".cls": texSyntax, ".bib": texSyntax,
+	".erl": erlangSyntax, ".hrl": erlangSyntax,
+	".bat": batchSyntax, ".cmd": batchSyntax,
+	".m4": m4Syntax, ".ac": m4Syntax,
+	".vb": vbSyntax, ".vbs": vbSyntax, ".bas": vbSyntax,
+	".f": fortranSyntax, ".f90": fortranSyntax, ".f95": fortranSyntax, ".f03": fortranSyntax,
+	".ml": mlSyntax, ".mli": mlSyntax,
+	".pas": pascalSyntax, ".pp": pascalSyntax, ".dpr": pascalSyntax,
+}
+
+// commentSyntaxByName maps file base names to comment syntaxes.
+var commentSyntaxByName = map[string]*commentSyntax{
+	"Makefile":       hashSyntax,
+	"makefile":       hashSyntax,
+	"GNUmakefile":    hashSyntax,
+	"Dockerfile":     hashSyntax,
+	"CMakeLists.txt": hashSyntax,
+	"BUILD":          hashSyntax,
+	"WORKSPACE":      hashSyntax,
+	"Rakefile":       hashSyntax,
+	"Gemfile":        hashSyntax,
+	"configure.in":   m4Syntax,
+}
+
+// commentSyntaxByInterp maps interpreters named on #! lines to comment syntaxes.
+var commentSyntaxByInterp = map[string]*commentSyntax{
+	"sh": shellSyntax, "bash": shellSyntax, "zsh": shellSyntax, "ksh": shellSyntax,
+	"dash": shellSyntax, "ash": shellSyntax,
+	"python": pythonSyntax, "pypy": pythonSyntax,
+	"perl": hashSyntax, "ruby": hashSyntax, "Rscript": hashSyntax, "tclsh": hashSyntax,
+	"wish": hashSyntax, "awk": hashSyntax, "gawk": hashSyntax, "make": hashSyntax,
+	"node": cSyntax, "nodejs": cSyntax, "deno": cSyntax,
+	"lua": luaSyntax, "php": phpSyntax, "pwsh": psSyntax,
+}
+
+// commentSyntaxFor returns the comment syntax for the named file,
+// or nil if the language is unknown.
+func commentSyntaxFor(name string, data []byte) *commentSyntax {
+	base := path.Base(strings.ReplaceAll(name, `\`, "/"))
+	if syn := commentSyntaxByName[base]; syn != nil {
+		return syn
+	}
+	if syn := commentSyntaxByExt[strings.ToLower(path.Ext(base))]; syn != nil {
+		return syn
+	}
+	return commentSyntaxByInterp[interpreter(data)]
+}
+
+// interpreter returns the name of the interpreter on the #! line
+// at the start of data, without any version number,
+// or "" if there is no #! line.
+func interpreter(data []byte) string {
+	if !bytes.HasPrefix(data, []byte("#!")) {
+		return ""
+	}
+	line := data[2:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	f := strings.Fields(string(line))
+	if len(f) == 0 {
+		return ""
+	}
+	name := path.Base(f[0])
+	if name == "env" {
+		name = ""
+		for _, arg := range f[1:] {
+			if !strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") {
+				name = arg
+				break
+			}
+		}
+	}
+	return strings.TrimRight(name, "0123456789.")
+}
+
+// extract returns the text of the comments in data.
+func (syn *commentSyntax) extract(data []byte) *Text {
+	var b Builder
+	i := 0
+	if bytes.HasPrefix(data, []byte("#!")) {
+		// Skip #! line.
+		i = len(data)
+		if j := bytes.IndexByte(data, '\n'); j >= 0 {
+			i = j
+		}
+	}
+	lineStart := 0
+	skip := func(end int) {
+		if j := bytes.LastIndexByte(data[i:end], '\n'); j >= 0 {
+			lineStart = i + j + 1
+		}
+		i = end
+	}
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '\n':
+			i++
+			lineStart = i
+			continue
+
+		case syn.escape && c == '\\':
+			i += 2
+			continue
+
+		case syn.triple && (c == '"' || c == '\'') && bytes.HasPrefix(data[i:], []byte{c, c, c}):
+			q := string([]byte{c, c, c})
+			lo := i + 3
+			hi := len(data)
+			end := hi
+			if j := strings.Index(string(data[lo:]), q); j >= 0 {
+				hi = lo + j
+				end = hi + 3
+			}
+			if syn.docstrings && isDocstringStart(data[lineStart:i]) {
+				b.block(data, lo, hi, end, 0)
+			}
+			skip(end)
+			continue
+
+		case strings.IndexByte(syn.quotes, c) >= 0:
+			skip(skipString(data, i, true))
+			continue
+
+		case strings.IndexByte(syn.rawQuotes, c) >= 0:
+			skip(skipString(data, i, false))
+			continue
+		}
+
+		if d, ok := syn.blockAt(data, i); ok {
+			lo := i + len(d.open)
+			hi, end := syn.blockEnd(data, lo, d)
+			b.block(data, lo, hi, end, d.bullet)
+			skip(end)
+			continue
+		}
+		if n := syn.lineAt(data, i, lineStart); n > 0 {
+			i = b.line(data, i, n)
+			continue
+		}
+		i++
+	}
+	return b.Text()
+}
+
+// blockAt reports whether a block comment starts at data[i:],
+// returning its delimiters.
+func (syn *commentSyntax) blockAt(data []byte, i int) (delims, bool) {
+	for _, d := range syn.block {
+		if bytes.HasPrefix(data[i:], []byte(d.open)) {
+			return d, true
+		}
+	}
+	return delims{}, false
+}
+
+// blockEnd returns the end of the block comment text starting at data[lo:],
+// and the end of its closing delimiter.
+// An unterminated comment ends at the end of data.
+func (syn *commentSyntax) blockEnd(data []byte, lo int, d delims) (hi, end int) {
+	depth := 1
+	for i := lo; i < len(data); i++ {
+		if syn.nest && bytes.HasPrefix(data[i:], []byte(d.open)) {
+			depth++
+			i += len(d.open) - 1
+			continue
+		}
+		if bytes.HasPrefix(data[i:], []byte(d.close)) {
+			if depth--; depth == 0 {
+				return i, i + len(d.close)
+			}
+			i += len(d.close) - 1
+		}
+	}
+	return len(data), len(data)
+}
+
+// lineAt returns the length of the line comment marker at data[i:],
+// or 0 if there is none. The current line starts at data[lineStart].
+func (syn *commentSyntax) lineAt(data []byte, i, lineStart int) int {
+	for _, m := range syn.line {
+		if len(data)-i < len(m) {
+			continue
+		}
+		text := string(data[i : i+len(m)])
+		if text != m && !(syn.fold && strings.EqualFold(text, m)) {
+			continue
+		}
+		word := isLetter(m[0])
+		if (syn.wordStart || word) && i > lineStart && !strings.ContainsRune(" \t;@", rune(data[i-1])) {
+			continue
+		}
+		if word && i+len(m) < len(data) && !isSpace(data[i+len(m)]) {
+			continue
+		}
+		return len(m)
+	}
+	return 0
+}
+
+// line adds the text of the line comment with an n-byte marker at data[i:]
+// and returns the offset of the newline ending the line, or len(data).
+func (b *Builder) line(data []byte, i, n int) int {
+	lo := i + n
+	if !isLetter(data[i]) {
+		// Skip repeated markers, as in /// or ###.
+		for lo < len(data) && data[lo] == data[i] {
+			lo++
+		}
+	}
+	hi := len(data)
+	if j := bytes.IndexByte(data[lo:], '\n'); j >= 0 {
+		hi = lo + j
+	}
+	b.comment(data, lo, hi, 0)
+	b.Replace("\n", hi, min(hi+1, len(data)))
+	return hi
+}
+
+// block adds the text data[lo:hi] of a block comment
+// ending with a closing delimiter at data[hi:end].
+func (b *Builder) block(data []byte, lo, hi, end int, bullet byte) {
+	for {
+		j := bytes.IndexByte(data[lo:hi], '\n')
+		if j < 0 {
+			break
+		}
+		b.comment(data, lo, lo+j, bullet)
+		b.Replace("\n", lo+j, lo+j+1)
+		lo += j + 1
+	}
+	b.comment(data, lo, hi, bullet)
+	b.Replace("\n", hi, end)
+}
+
+// comment adds the comment text data[lo:hi], which is a single line,
+// trimming surrounding spaces and runs of bullet bytes.
+func (b *Builder) comment(data []byte, lo, hi int, bullet byte) {
+	for lo < hi && isSpace(data[lo]) {
+		lo++
+	}
+	for lo < hi && bullet != 0 && data[lo] == bullet {
+		lo++
+	}
+	for lo < hi && isSpace(data[hi-1]) {
+		hi--
+	}
+	for lo < hi && bullet != 0 && data[hi-1] == bullet {
+		hi--
+	}
+	for lo < hi && isSpace(data[lo]) {
+		lo++
+	}
+	for lo < hi && isSpace(data[hi-1]) {
+		hi--
+	}
+	b.Copy(data, lo, hi)
+}
+
+// skipString returns the offset just past the string starting at data[i].
+// A string with escapes ends at the end of the line if it is not closed.
+func skipString(data []byte, i int, escapes bool) int {
+	q := data[i]
+	for j := i + 1; j < len(data); j++ {
+		switch data[j] {
+		case q:
+			return j + 1
+		case '\\':
+			if escapes {
+				j++
+			}
+		case '\n':
+			if escapes {
+				return j
+			}
+		}
+	}
+	return len(data)
+}
+
+// isDocstringStart reports whether prefix, the text on a line before
+// a triple-quoted string, allows the string to be a docstring:
+// it must be only indentation and an optional string prefix.
+func isDocstringStart(prefix []byte) bool {
+	prefix = bytes.TrimLeft(prefix, " \t")
+	return len(prefix) <= 1 && (len(prefix) == 0 || strings.IndexByte("rRuU", prefix[0]) >= 0)
+}
+
+func isLetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == '\v'
+}
+
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,150 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var commentTests = []struct {
+	name string
+	in   string
+	out  string
+}{
+	{
+		"x.go",
+		"// Copyright 2020 Gopher.\n//\n// Use of this source code is governed.\n\npackage x // import \"x\"\n\nvar s = \"// not a comment\"\n",
+		"Copyright 2020 Gopher.\n\nUse of this source code is governed.\nimport \"x\"\n",
+	},
+	{
+		"x.c",
+		"/*\n * Copyright (c) 2020 Gopher\n *\n * Licensed under the MIT License.\n **************/\nint x = '\"'; /* one */ int y; /** two **/\n",
+		"\nCopyright (c) 2020 Gopher\n\nLicensed under the MIT License.\n\none\ntwo\n",
+	},
+	{
+		"x.js",
+		"const s = `\n// template\n`; // real\n",
+		"real\n",
+	},
+	{
+		"x.rs",
+		"/* outer /* inner */ still outer */ fn f<'a>() {} // done\n",
+		"outer /* inner */ still outer\ndone\n",
+	},
+	{
+		"x.py",
+		"#!/usr/bin/env python3\n# Copyright 2020 Gopher\n\"\"\"Module docstring.\n\nMore text.\n\"\"\"\nx = \"\"\"not # a docstring\"\"\"\ndef f():\n    r'''Function docstring.'''\n    return '#'  # comment\n",
+		"Copyright 2020 Gopher\nModule docstring.\n\nMore text.\n\nFunction docstring.\ncomment\n",
+	},
+	{
+		"run",
+		"#!/bin/sh\n### Licensed under X.\necho ${#x} $# # trailing\n",
+		"Licensed under X.\ntrailing\n",
+	},
+	{
+		"x.sql",
+		"-- Licensed\nSELECT '--' FROM t; /* block */\n",
+		"Licensed\nblock\n",
+	},
+	{
+		"x.lua",
+		"--[[\n  Licensed under MIT.\n]]\nlocal x = 1 -- one\n",
+		"\nLicensed under MIT.\n\none\n",
+	},
+	{
+		"x.el",
+		";;; x.el --- summary\n;; Copyright (C) 2020\n(setq x \"; no\")\n",
+		"x.el --- summary\nCopyright (C) 2020\n",
+	},
+	{
+		"INSTALL.BAT",
+		"@echo off\nREM Licensed under X.\n@rem second\nset REMOTE=1\n:: third\n",
+		"Licensed under X.\nsecond\nthird\n",
+	},
+	{
+		"configure.ac",
+		"dnl Licensed under X.\nAC_INIT([x]) dnl trailing\nAC_SUBST(dnlx)\n# hash\n",
+		"Licensed under X.\ntrailing\nhash\n",
+	},
+	{
+		"x.vbs",
+		"' Licensed under X.\nRem second\nMsgBox \"it's\" ' third\n",
+		"Licensed under X.\nsecond\nthird\n",
+	},
+	{
+		"x.tex",
+		"% Licensed under X.\n50\\% off % real\n",
+		"Licensed under X.\nreal\n",
+	},
+	{
+		"x.hs",
+		"{- |\n - Licensed under X.\n -}\nmain = pure () -- trailing\n",
+		"|\nLicensed under X.\n\ntrailing\n",
+	},
+	{
+		"Makefile",
+		"# Licensed under X.\nall:\n",
+		"Licensed under X.\n",
+	},
+	{
+		"x.go",
+		"/* unterminated\n * comment",
+		"unterminated\ncomment\n",
+	},
+}
+
+func TestComments(t *testing.T) {
+	for _, tt := range commentTests {
+		text, ok := Comments(tt.name, []byte(tt.in))
+		if !ok {
+			t.Errorf("Comments(%q, %q) = _, false, want true", tt.name, tt.in)
+			continue
+		}
+		if string(text.Data) != tt.out {
+			t.Errorf("Comments(%q, %q):\nhave %q\nwant %q", tt.name, tt.in, text.Data, tt.out)
+			continue
+		}
+		// Every word of the output must map back to the same word in the input.
+		for _, w := range strings.Fields(tt.out) {
+			i := bytes.Index(text.Data, []byte(w))
+			lo, hi := text.Start(i), text.End(i+len(w))
+			if got := tt.in[lo:hi]; got != w {
+				t.Errorf("Comments(%q, %q): word %q maps to [%d:%d] %q", tt.name, tt.in, w, lo, hi, got)
+			}
+		}
+	}
+}
+
+func TestCommentsUnknown(t *testing.T) {
+	for _, name := range []string{"LICENSE", "x.txt", "x.unknown"} {
+		if _, ok := Comments(name, []byte("# comment\n")); ok {
+			t.Errorf("Comments(%q) = _, true, want false", name)
+		}
+	}
+	if _, ok := Comments("script", []byte("#!/usr/bin/env -S perl5.30 -w\n# comment\n")); !ok {
+		t.Errorf("Comments with perl #! line = _, false, want true")
+	}
+}
+
+var interpreterTests = []struct {
+	line string
+	name string
+}{
+	{"#!/bin/sh", "sh"},
+	{"#! /usr/bin/python3.11 -u\nx", "python"},
+	{"#!/usr/bin/env node", "node"},
+	{"#!/usr/bin/env -S LANG=C ruby -w", "ruby"},
+	{"# not a #! line", ""},
+}
+
+func TestInterpreter(t *testing.T) {
+	for _, tt := range interpreterTests {
+		if name := interpreter([]byte(tt.line)); name != tt.name {
+			t.Errorf("interpreter(%q) = %q, want %q", tt.line, name, tt.name)
+		}
+	}
+}
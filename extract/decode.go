@@ -0,0 +1,260 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Character set decoding.
+
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Decode returns data transcoded to UTF-8.
+// It recognizes UTF-8, UTF-16, and UTF-32 text with a byte order mark,
+// UTF-16 and UTF-32 text without one, by the pattern of zero bytes
+// in mostly-ASCII text, and otherwise treats data that is not valid
+// UTF-8 as Windows-1252, a superset of the printable characters
+// of ISO 8859-1 (Latin-1) used for most 8-bit Western European text.
+// If data is valid UTF-8 without a byte order mark,
+// Decode returns nil, false: there is nothing to decode.
+func Decode(data []byte) (*Text, bool) {
+	charset := sniff(data)
+	if charset == "" {
+		return nil, false
+	}
+	return decoders[charset](data), true
+}
+
+// DecodeCharset returns data transcoded to UTF-8 from the named character set:
+// utf-8, utf-16, utf-16le, utf-16be, utf-32, utf-32le, utf-32be,
+// iso-8859-1 (or latin1), iso-8859-15 (or latin9), or windows-1252 (or cp1252).
+// Names are case-insensitive. A byte order mark at the start of data is removed.
+// For utf-16 and utf-32, it also selects the byte order;
+// without one, the byte order is big-endian.
+func DecodeCharset(data []byte, charset string) (*Text, error) {
+	name := strings.ReplaceAll(strings.ToLower(charset), "_", "-")
+	if alias, ok := charsetAliases[name]; ok {
+		name = alias
+	}
+	switch name {
+	case "utf-16":
+		name = "utf-16be"
+		if bytes.HasPrefix(data, bomUTF16LE) {
+			name = "utf-16le"
+		}
+	case "utf-32":
+		name = "utf-32be"
+		if bytes.HasPrefix(data, bomUTF32LE) {
+			name = "utf-32le"
+		}
+	}
+	dec := decoders[name]
+	if dec == nil {
+		return nil, fmt.Errorf("unknown charset %q", charset)
+	}
+	return dec(data), nil
+}
+
+var charsetAliases = map[string]string{
+	"utf8":       "utf-8",
+	"utf16":      "utf-16",
+	"utf16le":    "utf-16le",
+	"utf16be":    "utf-16be",
+	"utf32":      "utf-32",
+	"utf32le":    "utf-32le",
+	"utf32be":    "utf-32be",
+	"latin1":     "iso-8859-1",
+	"iso8859-1":  "iso-8859-1",
+	"latin9":     "iso-8859-15",
+	"iso8859-15": "iso-8859-15",
+	"cp1252":     "windows-1252",
+}
+
+var decoders = map[string]func([]byte) *Text{
+	"utf-8":        decodeUTF8,
+	"utf-16le":     func(data []byte) *Text { return decodeUTF16(data, binary.LittleEndian) },
+	"utf-16be":     func(data []byte) *Text { return decodeUTF16(data, binary.BigEndian) },
+	"utf-32le":     func(data []byte) *Text { return decodeUTF32(data, binary.LittleEndian) },
+	"utf-32be":     func(data []byte) *Text { return decodeUTF32(data, binary.BigEndian) },
+	"iso-8859-1":   func(data []byte) *Text { return decode8bit(data, nil) },
+	"iso-8859-15":  func(data []byte) *Text { return decode8bit(data, &latin9) },
+	"windows-1252": func(data []byte) *Text { return decode8bit(data, &cp1252) },
+}
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+	bomUTF32LE = []byte{0xFF, 0xFE, 0, 0}
+	bomUTF32BE = []byte{0, 0, 0xFE, 0xFF}
+)
+
+// sniffLen is the number of bytes examined when
+// guessing the encoding of text without a byte order mark.
+const sniffLen = 4096
+
+// sniff returns the name of the character set of data,
+// or "" for UTF-8 without a byte order mark.
+func sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return "utf-8"
+	case bytes.HasPrefix(data, bomUTF32LE):
+		return "utf-32le"
+	case bytes.HasPrefix(data, bomUTF32BE):
+		return "utf-32be"
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return "utf-16le"
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return "utf-16be"
+	}
+
+	// Mostly-ASCII text in UTF-32 or UTF-16 has zero bytes
+	// at the same position in most code units,
+	// while text in UTF-8 or an 8-bit encoding has none.
+	sample := data
+	if len(sample) > sniffLen {
+		sample = sample[:sniffLen]
+	}
+	var zero [4]int
+	for i, c := range sample {
+		if c == 0 {
+			zero[i%4]++
+		}
+	}
+	if n := len(sample) / 4; n > 0 {
+		switch {
+		case zero[2] > n*3/4 && zero[3] > n*3/4 && zero[0] < n/4:
+			return "utf-32le"
+		case zero[0] > n*3/4 && zero[1] > n*3/4 && zero[3] < n/4:
+			return "utf-32be"
+		}
+	}
+	if n := len(sample) / 2; n > 0 {
+		even, odd := zero[0]+zero[2], zero[1]+zero[3]
+		switch {
+		case odd > n/2 && even < n/20:
+			return "utf-16le"
+		case even > n/2 && odd < n/20:
+			return "utf-16be"
+		}
+	}
+
+	if utf8.Valid(data) {
+		return ""
+	}
+	return "windows-1252"
+}
+
+// decodeUTF8 returns data without its byte order mark, if any.
+func decodeUTF8(data []byte) *Text {
+	var b Builder
+	lo := 0
+	if bytes.HasPrefix(data, bomUTF8) {
+		lo = len(bomUTF8)
+	}
+	b.Copy(data, lo, len(data))
+	return b.Text()
+}
+
+// decodeUTF16 decodes UTF-16 text in the given byte order.
+func decodeUTF16(data []byte, order binary.ByteOrder) *Text {
+	var b Builder
+	unit := func(i int) rune {
+		return rune(order.Uint16(data[i:]))
+	}
+	i := 0
+	if len(data) >= 2 && unit(0) == 0xFEFF {
+		i = 2
+	}
+	for i+1 < len(data) {
+		r, n := unit(i), 2
+		if utf16.IsSurrogate(r) {
+			r = utf8.RuneError
+			if i+3 < len(data) {
+				if r2 := utf16.DecodeRune(unit(i), unit(i+2)); r2 != utf8.RuneError {
+					r, n = r2, 4
+				}
+			}
+		}
+		b.appendRune(r, i, n)
+		i += n
+	}
+	if i < len(data) {
+		b.appendRune(utf8.RuneError, i, len(data)-i)
+	}
+	return b.Text()
+}
+
+// decodeUTF32 decodes UTF-32 text in the given byte order.
+func decodeUTF32(data []byte, order binary.ByteOrder) *Text {
+	var b Builder
+	i := 0
+	if len(data) >= 4 && order.Uint32(data) == 0xFEFF {
+		i = 4
+	}
+	for ; i+3 < len(data); i += 4 {
+		r := rune(order.Uint32(data[i:]))
+		if !utf8.ValidRune(r) {
+			r = utf8.RuneError
+		}
+		b.appendRune(r, i, 4)
+	}
+	if i < len(data) {
+		b.appendRune(utf8.RuneError, i, len(data)-i)
+	}
+	return b.Text()
+}
+
+// decode8bit decodes text in an 8-bit encoding that agrees with
+// ISO 8859-1 except for the upper-half bytes listed in table.
+// A nil table means ISO 8859-1 itself.
+func decode8bit(data []byte, table *[128]rune) *Text {
+	var b Builder
+	lo := 0
+	for i, c := range data {
+		if c < utf8.RuneSelf {
+			continue
+		}
+		b.Copy(data, lo, i)
+		r := rune(c)
+		if table != nil && table[c-0x80] != 0 {
+			r = table[c-0x80]
+		}
+		b.appendRune(r, i, 1)
+		lo = i + 1
+	}
+	b.Copy(data, lo, len(data))
+	return b.Text()
+}
+
+// appendRune appends r, which stands for orig[lo:lo+n].
+func (b *Builder) appendRune(r rune, lo, n int) {
+	if r < utf8.RuneSelf {
+		b.t.Data = append(b.t.Data, byte(r))
+		b.add(1, lo, n)
+		return
+	}
+	b.Replace(string(r), lo, lo+n)
+}
+
+// cp1252 lists the Windows-1252 characters that differ from ISO 8859-1,
+// indexed by byte value minus 0x80.
+var cp1252 = [128]rune{
+	0x00: '€', 0x02: '‚', 0x03: 'ƒ', 0x04: '„', 0x05: '…', 0x06: '†', 0x07: '‡',
+	0x08: 'ˆ', 0x09: '‰', 0x0A: 'Š', 0x0B: '‹', 0x0C: 'Œ', 0x0E: 'Ž',
+	0x11: '‘', 0x12: '’', 0x13: '“', 0x14: '”', 0x15: '•', 0x16: '–', 0x17: '—',
+	0x18: '˜', 0x19: '™', 0x1A: 'š', 0x1B: '›', 0x1C: 'œ', 0x1E: 'ž', 0x1F: 'Ÿ',
+}
+
+// latin9 lists the ISO 8859-15 characters that differ from ISO 8859-1,
+// indexed by byte value minus 0x80.
+var latin9 = [128]rune{
+	0x24: '€', 0x26: 'Š', 0x28: 'š', 0x34: 'Ž', 0x38: 'ž', 0x3C: 'Œ', 0x3D: 'œ', 0x3E: 'Ÿ',
+}
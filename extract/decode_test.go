@@ -0,0 +1,160 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// encode returns s encoded in the named character set.
+func encode(s, charset string) []byte {
+	var buf bytes.Buffer
+	switch charset {
+	case "utf-16le", "utf-16be":
+		var order binary.ByteOrder = binary.LittleEndian
+		if charset == "utf-16be" {
+			order = binary.BigEndian
+		}
+		for _, u := range utf16.Encode([]rune(s)) {
+			binary.Write(&buf, order, u)
+		}
+	case "utf-32le", "utf-32be":
+		var order binary.ByteOrder = binary.LittleEndian
+		if charset == "utf-32be" {
+			order = binary.BigEndian
+		}
+		for _, r := range s {
+			binary.Write(&buf, order, uint32(r))
+		}
+	case "iso-8859-1":
+		for _, r := range s {
+			buf.WriteByte(byte(r))
+		}
+	default:
+		buf.WriteString(s)
+	}
+	return buf.Bytes()
+}
+
+const decodeText = "Copyright © 2020 José Núñez 𝄞\r\nPermission is hereby granted.\n"
+
+var decodeTests = []struct {
+	charset string
+	bom     string
+	sniff   string
+}{
+	{"utf-8", "", ""},
+	{"utf-8", "\ufeff", "utf-8"},
+	{"utf-16le", "", "utf-16le"},
+	{"utf-16le", "\ufeff", "utf-16le"},
+	{"utf-16be", "", "utf-16be"},
+	{"utf-16be", "\ufeff", "utf-16be"},
+	{"utf-32le", "", "utf-32le"},
+	{"utf-32le", "\ufeff", "utf-32le"},
+	{"utf-32be", "", "utf-32be"},
+	{"utf-32be", "\ufeff", "utf-32be"},
+}
+
+func TestDecode(t *testing.T) {
+	for _, tt := range decodeTests {
+		data := encode(tt.bom+decodeText, tt.charset)
+		if sniff := sniff(data); sniff != tt.sniff {
+			t.Errorf("sniff(%s, BOM=%v) = %q, want %q", tt.charset, tt.bom != "", sniff, tt.sniff)
+		}
+		text, ok := Decode(data)
+		if tt.sniff == "" {
+			if ok {
+				t.Errorf("Decode(%s) = %q, true, want nil, false", tt.charset, text.Data)
+			}
+			continue
+		}
+		if !ok || string(text.Data) != decodeText {
+			t.Errorf("Decode(%s, BOM=%v) = %q, %v, want %q, true", tt.charset, tt.bom != "", text.Data, ok, decodeText)
+			continue
+		}
+		checkWords(t, tt.charset, text, data, func(w string) []byte { return encode(w, tt.charset) })
+	}
+}
+
+// checkWords checks that each word in text.Data maps back to its encoding in orig.
+func checkWords(t *testing.T, name string, text *Text, orig []byte, enc func(string) []byte) {
+	t.Helper()
+	for _, w := range strings.Fields(string(text.Data)) {
+		i := bytes.Index(text.Data, []byte(w))
+		lo, hi := text.Start(i), text.End(i+len(w))
+		if got, want := orig[lo:hi], enc(w); !bytes.Equal(got, want) {
+			t.Errorf("%s: word %q maps to [%d:%d] %q, want %q", name, w, lo, hi, got, want)
+		}
+	}
+}
+
+func TestDecode8bit(t *testing.T) {
+	data := encode("Copyright © 2020 José Núñez", "iso-8859-1")
+	data = append(data, " \x93quoted\x94 \x80"...)
+	text, ok := Decode(data)
+	if want := "Copyright © 2020 José Núñez “quoted” €"; !ok || string(text.Data) != want {
+		t.Fatalf("Decode(Latin-1) = %q, %v, want %q, true", text.Data, ok, want)
+	}
+	checkWords(t, "windows-1252", text, data, func(w string) []byte {
+		var enc []byte
+		for _, r := range w {
+			for i, c := range cp1252 {
+				if c == r {
+					r = rune(0x80 + i)
+				}
+			}
+			enc = append(enc, byte(r))
+		}
+		return enc
+	})
+
+	text, err := DecodeCharset(data, "ISO_8859-1")
+	if want := "Copyright © 2020 José Núñez \u0093quoted\u0094 \u0080"; err != nil || string(text.Data) != want {
+		t.Errorf("DecodeCharset(ISO_8859-1) = %q, %v, want %q, nil", text.Data, err, want)
+	}
+	text, err = DecodeCharset([]byte("\xa4 \xbd"), "latin9")
+	if want := "€ œ"; err != nil || string(text.Data) != want {
+		t.Errorf("DecodeCharset(latin9) = %q, %v, want %q, nil", text.Data, err, want)
+	}
+}
+
+func TestDecodeCharset(t *testing.T) {
+	for _, tt := range decodeTests {
+		data := encode(tt.bom+decodeText, tt.charset)
+		charset := strings.ToUpper(tt.charset)
+		if tt.bom != "" {
+			charset = strings.TrimSuffix(strings.TrimSuffix(charset, "LE"), "BE")
+		}
+		text, err := DecodeCharset(data, charset)
+		if err != nil || string(text.Data) != decodeText {
+			t.Errorf("DecodeCharset(%s, BOM=%v) = %q, %v, want %q, nil", charset, tt.bom != "", text.Data, err, decodeText)
+		}
+	}
+	if _, err := DecodeCharset(nil, "ebcdic"); err == nil || !strings.Contains(err.Error(), "unknown charset") {
+		t.Errorf("DecodeCharset(ebcdic) = _, %v, want unknown charset error", err)
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	tests := []struct {
+		charset string
+		data    string
+		out     string
+	}{
+		{"utf-16le", "a\x00\x00\xd8b\x00", "a�b"}, // unpaired surrogate
+		{"utf-16le", "a\x00b", "a�"},              // odd length
+		{"utf-32be", "\x00\x11\x00\x00\x00\x00\x00a", "�a"},
+	}
+	for _, tt := range tests {
+		text, err := DecodeCharset([]byte(tt.data), tt.charset)
+		if err != nil || string(text.Data) != tt.out {
+			t.Errorf("DecodeCharset(%q, %s) = %q, %v, want %q, nil", tt.data, tt.charset, text.Data, err, tt.out)
+		}
+	}
+}
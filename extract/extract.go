@@ -0,0 +1,138 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package extract extracts the text to scan for licenses
+// from files in which license text is embedded in other syntax,
+// such as the comments in a source file or a document format such as RTF,
+// or encoded in a character set other than UTF-8.
+//
+// Each extractor returns a Text holding the extracted text
+// and the information needed to map byte offsets in the
+// extracted text, such as the Start and End of a licensecheck.Match,
+// back to byte offsets in the original file.
+//
+// Office reads word processing documents, which are compressed archives,
+// so that offsets in the original file are meaningless; it returns
+// a Document, which maps offsets in its text to paragraph and character
+// indexes instead.
+package extract
+
+import "sort"
+
+// A Text is text extracted from an original file.
+type Text struct {
+	Data  []byte // the extracted text
+	spans []span // spans of Data, in order, with their sources in the original
+}
+
+// A span records that Data[outLo:outHi] came from original[inLo:inHi].
+// If step is positive, each byte of Data[outLo:outHi] stands for
+// step bytes of the original: step is 1 for copied text and,
+// for example, 2 for ASCII characters decoded from UTF-16.
+// If step is 0, Data[outLo:outHi] replaces original[inLo:inHi] as a unit.
+type span struct {
+	outLo, outHi int
+	inLo, inHi   int
+	step         int
+}
+
+// Start returns the offset in the original file corresponding
+// to the start of the text at offset off in t.Data.
+// An offset inside text that replaced a sequence of original bytes,
+// such as a decoded character or a line break standing for a comment
+// marker, maps to the start of that sequence.
+func (t *Text) Start(off int) int {
+	i := sort.Search(len(t.spans), func(i int) bool { return t.spans[i].outHi > off })
+	if i == len(t.spans) {
+		if i == 0 {
+			return 0
+		}
+		return t.spans[i-1].inHi
+	}
+	s := t.spans[i]
+	if s.step > 0 {
+		return s.inLo + (off-s.outLo)*s.step
+	}
+	return s.inLo
+}
+
+// End returns the offset in the original file corresponding
+// to the end of the text ending at offset off in t.Data.
+// An offset inside text that replaced a sequence of original bytes
+// maps to the end of that sequence.
+func (t *Text) End(off int) int {
+	i := sort.Search(len(t.spans), func(i int) bool { return t.spans[i].outHi >= off })
+	if i == len(t.spans) {
+		if i == 0 {
+			return 0
+		}
+		return t.spans[i-1].inHi
+	}
+	s := t.spans[i]
+	if off <= s.outLo {
+		if i == 0 {
+			return s.inLo
+		}
+		return t.spans[i-1].inHi
+	}
+	if s.step > 0 {
+		return s.inLo + (off-s.outLo)*s.step
+	}
+	return s.inHi
+}
+
+// A Builder builds a Text from pieces of an original file.
+// The pieces must be added in order of their original offsets.
+// The zero Builder is an empty text ready to use.
+type Builder struct {
+	t Text
+}
+
+// Copy appends the original bytes orig[lo:hi] to the text.
+func (b *Builder) Copy(orig []byte, lo, hi int) {
+	if lo == hi {
+		return
+	}
+	b.t.Data = append(b.t.Data, orig[lo:hi]...)
+	b.add(hi-lo, lo, 1)
+}
+
+// add records that the last n bytes of the text stand for
+// the n*step original bytes starting at lo.
+func (b *Builder) add(n, lo, step int) {
+	t := &b.t
+	hi := lo + n*step
+	if k := len(t.spans); k > 0 {
+		if last := &t.spans[k-1]; last.step == step && last.inHi == lo {
+			last.outHi += n
+			last.inHi = hi
+			return
+		}
+	}
+	t.spans = append(t.spans, span{len(t.Data) - n, len(t.Data), lo, hi, step})
+}
+
+// Replace appends text to the text, standing for the original bytes orig[lo:hi].
+// Either may be empty: Replace("", lo, hi) records that orig[lo:hi] was dropped,
+// and Replace("\n", lo, lo) inserts a line break not present in the original.
+func (b *Builder) Replace(text string, lo, hi int) {
+	if text == "" {
+		return
+	}
+	t := &b.t
+	out := len(t.Data)
+	t.Data = append(t.Data, text...)
+	t.spans = append(t.spans, span{out, len(t.Data), lo, hi, 0})
+}
+
+// Len returns the length of the text built so far.
+func (b *Builder) Len() int {
+	return len(b.t.Data)
+}
+
+// Text returns the text built so far.
+// The Builder must not be used after calling Text.
+func (b *Builder) Text() *Text {
+	return &b.t
+}
@@ -0,0 +1,55 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package extract
+
+import "testing"
+
+func TestBuilder(t *testing.T) {
+	orig := []byte("<<hello>> [world]")
+	var b Builder
+	b.Copy(orig, 2, 4) // "he"
+	b.Copy(orig, 4, 7) // "llo", merged with "he"
+	b.Replace(" ", 7, 11)
+	b.Copy(orig, 11, 16) // "world"
+	b.Replace("\n", 17, 17)
+	text := b.Text()
+	if string(text.Data) != "hello world\n" {
+		t.Fatalf("Data = %q, want %q", text.Data, "hello world\n")
+	}
+	if len(text.spans) != 4 {
+		t.Errorf("len(spans) = %d, want 4", len(text.spans))
+	}
+
+	tests := []struct {
+		off        int
+		start, end int
+	}{
+		{0, 2, 2},
+		{3, 5, 5},
+		{5, 7, 7},
+		{6, 11, 11},
+		{8, 13, 13},
+		{11, 17, 16},
+		{12, 17, 17},
+	}
+	for _, tt := range tests {
+		if start := text.Start(tt.off); start != tt.start {
+			t.Errorf("Start(%d) = %d, want %d", tt.off, start, tt.start)
+		}
+		if end := text.End(tt.off); end != tt.end {
+			t.Errorf("End(%d) = %d, want %d", tt.off, end, tt.end)
+		}
+	}
+
+	// A replacement covers its whole original span.
+	if start, end := text.Start(5), text.End(6); start != 7 || end != 11 {
+		t.Errorf("Start(5), End(6) = %d, %d, want 7, 11", start, end)
+	}
+
+	var empty Builder
+	if text := empty.Text(); text.Start(0) != 0 || text.End(0) != 0 {
+		t.Errorf("empty Text: Start(0), End(0) = %d, %d, want 0, 0", text.Start(0), text.End(0))
+	}
+}
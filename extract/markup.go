@@ -0,0 +1,113 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Markup stripping.
+
+package extract
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"sync"
+)
+
+// A Markup describes a markup language whose documents StripMarkup
+// can reduce to plain text.
+type Markup struct {
+	// Name is the name of the markup language, such as "rtf".
+	Name string
+
+	// Match reports whether the file with the given name and contents
+	// is written in the markup language.
+	Match func(name string, data []byte) bool
+
+	// Strip returns the text of the document, without markup.
+	// Control words, macros, and other markup that do not stand for text
+	// should be removed entirely, and paragraph breaks turned into
+	// blank lines, so that the markup neither breaks license matches
+	// nor counts as unmatched words.
+	Strip func(data []byte) *Text
+}
+
+var (
+	markupMu sync.Mutex
+	markups  = []*Markup{
+		{"rtf", isRTF, stripRTF},
+		{"troff", isTroff, stripTroff},
+		{"texinfo", isTexinfo, stripTexinfo},
+		{"rst", isRST, stripRST},
+	}
+)
+
+// RegisterMarkup adds m to the markup languages recognized by StripMarkup.
+// Languages registered later take precedence over those registered earlier,
+// which take precedence over the built-in ones.
+func RegisterMarkup(m *Markup) {
+	markupMu.Lock()
+	defer markupMu.Unlock()
+	markups = append([]*Markup{m}, markups...)
+}
+
+// StripMarkup returns the text of the document with the given name
+// and contents with its markup removed.
+// The markup language is chosen by the first registered Markup
+// whose Match function accepts the document.
+// The built-in languages are:
+//
+//	rtf      Rich Text Format, as used in Windows installers ({\rtf1 ...})
+//	troff    troff, man, and mdoc manual pages (*.1 to *.9, *.man, *.roff, .TH)
+//	texinfo  Texinfo (*.texi, *.texinfo, *.txi, \input texinfo)
+//	rst      reStructuredText (*.rst, *.rest)
+//
+// If no Markup accepts the document, StripMarkup returns nil, false.
+func StripMarkup(name string, data []byte) (*Text, bool) {
+	markupMu.Lock()
+	list := markups
+	markupMu.Unlock()
+	for _, m := range list {
+		if m.Match(name, data) {
+			return m.Strip(data), true
+		}
+	}
+	return nil, false
+}
+
+// ext returns the lower-case extension of the file name.
+func ext(name string) string {
+	return strings.ToLower(path.Ext(strings.ReplaceAll(name, `\`, "/")))
+}
+
+// A lineReader iterates over the lines of a document.
+type lineReader struct {
+	data   []byte
+	lo, hi int // current line, without its newline
+	next   int // start of next line
+}
+
+// scan advances to the next line, reporting whether there is one.
+func (r *lineReader) scan() bool {
+	if r.next >= len(r.data) {
+		return false
+	}
+	r.lo = r.next
+	if i := bytes.IndexByte(r.data[r.lo:], '\n'); i >= 0 {
+		r.hi = r.lo + i
+		r.next = r.hi + 1
+	} else {
+		r.hi = len(r.data)
+		r.next = r.hi
+	}
+	return true
+}
+
+// line returns the current line, without its newline.
+func (r *lineReader) line() []byte {
+	return r.data[r.lo:r.hi]
+}
+
+// newline adds a newline standing for the end of the current line to b.
+func (r *lineReader) newline(b *Builder) {
+	b.Replace("\n", r.hi, r.next)
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+var markupTests = []struct {
+	name string
+	in   string
+	out  string
+}{
+	{
+		"LICENSE.rtf",
+		`{\rtf1\ansi\ansicpg1252\deff0{\fonttbl{\f0\fnil Arial;}}{\colortbl;\red0\green0\blue0;}` +
+			`{\*\generator Riched20 10.0;}\viewkind4\uc1` + "\r\n" +
+			`\pard\b Copyright \'a9 2020 Jos\'e9\b0\par` + "\r\n" +
+			`Permission is \i hereby\i0  granted\emdash see \ldblquote LICENSE\rdblquote .\par` + "\r\n" +
+			`Unicode \u8364? euro, braces \{ \} and \\.\par` + "\r\n" +
+			`{\pict\wmetafile8 0102ab}}`,
+		"Copyright © 2020 José\nPermission is hereby granted—see “LICENSE”.\nUnicode € euro, braces { } and \\.\n",
+	},
+	{
+		"huge.rtf",
+		`{\rtf1 hello \bin9223372036854775807 x}`,
+		"hello ",
+	},
+	{
+		"ls.1",
+		`.\" Copyright comment, not text` + "\n" +
+			`.TH LS 1 2020-01-01 "GNU" "User Commands"` + "\n" +
+			`.SH NAME` + "\n" +
+			`ls \- list directory contents` + "\n" +
+			`.SH COPYRIGHT` + "\n" +
+			`Copyright \(co 2020 Free Software Foundation, Inc.` + "\n" +
+			`License GPLv3+: \fBGNU GPL version 3\fR or later \" trailing comment` + "\n" +
+			`.B "free software"` + "\n" +
+			`.de XX` + "\n" +
+			`macro body` + "\n" +
+			`..` + "\n" +
+			`.PP` + "\n" +
+			`There is \s-1NO WARRANTY\s0, to the \*(lqextent\*(rq permitted.` + "\n",
+		"\nNAME\nls - list directory contents\nCOPYRIGHT\nCopyright © 2020 Free Software Foundation, Inc.\nLicense GPLv3+: GNU GPL version 3 or later \nfree software\n\nThere is NO WARRANTY, to the “extent” permitted.\n",
+	},
+	{
+		"x.3pm",
+		".Sh LICENSE\n.Nm foo\nis \\[u00A9] me.\n.Op Fl v\n",
+		"LICENSE\nfoo\nis © me.\n v\n",
+	},
+	{
+		"doc.texi",
+		`\input texinfo` + "\n" +
+			`@setfilename doc.info` + "\n" +
+			`@c comment line` + "\n" +
+			`@copying` + "\n" +
+			`Copyright @copyright{} 2020 @emph{Free} Software Foundation@comma{} Inc.` + "\n" +
+			`Permission is granted@dots{} see @uref{https://www.gnu.org/licenses/}.@footnote{Really.}` + "\n" +
+			`@end copying` + "\n" +
+			`@ignore` + "\n" +
+			`hidden` + "\n" +
+			`@end ignore` + "\n" +
+			`@chapter Caf@'e @@ home@anchor{Top}` + "\n",
+		"\n\nCopyright © 2020 Free Software Foundation, Inc.\nPermission is granted… see https://www.gnu.org/licenses/.Really.\n\nCafe @ home\n",
+	},
+	{
+		"README.rst",
+		"=======\nLicense\n=======\n\n" +
+			".. This is a comment\n   spanning two lines.\n\n" +
+			".. image:: logo.png\n   :alt: Logo\n\n" +
+			".. note:: Read this.\n   :class: x\n\n   Really.\n\n" +
+			"Licensed under the `MIT License <https://opensource.org/licenses/MIT>`_ [1]_.\n" +
+			"See :doc:`COPYING` and ``code``, escaped \\*.\n" +
+			"Example::\n\n    literal text\n\n" +
+			".. _MIT: https://opensource.org/licenses/MIT\n" +
+			"+---+---+\n",
+		"\nLicense\n\n\n\n\n Read this.\n\nReally.\n\nLicensed under the `MIT License`_ .\nSee `COPYING` and ``code``, escaped *.\nExample:\n\nliteral text\n\nhttps://opensource.org/licenses/MIT\n\n",
+	},
+}
+
+func TestStripMarkup(t *testing.T) {
+	for _, tt := range markupTests {
+		text, ok := StripMarkup(tt.name, []byte(tt.in))
+		if !ok {
+			t.Errorf("StripMarkup(%q) = _, false, want true", tt.name)
+			continue
+		}
+		if string(text.Data) != tt.out {
+			t.Errorf("StripMarkup(%q):\nhave %q\nwant %q", tt.name, text.Data, tt.out)
+			continue
+		}
+		// Words copied from the input must map back to themselves.
+		for _, w := range strings.Fields(tt.out) {
+			w = strings.TrimFunc(w, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			if w == "" || !strings.Contains(tt.in, w) {
+				continue
+			}
+			i := bytes.Index(text.Data, []byte(w))
+			lo, hi := text.Start(i), text.End(i+len(w))
+			if got := tt.in[lo:hi]; got != w {
+				t.Errorf("StripMarkup(%q): word %q maps to [%d:%d] %q", tt.name, w, lo, hi, got)
+			}
+		}
+	}
+}
+
+func TestMarkupDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		ok   bool
+	}{
+		{"EULA", `{\rtf1\ansi hello}`, true},
+		{"cmd.8", "hello\n", true},
+		{"cmd", ".\\\" comment\n.TH CMD 1\n", true},
+		{"cmd", ".Dd May 1, 2020\n", true},
+		{"notes", "\\input texinfo\n", true},
+		{"x.rest", "hello\n", true},
+		{"LICENSE", "hello\n", false},
+		{"x.10", "hello\n", false},
+		{"x.go", "package x\n", false},
+	}
+	for _, tt := range tests {
+		if _, ok := StripMarkup(tt.name, []byte(tt.data)); ok != tt.ok {
+			t.Errorf("StripMarkup(%q, %q) = _, %v, want %v", tt.name, tt.data, ok, tt.ok)
+		}
+	}
+}
+
+func TestRegisterMarkup(t *testing.T) {
+	defer func(saved []*Markup) { markups = saved }(markups)
+
+	RegisterMarkup(&Markup{
+		Name:  "shout",
+		Match: func(name string, data []byte) bool { return strings.HasSuffix(name, ".shout") },
+		Strip: func(data []byte) *Text {
+			var b Builder
+			b.Copy(data, 0, len(data))
+			return b.Text()
+		},
+	})
+	text, ok := StripMarkup("x.shout", []byte("HELLO"))
+	if !ok || string(text.Data) != "HELLO" {
+		t.Errorf("StripMarkup(x.shout) = %q, %v, want %q, true", text.Data, ok, "HELLO")
+	}
+	if _, ok := StripMarkup("x.rst", []byte("hello")); !ok {
+		t.Errorf("StripMarkup(x.rst) after RegisterMarkup = _, false, want true")
+	}
+}
@@ -0,0 +1,282 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Office documents.
+
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A Document is the text of a word processing document,
+// as a list of paragraphs.
+type Document struct {
+	// Paragraphs lists the text of each paragraph, including empty ones,
+	// in document order, except that paragraphs nested in another,
+	// such as those of a text box or footnote, come before it.
+	// A paragraph contains no newlines: line breaks within a paragraph
+	// are read as spaces.
+	Paragraphs []string
+}
+
+// A Position is a position in a Document.
+// Both indexes are 0-based.
+type Position struct {
+	Paragraph int // index in Paragraphs
+	Char      int // index of the character (Unicode code point) in the paragraph
+}
+
+// Text returns the text of the document,
+// with each paragraph followed by a newline.
+func (d *Document) Text() []byte {
+	var buf bytes.Buffer
+	for _, p := range d.Paragraphs {
+		buf.WriteString(p)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// Position returns the position of the byte offset off in d.Text().
+// The newline after a paragraph is at the position just past
+// the paragraph's last character.
+// Offsets outside the text are clamped to its start or end.
+func (d *Document) Position(off int) Position {
+	if off < 0 {
+		off = 0
+	}
+	start := 0
+	for i, p := range d.Paragraphs {
+		if off <= start+len(p) {
+			return Position{i, utf8.RuneCountInString(p[:off-start])}
+		}
+		start += len(p) + 1
+	}
+	if n := len(d.Paragraphs); n > 0 {
+		return Position{n - 1, utf8.RuneCountInString(d.Paragraphs[n-1])}
+	}
+	return Position{}
+}
+
+// IsOffice reports whether the file name has the extension
+// of a document that Office can read: .docx, .docm, .dotx, .dotm,
+// .odt, or .ott.
+func IsOffice(name string) bool {
+	switch strings.ToLower(ext(name)) {
+	case ".docx", ".docm", ".dotx", ".dotm", ".odt", ".ott":
+		return true
+	}
+	return false
+}
+
+// maxOfficeXML is the maximum uncompressed size of the document part
+// read by Office, to guard against zip bombs.
+const maxOfficeXML = 64 << 20
+
+// Office returns the text of the word processing document data,
+// which must be an Office Open XML document (.docx), with its text
+// in word/document.xml, or an OpenDocument text (.odt), with its text
+// in content.xml. Deleted revisions, comments, and field codes are
+// not part of the text.
+func Office(data []byte) (*Document, error) {
+	z, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	parts := make(map[string]*zip.File)
+	for _, f := range z.File {
+		parts[f.Name] = f
+	}
+	if f := parts["word/document.xml"]; f != nil {
+		return officeParagraphs(f, docxSyntax)
+	}
+	if f := parts["content.xml"]; f != nil {
+		return officeParagraphs(f, odtSyntax)
+	}
+	return nil, errors.New("not a DOCX or ODT document")
+}
+
+// An officeSyntax describes the XML elements of a document format.
+type officeSyntax struct {
+	ns       string            // namespace of text elements
+	para     map[string]bool   // paragraph elements
+	text     map[string]bool   // elements holding text, or nil for all
+	chars    map[string]string // empty elements that stand for text
+	space    string            // element that stands for spaces, with a count attribute c
+	skip     map[xml.Name]bool // elements whose contents are not text
+	collapse bool              // collapse runs of white space in text
+}
+
+var docxSyntax = &officeSyntax{
+	ns:   wordNS,
+	para: map[string]bool{"p": true},
+	text: map[string]bool{"t": true},
+	chars: map[string]string{
+		"tab":           "\t",
+		"ptab":          "\t",
+		"br":            " ",
+		"cr":            " ",
+		"noBreakHyphen": "-",
+	},
+	skip: map[xml.Name]bool{
+		{Space: wordNS, Local: "pPr"}:      true, // paragraph properties, including tab stops
+		{Space: wordNS, Local: "rPr"}:      true,
+		{Space: wordNS, Local: "del"}:      true,
+		{Space: wordNS, Local: "moveFrom"}: true,
+	},
+}
+
+var odtSyntax = &officeSyntax{
+	ns:   odfTextNS,
+	para: map[string]bool{"p": true, "h": true},
+	chars: map[string]string{
+		"tab":        "\t",
+		"line-break": " ",
+	},
+	space: "s",
+	skip: map[xml.Name]bool{
+		{Space: odfTextNS, Local: "tracked-changes"}:  true,
+		{Space: odfTextNS, Local: "note-citation"}:    true,
+		{Space: odfOfficeNS, Local: "annotation"}:     true,
+		{Space: odfOfficeNS, Local: "annotation-end"}: true,
+	},
+	collapse: true,
+}
+
+const (
+	wordNS      = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	odfTextNS   = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	odfOfficeNS = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+)
+
+// officeParagraphs returns the paragraphs in the XML document f
+// written in the given syntax.
+func officeParagraphs(f *zip.File, syntax *officeSyntax) (*Document, error) {
+	if f.UncompressedSize64 > maxOfficeXML {
+		return nil, fmt.Errorf("%s too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(io.LimitReader(rc, maxOfficeXML+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxOfficeXML {
+		return nil, fmt.Errorf("%s too large", f.Name)
+	}
+
+	doc := new(Document)
+	var stack []*strings.Builder // paragraphs being read, innermost last
+	inText := 0                  // depth of text elements
+	skip := 0                    // depth of skipped elements
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if skip > 0 || syntax.skip[tok.Name] {
+				skip++
+				continue
+			}
+			if tok.Name.Space != syntax.ns {
+				continue
+			}
+			name := tok.Name.Local
+			switch {
+			case syntax.para[name]:
+				stack = append(stack, new(strings.Builder))
+			case syntax.text[name]:
+				inText++
+			case len(stack) == 0:
+				// not in a paragraph
+			case name == syntax.space:
+				n := 1
+				for _, a := range tok.Attr {
+					if a.Name.Local == "c" {
+						if c, err := strconv.Atoi(a.Value); err == nil && c >= 0 && c <= 1000 {
+							n = c
+						}
+					}
+				}
+				stack[len(stack)-1].WriteString(strings.Repeat(" ", n))
+			default:
+				if s, ok := syntax.chars[name]; ok {
+					stack[len(stack)-1].WriteString(s)
+				}
+			}
+
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			if tok.Name.Space != syntax.ns {
+				continue
+			}
+			switch name := tok.Name.Local; {
+			case syntax.para[name] && len(stack) > 0:
+				p := stack[len(stack)-1].String()
+				stack = stack[:len(stack)-1]
+				doc.Paragraphs = append(doc.Paragraphs, p)
+			case syntax.text[name] && inText > 0:
+				inText--
+			}
+
+		case xml.CharData:
+			if skip > 0 || len(stack) == 0 || syntax.text != nil && inText == 0 {
+				continue
+			}
+			p := stack[len(stack)-1]
+			if syntax.collapse {
+				tok = collapseSpace(tok, p.Len() == 0 || strings.HasSuffix(p.String(), " "))
+			}
+			p.Write(bytes.Map(func(r rune) rune {
+				if r == '\n' || r == '\r' {
+					return ' '
+				}
+				return r
+			}, tok))
+		}
+	}
+	return doc, nil
+}
+
+// collapseSpace returns text with each run of white space
+// replaced by a single space, as in OpenDocument paragraphs.
+// If trim is true, leading white space is removed.
+func collapseSpace(text []byte, trim bool) []byte {
+	var out []byte
+	space := trim
+	for _, c := range text {
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			if !space {
+				out = append(out, ' ')
+			}
+			space = true
+			continue
+		}
+		out = append(out, c)
+		space = false
+	}
+	return out
+}
@@ -0,0 +1,120 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package extract
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/google/licensecheck/internal/archivetest"
+)
+
+const docxXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main">
+<w:body>
+<w:p><w:pPr><w:tabs><w:tab w:val="left" w:pos="720"/></w:tabs></w:pPr>
+<w:r><w:rPr><w:b/></w:rPr><w:t>END USER </w:t></w:r><w:r><w:t>LICENSE</w:t></w:r></w:p>
+<w:p/>
+<w:p><w:r><w:t xml:space="preserve">Copyright © 2020 </w:t></w:r><w:del w:id="1"><w:r><w:delText>Old</w:delText></w:r></w:del><w:ins w:id="2"><w:r><w:t>Acme</w:t></w:r></w:ins><w:r><w:tab/><w:t>Inc.</w:t><w:br/><w:t>All rights reserved.</w:t></w:r></w:p>
+<w:p><w:r><w:fldChar w:fldCharType="begin"/></w:r><w:r><w:instrText>HYPERLINK "x"</w:instrText></w:r><w:r><w:t>link</w:t></w:r><w:r><w:t>&amp;</w:t><w:noBreakHyphen/><w:t>text</w:t></w:r></w:p>
+</w:body>
+</w:document>`
+
+const odtXML = `<?xml version="1.0" encoding="UTF-8"?>
+<office:document-content xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0">
+<office:body><office:text>
+<text:tracked-changes><text:changed-region><text:deletion><text:p>deleted</text:p></text:deletion></text:changed-region></text:tracked-changes>
+<text:h text:outline-level="1">License</text:h>
+<text:p>  Copyright   2020
+  <text:span>Acme</text:span>,<text:s text:c="3"/>Inc.<text:tab/>All<text:line-break/>rights<office:annotation><text:p>a comment</text:p></office:annotation> reserved.</text:p>
+<text:p>See<text:note><text:note-citation>1</text:note-citation><text:note-body><text:p>The note.</text:p></text:note-body></text:note> below.</text:p>
+</office:text></office:body>
+</office:document-content>`
+
+var officeTests = []struct {
+	name  string
+	files []string
+	paras []string
+}{
+	{
+		"EULA.docx",
+		[]string{"[Content_Types].xml", "<Types/>", "word/document.xml", docxXML},
+		[]string{
+			"END USER LICENSE",
+			"",
+			"Copyright © 2020 Acme\tInc. All rights reserved.",
+			"link&-text",
+		},
+	},
+	{
+		"EULA.odt",
+		[]string{"mimetype", "application/vnd.oasis.opendocument.text", "content.xml", odtXML},
+		[]string{
+			"License",
+			"Copyright 2020 Acme,   Inc.\tAll rights reserved.",
+			"The note.",
+			"See below.",
+		},
+	},
+}
+
+func TestOffice(t *testing.T) {
+	for _, tt := range officeTests {
+		doc, err := Office(archivetest.Zip(t, tt.files...))
+		if err != nil {
+			t.Errorf("Office(%s): %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(doc.Paragraphs, tt.paras) {
+			t.Errorf("Office(%s):\nhave %q\nwant %q", tt.name, doc.Paragraphs, tt.paras)
+		}
+		if !IsOffice(tt.name) {
+			t.Errorf("IsOffice(%q) = false, want true", tt.name)
+		}
+	}
+}
+
+func TestOfficeInvalid(t *testing.T) {
+	if _, err := Office([]byte("not a zip file")); err == nil {
+		t.Errorf("Office(text) succeeded, want error")
+	}
+	if _, err := Office(archivetest.Zip(t, "README", "hello")); err == nil {
+		t.Errorf("Office(zip without document) succeeded, want error")
+	}
+	if _, err := Office(archivetest.Zip(t, "word/document.xml", "<w:document><w:p>")); err == nil {
+		t.Errorf("Office(truncated XML) succeeded, want error")
+	}
+	if IsOffice("LICENSE.txt") {
+		t.Errorf("IsOffice(LICENSE.txt) = true, want false")
+	}
+}
+
+func TestDocumentPosition(t *testing.T) {
+	doc := &Document{Paragraphs: []string{"Título", "", "ab"}}
+	text := doc.Text()
+	if string(text) != "Título\n\nab\n" {
+		t.Fatalf("Text() = %q, want %q", text, "Título\n\nab\n")
+	}
+	tests := []struct {
+		off int
+		pos Position
+	}{
+		{-1, Position{0, 0}},
+		{0, Position{0, 0}},
+		{3, Position{0, 2}},
+		{5, Position{0, 4}}, // after the two-byte í
+		{7, Position{0, 6}},
+		{8, Position{1, 0}},
+		{9, Position{2, 0}},
+		{11, Position{2, 2}},
+		{12, Position{2, 2}},
+		{100, Position{2, 2}},
+	}
+	for _, tt := range tests {
+		if pos := doc.Position(tt.off); pos != tt.pos {
+			t.Errorf("Position(%d) = %v, want %v", tt.off, pos, tt.pos)
+		}
+	}
+}
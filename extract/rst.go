@@ -0,0 +1,207 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// reStructuredText.
+
+package extract
+
+import (
+	"bytes"
+	"strings"
+)
+
+func isRST(name string, data []byte) bool {
+	switch ext(name) {
+	case ".rst", ".rest":
+		return true
+	}
+	return false
+}
+
+// rstTextDirectives lists the directives whose contents are text.
+// The contents of other directives, such as images and code,
+// are removed, as are comments.
+var rstTextDirectives = map[string]bool{
+	"admonition": true, "attention": true, "caution": true, "compound": true,
+	"container": true, "danger": true, "epigraph": true, "error": true,
+	"highlights": true, "hint": true, "important": true, "line-block": true,
+	"note": true, "parsed-literal": true, "pull-quote": true, "rubric": true,
+	"sidebar": true, "tip": true, "topic": true, "warning": true,
+}
+
+// stripRST returns the text of the reStructuredText document data.
+func stripRST(data []byte) *Text {
+	var b Builder
+	r := lineReader{data: data}
+	skip := -1       // indentation of markup whose indented block is being skipped, or -1
+	options := false // in the field list of options after a directive
+	for r.scan() {
+		line := r.line()
+		indent := 0
+		for indent < len(line) && (line[indent] == ' ' || line[indent] == '\t') {
+			indent++
+		}
+		text := bytes.TrimRight(line[indent:], " \t\r")
+		if skip >= 0 {
+			if len(text) == 0 || indent > skip {
+				continue
+			}
+			skip = -1
+		}
+		if options {
+			if len(text) > 0 && text[0] == ':' {
+				continue
+			}
+			options = false
+		}
+
+		lo := r.lo + indent
+		switch {
+		case bytes.HasPrefix(text, []byte("..")) && (len(text) == 2 || text[2] == ' '):
+			// Explicit markup: directive, target, footnote, or comment.
+			rest := text[min(3, len(text)):]
+			lo += min(3, len(text))
+			switch {
+			case len(rest) > 0 && rest[0] == '_':
+				// Hyperlink target: .. _name: URL. Keep the URL.
+				if i := bytes.Index(rest, []byte(": ")); i >= 0 {
+					rstText(&b, data, lo+i+2, lo+len(rest))
+				}
+			case len(rest) > 0 && rest[0] == '[':
+				// Footnote or citation: .. [1] text.
+				if i := bytes.IndexByte(rest, ']'); i >= 0 {
+					rstText(&b, data, lo+i+1, lo+len(rest))
+				}
+			default:
+				if name, end, ok := rstDirective(rest); ok && rstTextDirectives[name] {
+					rstText(&b, data, lo+end, lo+len(rest))
+					options = true
+					break
+				}
+				skip = indent
+			}
+
+		case isRSTAdornment(text):
+			// Section title underline or overline, transition, or table border.
+
+		default:
+			rstText(&b, data, lo, lo+len(text))
+		}
+		r.newline(&b)
+	}
+	return b.Text()
+}
+
+// isRSTAdornment reports whether line consists entirely of punctuation
+// used for section adornment or table borders, such as "=====" or "+---+---+".
+func isRSTAdornment(line []byte) bool {
+	if len(line) < 2 {
+		return false
+	}
+	c := line[0]
+	if strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) < 0 {
+		return false
+	}
+	table := c == '+'
+	for _, x := range line {
+		if x != c && !(table && (x == '-' || x == '=' || x == '+')) {
+			return false
+		}
+	}
+	return true
+}
+
+// rstDirective parses the directive "name:: args" at the start of text,
+// returning the directive name and the offset of its arguments.
+func rstDirective(text []byte) (name string, end int, ok bool) {
+	i := bytes.Index(text, []byte("::"))
+	if i <= 0 || i+2 < len(text) && text[i+2] != ' ' {
+		return "", 0, false
+	}
+	for _, c := range text[:i] {
+		if !isLetter(c) && !('0' <= c && c <= '9') && c != '-' && c != '_' && c != '.' && c != ':' {
+			return "", 0, false
+		}
+	}
+	return strings.ToLower(string(text[:i])), i + 2, true
+}
+
+// rstText adds the text data[lo:hi], removing inline markup
+// that is not punctuation ignored by license matching:
+// roles such as :ref:, link targets such as <https://example.com/>
+// in `text <target>`_ references, footnote references,
+// backslash escapes, and the :: introducing a literal block.
+func rstText(b *Builder, data []byte, lo, hi int) {
+	start := lo
+	drop := func(i, end int, text string) {
+		b.Copy(data, start, i)
+		b.Replace(text, i, end)
+		start = end
+	}
+	end := hi
+	literal := "" // replacement for a :: introducing a literal block
+	if hi-lo >= 2 && string(data[hi-2:hi]) == "::" {
+		hi -= 2
+		if hi > lo && data[hi-1] != ' ' {
+			literal = ":" // "text::" means "text:"
+		}
+	}
+	for i := lo; i < hi; {
+		switch c := data[i]; {
+		case c == '\\' && i+1 < hi:
+			drop(i, i+1, "")
+			i += 2
+
+		case c == ':' && (i == lo || !isLetter(data[i-1])):
+			// Role, as in :ref:`target`.
+			j := i + 1
+			for j < hi && (isLetter(data[j]) || '0' <= data[j] && data[j] <= '9' || strings.IndexByte("-_.:+", data[j]) >= 0) {
+				j++
+			}
+			if j < hi && data[j] == '`' && j > i+2 && data[j-1] == ':' {
+				drop(i, j, "")
+				i = j
+				break
+			}
+			i++
+
+		case c == '`' && i+1 < hi && data[i+1] == '`':
+			// Inline literal, as in ``text``.
+			j := bytes.Index(data[i+2:hi], []byte("``"))
+			if j < 0 {
+				i += 2
+				break
+			}
+			i += 2 + j + 2
+
+		case c == '`':
+			// Interpreted text or reference: drop <target> before closing `.
+			j := bytes.IndexByte(data[i+1:hi], '`')
+			if j < 0 {
+				i++
+				break
+			}
+			j += i + 1
+			if k := bytes.LastIndex(data[i+1:j], []byte(" <")); k >= 0 && data[j-1] == '>' {
+				drop(i+1+k, j, "")
+			}
+			i = j + 1
+
+		case c == '[' && (i == lo || data[i-1] == ' '):
+			// Footnote reference, as in [1]_ or [#note]_.
+			j := bytes.IndexByte(data[i:hi], ']')
+			if j > 0 && i+j+1 < hi && data[i+j+1] == '_' {
+				drop(i, i+j+2, "")
+				i += j + 2
+				break
+			}
+			i++
+
+		default:
+			i++
+		}
+	}
+	b.Copy(data, start, hi)
+	b.Replace(literal, hi, end)
+}
@@ -0,0 +1,214 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Rich Text Format.
+
+package extract
+
+import (
+	"bytes"
+	"strconv"
+)
+
+func isRTF(name string, data []byte) bool {
+	return bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte(`{\rtf`)) || ext(name) == ".rtf"
+}
+
+// rtfDestinations lists the RTF destinations whose contents are not
+// document text, such as tables of fonts and embedded pictures.
+var rtfDestinations = map[string]bool{
+	"author":             true,
+	"bkmkend":            true,
+	"bkmkstart":          true,
+	"colorschememapping": true,
+	"colortbl":           true,
+	"company":            true,
+	"datastore":          true,
+	"docvar":             true,
+	"filetbl":            true,
+	"fldinst":            true,
+	"fonttbl":            true,
+	"generator":          true,
+	"info":               true,
+	"latentstyles":       true,
+	"listoverridetable":  true,
+	"listtable":          true,
+	"listtext":           true,
+	"nonshppict":         true,
+	"object":             true,
+	"operator":           true,
+	"pgdsctbl":           true,
+	"pict":               true,
+	"pntext":             true,
+	"revtbl":             true,
+	"rsidtbl":            true,
+	"stylesheet":         true,
+	"template":           true,
+	"themedata":          true,
+	"title":              true,
+	"userprops":          true,
+	"xmlnstbl":           true,
+}
+
+// rtfText lists the RTF control words that stand for text.
+var rtfText = map[string]string{
+	"par":       "\n",
+	"line":      "\n",
+	"sect":      "\n",
+	"page":      "\n",
+	"row":       "\n",
+	"cell":      " ",
+	"tab":       "\t",
+	"emdash":    "—",
+	"endash":    "–",
+	"emspace":   " ",
+	"enspace":   " ",
+	"qmspace":   " ",
+	"lquote":    "‘",
+	"rquote":    "’",
+	"ldblquote": "“",
+	"rdblquote": "”",
+	"bullet":    "•",
+}
+
+// rtfSymbols lists the RTF control symbols that stand for text.
+var rtfSymbols = map[byte]string{
+	'\\': `\`,
+	'{':  "{",
+	'}':  "}",
+	'~':  " ",
+	'_':  "-",
+	'\n': "\n",
+	'\r': "\n",
+}
+
+// stripRTF returns the text of the RTF document data.
+func stripRTF(data []byte) *Text {
+	var b Builder
+
+	// A group is the state of an RTF group.
+	type group struct {
+		skip bool // group is a destination that is not text
+		uc   int  // number of fallback characters after \u
+	}
+	stack := []group{{uc: 1}}
+	skipChars := 0 // fallback characters left to skip
+
+	// emit adds text standing for data[lo:hi].
+	emit := func(text string, lo, hi int) {
+		if skipChars > 0 {
+			skipChars--
+			return
+		}
+		if !stack[len(stack)-1].skip {
+			b.Replace(text, lo, hi)
+		}
+	}
+
+	lo := 0 // start of pending literal text
+	flush := func(i int) {
+		if !stack[len(stack)-1].skip {
+			b.Copy(data, lo, i)
+		}
+	}
+	for i := 0; i < len(data); {
+		c := data[i]
+		switch {
+		default:
+			if skipChars > 0 {
+				flush(i)
+				skipChars--
+				lo = i + 1
+			}
+			i++
+			continue
+
+		case c == '{':
+			flush(i)
+			stack = append(stack, stack[len(stack)-1])
+			skipChars = 0
+			i++
+
+		case c == '}':
+			flush(i)
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			skipChars = 0
+			i++
+
+		case c == '\r' || c == '\n':
+			flush(i)
+			i++
+
+		case c == '\\' && i+1 < len(data) && !isLetter(data[i+1]):
+			flush(i)
+			end := i + 2
+			switch s := data[i+1]; s {
+			case '\'':
+				if end+2 <= len(data) {
+					if x, err := strconv.ParseUint(string(data[end:end+2]), 16, 8); err == nil {
+						end += 2
+						r := rune(x)
+						if x >= 0x80 && cp1252[x-0x80] != 0 {
+							r = cp1252[x-0x80]
+						}
+						emit(string(r), i, end)
+					}
+				}
+			case '*':
+				stack[len(stack)-1].skip = true
+			default:
+				emit(rtfSymbols[s], i, end)
+			}
+			i = end
+
+		case c == '\\':
+			flush(i)
+			j := i + 1
+			for j < len(data) && isLetter(data[j]) {
+				j++
+			}
+			word := string(data[i+1 : j])
+			k := j
+			if k < len(data) && data[k] == '-' {
+				k++
+			}
+			for k < len(data) && '0' <= data[k] && data[k] <= '9' {
+				k++
+			}
+			param, err := strconv.Atoi(string(data[j:k]))
+			hasParam := err == nil
+			end := k
+			if end < len(data) && data[end] == ' ' {
+				end++
+			}
+			switch {
+			case rtfDestinations[word]:
+				stack[len(stack)-1].skip = true
+			case word == "bin" && hasParam:
+				if param > len(data)-end {
+					end = len(data)
+				} else if param > 0 {
+					end += param
+				}
+			case word == "uc" && hasParam:
+				stack[len(stack)-1].uc = param
+			case word == "u" && hasParam:
+				if param < 0 {
+					param += 65536
+				}
+				skipChars = 0
+				emit(string(rune(param)), i, end)
+				skipChars = stack[len(stack)-1].uc
+			default:
+				emit(rtfText[word], i, end)
+			}
+			i = end
+		}
+		lo = i
+	}
+	flush(len(data))
+	return b.Text()
+}
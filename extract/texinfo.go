@@ -0,0 +1,190 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Texinfo.
+
+package extract
+
+import (
+	"bytes"
+	"strings"
+)
+
+func isTexinfo(name string, data []byte) bool {
+	switch ext(name) {
+	case ".texi", ".texinfo", ".txi":
+		return true
+	}
+	return bytes.HasPrefix(data, []byte(`\input texinfo`))
+}
+
+// texinfoLineDrop lists the commands whose line is not text.
+var texinfoLineDrop = map[string]bool{
+	"bye": true, "cindex": true, "clear": true,
+	"contents": true, "defindex": true, "dircategory": true, "documentencoding": true,
+	"documentlanguage": true, "end": true, "exampleindent": true, "findex": true,
+	"finalout": true, "firstparagraphindent": true, "footnotestyle": true,
+	"headings": true, "include": true, "insertcopying": true, "kindex": true,
+	"node": true, "page": true, "paragraphindent": true, "pindex": true,
+	"printindex": true, "set": true, "setchapternewpage": true, "setfilename": true,
+	"shortcontents": true, "smallbook": true, "sp": true, "syncodeindex": true,
+	"synindex": true, "tindex": true, "vindex": true, "vskip": true,
+
+	// Block commands whose contents are text.
+	"cartouche": true, "copying": true, "display": true, "enumerate": true,
+	"example": true, "flushleft": true, "flushright": true, "format": true,
+	"group": true, "itemize": true, "lisp": true, "multitable": true,
+	"quotation": true, "smalldisplay": true, "smallexample": true,
+	"smallformat": true, "smalllisp": true, "table": true, "ftable": true,
+	"vtable": true, "titlepage": true, "ifinfo": true, "iftex": true,
+	"ifhtml": true, "ifplaintext": true, "ifnottex": true, "ifnothtml": true,
+	"ifnotinfo": true, "ifnotplaintext": true, "ifdocbook": true, "ifxml": true,
+	"ifnotdocbook": true, "ifnotxml": true,
+}
+
+// texinfoSkipBlock lists the block commands whose contents are not text.
+var texinfoSkipBlock = map[string]bool{
+	"ignore": true, "macro": true, "rmacro": true, "tex": true, "html": true,
+	"docbook": true, "xml": true, "latex": true, "menu": true, "direntry": true,
+}
+
+// texinfoDropBraces lists the brace commands whose arguments are not text.
+var texinfoDropBraces = map[string]bool{
+	"anchor": true, "image": true, "footnotestyle": true, "today": true,
+	"hyphenation": true, "indexterm": true, "errormsg": true, "clicksequence": true,
+}
+
+// texinfoSymbols lists the commands that stand for text.
+var texinfoSymbols = map[string]string{
+	"@": "@", "{": "{", "}": "}", "*": "\n", ".": ".", "!": "!", "?": "?",
+	" ": " ", "\t": " ", "-": "", ":": "", "/": "", "|": "",
+	"copyright": "©", "registeredsymbol": "®", "dots": "…", "enddots": "...",
+	"tie": " ", "bullet": "•", "minus": "-", "result": "⇒", "expansion": "→",
+	"equiv": "≡", "error": "error→", "point": "∗", "print": "-|",
+	"TeX": "TeX", "LaTeX": "LaTeX", "tab": " ", "euro": "€", "pounds": "£",
+	"textdegree": "°", "comma": ",", "atchar": "@", "lbracechar": "{",
+	"rbracechar": "}", "backslashchar": `\`, "hashchar": "#",
+	"quotedblleft": "“", "quotedblright": "”", "quoteleft": "‘", "quoteright": "’",
+	"guillemetleft": "«", "guillemetright": "»", "exclamdown": "¡",
+	"questiondown": "¿", "ss": "ß", "ae": "æ", "AE": "Æ", "oe": "œ", "OE": "Œ",
+	"o": "ø", "O": "Ø", "l": "ł", "L": "Ł", "aa": "å", "AA": "Å", "i": "ı",
+}
+
+// stripTexinfo returns the text of the Texinfo document data.
+func stripTexinfo(data []byte) *Text {
+	var b Builder
+	r := lineReader{data: data}
+	skip := "" // name of block being skipped
+	for r.scan() {
+		line := r.line()
+		name, j := texinfoCommand(data, r.lo, r.hi)
+		if skip != "" {
+			if name == "end" && strings.TrimSpace(string(data[j:r.hi])) == skip {
+				skip = ""
+			}
+			continue
+		}
+		switch {
+		case bytes.HasPrefix(line, []byte(`\input`)):
+			continue
+		case texinfoSkipBlock[name]:
+			skip = name
+			continue
+		case name == "c" || name == "comment":
+			continue
+		case texinfoLineDrop[name]:
+			r.newline(&b)
+			continue
+		case name != "" && j < r.hi && isSpace(data[j]):
+			// Line command, such as @chapter or @item: keep the argument.
+			for j < r.hi && isSpace(data[j]) {
+				j++
+			}
+			texinfoText(&b, data, j, r.hi)
+		default:
+			texinfoText(&b, data, r.lo, r.hi)
+		}
+		r.newline(&b)
+	}
+	return b.Text()
+}
+
+// texinfoCommand returns the name of the command at the start of the line data[lo:hi]
+// and the end of the name, or "", lo if the line does not begin with a command.
+func texinfoCommand(data []byte, lo, hi int) (string, int) {
+	if lo >= hi || data[lo] != '@' {
+		return "", lo
+	}
+	j := lo + 1
+	for j < hi && isLetter(data[j]) {
+		j++
+	}
+	return string(data[lo+1 : j]), j
+}
+
+// texinfoText adds the text data[lo:hi], interpreting Texinfo commands.
+// Brace commands such as @emph{text} are replaced by their arguments.
+func texinfoText(b *Builder, data []byte, lo, hi int) {
+	start := lo
+	depth := 0    // brace depth
+	dropped := -1 // depth of brace command whose argument is being dropped, or -1
+	emit := func(text string, i, end int) {
+		if dropped < 0 {
+			b.Copy(data, start, i)
+			b.Replace(text, i, end)
+		}
+		start = end
+	}
+	for i := lo; i < hi; {
+		switch data[i] {
+		default:
+			i++
+			continue
+		case '{':
+			depth++
+			emit("", i, i+1)
+		case '}':
+			emit("", i, i+1)
+			if depth == dropped {
+				dropped = -1
+			}
+			if depth > 0 {
+				depth--
+			}
+		case '@':
+			j := i + 1
+			for j < hi && isLetter(data[j]) {
+				j++
+			}
+			if j == i+1 && j < hi {
+				j++ // symbol command, as in @@ or @{
+			}
+			name := string(data[i+1 : j])
+			if name == "c" || name == "comment" {
+				emit("", i, hi)
+				i = hi
+				continue
+			}
+			if texinfoDropBraces[name] && j < hi && data[j] == '{' {
+				emit("", i, j+1)
+				depth++
+				if dropped < 0 {
+					dropped = depth
+				}
+				i = start
+				continue
+			}
+			if j+1 < hi && data[j] == '{' && data[j+1] == '}' {
+				j += 2 // as in @dots{}
+			}
+			// Unknown commands, including font commands such as @emph
+			// and accents such as @'e, are removed, leaving their arguments.
+			emit(texinfoSymbols[name], i, j)
+		}
+		i = start
+	}
+	if dropped < 0 {
+		b.Copy(data, start, hi)
+	}
+}
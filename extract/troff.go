@@ -0,0 +1,248 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// troff, man, and mdoc manual pages.
+
+package extract
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+func isTroff(name string, data []byte) bool {
+	switch e := ext(name); {
+	case e == ".man" || e == ".mdoc" || e == ".roff":
+		return true
+	case len(e) >= 2 && len(e) <= 5 && '1' <= e[1] && e[1] <= '9' && strings.TrimLeft(e[2:], "abcdefghijklmnopqrstuvwxyz") == "":
+		// Manual section, as in ls.1 or File::Spec.3pm.
+		return true
+	}
+	// The first line that is not a comment is .TH (man) or .Dd (mdoc).
+	r := lineReader{data: data}
+	for r.scan() {
+		line := r.line()
+		if bytes.HasPrefix(line, []byte(`.\"`)) || bytes.HasPrefix(line, []byte(`'\"`)) || len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		return bytes.HasPrefix(line, []byte(".TH ")) || bytes.HasPrefix(line, []byte(".Dd "))
+	}
+	return false
+}
+
+// troffDropArgs lists the macros whose arguments are not text.
+// The arguments of troff requests, which have lower-case names,
+// are never text.
+var troffDropArgs = map[string]bool{
+	"TH": true, // man: title, section, date, source, manual
+	"Dd": true, // mdoc: date
+	"Dt": true, // mdoc: title, section
+	"Os": true, // mdoc: operating system
+	"DT": true,
+	"PD": true,
+	"IX": true,
+	"UC": true,
+}
+
+// troffCallable lists the mdoc macros that can be called
+// from the arguments of other macros.
+var troffCallable = map[string]bool{
+	"Ad": true, "Ar": true, "Cm": true, "Dl": true, "Dq": true, "Dv": true,
+	"Em": true, "Er": true, "Ev": true, "Fl": true, "Ic": true, "Li": true,
+	"Nm": true, "No": true, "Ns": true, "Op": true, "Pa": true, "Pq": true,
+	"Ql": true, "Qq": true, "Sq": true, "Sy": true, "Va": true, "Xr": true,
+}
+
+// troffChars lists the names of troff special characters, as in \(em or \[em].
+var troffChars = map[string]string{
+	"em": "—", "en": "–", "hy": "-", "mi": "-", "pl": "+", "eq": "=", "mu": "×",
+	"co": "©", "rg": "®", "tm": "™", "bu": "•", "dg": "†", "sc": "§", "de": "°",
+	"lq": "“", "rq": "”", "oq": "‘", "cq": "’", "aq": "'", "dq": "\"",
+	"Fo": "«", "Fc": "»", "ga": "`", "aa": "´", "ti": "~", "ha": "^", "rs": `\`,
+}
+
+// troffStrings lists the predefined strings, as in \*(lq or \*R.
+var troffStrings = map[string]string{
+	"lq": "“", "rq": "”", "Lq": "“", "Rq": "”", "R": "®", "Tm": "™",
+}
+
+// stripTroff returns the text of the troff document data.
+func stripTroff(data []byte) *Text {
+	var b Builder
+	r := lineReader{data: data}
+	end := "" // end of a macro definition or ignored block being skipped
+	for r.scan() {
+		line := r.line()
+		if end != "" {
+			if string(bytes.TrimSpace(line)) == end {
+				end = ""
+			}
+			continue
+		}
+		if len(line) == 0 || line[0] != '.' && line[0] != '\'' {
+			troffText(&b, data, r.lo, r.hi, false)
+			r.newline(&b)
+			continue
+		}
+
+		// Request or macro call.
+		i := r.lo + 1
+		for i < r.hi && isSpace(data[i]) {
+			i++
+		}
+		j := i
+		for j < r.hi && !isSpace(data[j]) {
+			j++
+		}
+		name := string(data[i:j])
+		switch {
+		case strings.HasPrefix(name, `\"`) || strings.HasPrefix(name, `\#`):
+			// Comment line.
+			continue
+		case name == "de" || name == "de1" || name == "am" || name == "ig":
+			end = ".."
+			if f := strings.Fields(string(data[j:r.hi])); len(f) >= 2 {
+				end = "." + f[1]
+			}
+			continue
+		case !troffDropArgs[name] && strings.ToLower(name) != name:
+			for j < r.hi && isSpace(data[j]) {
+				j++
+			}
+			troffText(&b, data, j, r.hi, true)
+		}
+		r.newline(&b)
+	}
+	return b.Text()
+}
+
+// troffText adds the text data[lo:hi], interpreting troff escapes.
+// If args is true, the text is the arguments of a macro,
+// from which quotes and callable macro names are removed.
+func troffText(b *Builder, data []byte, lo, hi int, args bool) {
+	start := lo
+	for i := lo; i < hi; {
+		c := data[i]
+		switch {
+		case args && c == '"':
+			b.Copy(data, start, i)
+			i++
+			start = i
+			continue
+		case args && (i == lo || isSpace(data[i-1])) && i+2 <= hi && troffCallable[string(data[i:i+2])] && (i+2 == hi || isSpace(data[i+2])):
+			b.Copy(data, start, i)
+			i += 2
+			start = i
+			continue
+		case c != '\\':
+			i++
+			continue
+		}
+		b.Copy(data, start, i)
+		text, end, comment := troffEscape(data, i, hi)
+		if comment {
+			start = hi
+			break
+		}
+		b.Replace(text, i, end)
+		i = end
+		start = i
+	}
+	b.Copy(data, start, hi)
+}
+
+// troffEscape returns the text for the escape at data[i:],
+// which starts with a backslash, and the end of the escape.
+// It reports whether the escape begins a comment.
+func troffEscape(data []byte, i, hi int) (text string, end int, comment bool) {
+	j := i + 1
+	if j >= hi {
+		return "", hi, false // line continuation
+	}
+	switch c := data[j]; c {
+	case '"', '#':
+		return "", hi, true
+	case 'e', '\\':
+		return `\`, j + 1, false
+	case ' ', '~', '0':
+		return " ", j + 1, false
+	case '-', '.', '\'', '`':
+		return string(c), j + 1, false
+	case '&', '|', '^', ')', '/', ',', ':', '%', 'c', 'd', 'u', 'p', 'r', 't', 'a':
+		return "", j + 1, false
+	case '(', '[':
+		name, end := troffName(data, j, hi)
+		return troffChar(name), end, false
+	case '*':
+		name, end := troffName(data, j+1, hi)
+		return troffStrings[name], end, false
+	case 'f', 'F', 'n', 'g', 'k', 'm', 'M', 'V', 'Y', '$':
+		_, end := troffName(data, j+1, hi)
+		return "", end, false
+	case 's':
+		k := j + 1
+		if k < hi && (data[k] == '+' || data[k] == '-') {
+			k++
+		}
+		if k < hi && (data[k] == '(' || data[k] == '[') {
+			_, end := troffName(data, k, hi)
+			return "", end, false
+		}
+		for k < hi && '0' <= data[k] && data[k] <= '9' {
+			k++
+		}
+		return "", k, false
+	case 'h', 'v', 'w', 'o', 'b', 'l', 'L', 'x', 'D', 'X', 'Z', 'A', 'B', 'C', 'N', 'R', 'S', 'H':
+		// Escape with a delimited argument, as in \h'1i'.
+		k := j + 1
+		if k < hi {
+			if m := bytes.IndexByte(data[k+1:hi], data[k]); m >= 0 {
+				return "", k + 1 + m + 1, false
+			}
+		}
+		return "", hi, false
+	}
+	return string(data[j]), j + 1, false
+}
+
+// troffName returns the escape name at data[j:]:
+// two characters after (, any characters up to ] after [,
+// or otherwise a single character.
+func troffName(data []byte, j, hi int) (string, int) {
+	if j >= hi {
+		return "", hi
+	}
+	switch data[j] {
+	case '(':
+		if j+3 <= hi {
+			return string(data[j+1 : j+3]), j + 3
+		}
+		return "", hi
+	case '[':
+		if k := bytes.IndexByte(data[j:hi], ']'); k >= 0 {
+			return string(data[j+1 : j+k]), j + k + 1
+		}
+		return "", hi
+	}
+	return string(data[j : j+1]), j + 1
+}
+
+// troffChar returns the text for the special character name.
+func troffChar(name string) string {
+	if s, ok := troffChars[name]; ok {
+		return s
+	}
+	if strings.HasPrefix(name, "u") {
+		// Unicode character, as in \[u00E9].
+		if x, err := strconv.ParseUint(name[1:], 16, 32); err == nil {
+			return string(rune(x))
+		}
+	}
+	if len(name) == 2 && isLetter(name[1]) {
+		// Accented letter, as in \('e: keep the letter.
+		return name[1:]
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path"
+
+	"github.com/google/licensecheck/internal/vcs"
+)
+
+// A FileCoverage describes the licenses found in a single file by ScanFS.
+type FileCoverage struct {
+	Path string // slash-separated path of the file in the file system
+	Data []byte // content of a file in an archive, which cannot be read by Path; nil otherwise
+	Coverage
+}
+
+// maxScanFSFile is the maximum size of a file scanned by ScanFS.
+// Larger files are almost never license texts or annotated sources.
+const maxScanFSFile = 4 << 20
+
+// ScanFS scans the files in the file tree rooted at root in fsys
+// using the built-in license set. See the ScanFS method for details.
+func ScanFS(fsys fs.FS, root string) ([]FileCoverage, error) {
+	return builtinScanner.ScanFS(fsys, root)
+}
+
+// ScanFS scans each regular file in the file tree rooted at root in fsys,
+// returning the coverage of the files in which at least one license was found,
+// in lexical order by path.
+//
+// ScanFS skips version control metadata directories (.git, .hg, .svn, .bzr),
+// files larger than 4 MB, and files that appear to be binary
+// (that contain a NUL byte in their first 8 kB).
+func (s *Scanner) ScanFS(fsys fs.FS, root string) ([]FileCoverage, error) {
+	return s.ScanFSWithOptions(fsys, root, ScanFSOptions{})
+}
+
+// ScanFSOptions holds optional settings for ScanFSWithOptions.
+// The zero ScanFSOptions gives the same results as ScanFS.
+type ScanFSOptions struct {
+	// Archives, if true, makes ScanFSWithOptions scan the license files
+	// in the archives in the tree, such as release tarballs and jar files,
+	// as ScanArchive does, instead of skipping them as binary files.
+	// The results for those files have paths like "dist/pkg.tar.gz!/pkg/LICENSE",
+	// so they cannot be read from the file tree.
+	// An archive that is not valid, such as a corrupt test fixture,
+	// is skipped like other binary files, but an archive that exceeds
+	// ArchiveLimits is an error.
+	Archives bool
+
+	// ArchiveLimits limits the reading of each archive in the tree.
+	ArchiveLimits ArchiveLimits
+}
+
+// ScanFSWithOptions scans the files in the file tree rooted at root in fsys
+// using the built-in license set and the settings in opts.
+// See the ScanFSWithOptions method for details.
+func ScanFSWithOptions(fsys fs.FS, root string, opts ScanFSOptions) ([]FileCoverage, error) {
+	return builtinScanner.ScanFSWithOptions(fsys, root, opts)
+}
+
+// ScanFSWithOptions is like ScanFS but uses the settings in opts.
+func (s *Scanner) ScanFSWithOptions(fsys fs.FS, root string, opts ScanFSOptions) ([]FileCoverage, error) {
+	var list []FileCoverage
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != root && vcs.IsMetadataDir(path.Base(file)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if opts.Archives && IsArchive(file) {
+			f, err := fsys.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			files, err := s.scanFSArchive(f, file, opts.ArchiveLimits)
+			if errors.Is(err, ErrArchiveLimit) {
+				return err
+			}
+			if err == nil {
+				list = append(list, files...)
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > maxScanFSFile {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		if isBinary(data) {
+			return nil
+		}
+		cov := s.Scan(data)
+		if len(cov.Match) > 0 {
+			list = append(list, FileCoverage{Path: file, Coverage: cov})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// scanFSArchive scans the archive f, named file,
+// using random access if the file system provides it.
+func (s *Scanner) scanFSArchive(f fs.File, file string, limits ArchiveLimits) ([]FileCoverage, error) {
+	if r, ok := f.(io.ReaderAt); ok {
+		info, err := f.Stat()
+		if err != nil {
+			return nil, err
+		}
+		return s.ScanArchive(file, r, info.Size(), limits)
+	}
+	return s.ScanArchiveStream(file, f, limits)
+}
+
+// isBinary reports whether data appears to be binary,
+// because it contains a NUL byte in its first 8 kB.
+func isBinary(data []byte) bool {
+	head := data
+	if len(head) > 8<<10 {
+		head = head[:8<<10]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
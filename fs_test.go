@@ -0,0 +1,47 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestScanFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pkg/LICENSE":        {Data: []byte(license_MIT)},
+		"pkg/README":         {Data: []byte("Licensed under https://www.apache.org/licenses/LICENSE-2.0\n")},
+		"pkg/main.go":        {Data: []byte("package main\n")},
+		"pkg/image.png":      {Data: []byte("\x89PNG\x00" + license_MIT)},
+		"pkg/.git/LICENSE":   {Data: []byte(license_MIT)},
+		"pkg/sub/COPYING":    {Data: []byte(license_MIT)},
+		"other/LICENSE.html": {Data: []byte(license_MIT)},
+	}
+	list, err := ScanFS(fsys, "pkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		path, id string
+		isURL    bool
+	}{
+		{"pkg/LICENSE", "MIT", false},
+		{"pkg/README", "Apache-2.0", true},
+		{"pkg/sub/COPYING", "MIT", false},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("ScanFS found %d files, want %d: %+v", len(list), len(want), list)
+	}
+	for i, w := range want {
+		f := list[i]
+		if f.Path != w.path || len(f.Match) != 1 || f.Match[0].ID != w.id || f.Match[0].IsURL != w.isURL {
+			t.Errorf("ScanFS #%d = %s %+v, want %s %s IsURL=%v", i, f.Path, f.Match, w.path, w.id, w.isURL)
+		}
+	}
+
+	if _, err := ScanFS(fsys, "missing"); err == nil {
+		t.Errorf("ScanFS(missing) succeeded, want error")
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"go/format"
 	"io/ioutil"
 	"log"
+	"os"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -41,19 +42,32 @@ func main() {
 		log.Fatal("no license files")
 	}
 
+	// Running with data.gen.go.triv, LoadLicenses has no built-in templates,
+	// so it sees exactly the definitions in the license files.
+	builtLRE, err := licensecheck.LoadLicenses(os.DirFS("licenses"), "*.lre")
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	code := outputTemplate
 	out := new(bytes.Buffer)
-	builtLRE := buildLRE(filesLRE)
-	for _, file := range builtLRE {
-		fmt.Fprintf(out, "\t\t{ID: %q, %s LRE: %v},\n", file.Name, file.Type, varName(file.Name+".lre"))
+	for _, l := range builtLRE {
+		tstr := ""
+		if l.Type != licensecheck.Unknown {
+			tstr = "Type: " + l.Type.String() + ","
+		}
+		lstr := ""
+		if l.Language != "" {
+			lstr = fmt.Sprintf(" Language: %q,", l.Language)
+		}
+		fmt.Fprintf(out, "\t\t{ID: %q, %s LRE: %v,%s},\n", l.ID, tstr, varName(fileName(l)), lstr)
 	}
 	code = strings.Replace(code, "FILES_LIST", out.String(), -1)
 
 	out.Reset()
-	for _, file := range builtLRE {
-		fmt.Fprintf(out, "const %s = `%s`\n",
-			varName(file.Name+".lre"),
-			bytes.ReplaceAll(file.Data, []byte("`"), []byte("` + \"`\" + `")))
+	fmt.Fprintf(out, "const builtinTemplates = %s\n", quote(buildTemplates(filesLRE)))
+	for _, l := range builtLRE {
+		fmt.Fprintf(out, "const %s = %s\n", varName(fileName(l)), quote(l.LRE))
 	}
 	code += out.String()
 
@@ -75,6 +89,19 @@ func main() {
 	}
 }
 
+// quote returns s as a Go raw string literal.
+func quote(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "` + \"`\" + `") + "`"
+}
+
+// fileName returns the name of the file defining l.
+func fileName(l licensecheck.License) string {
+	if l.Language != "" {
+		return l.ID + "." + l.Language + ".lre"
+	}
+	return l.ID + ".lre"
+}
+
 // varName returns the basename of the file, sanitized for use as a variable name,
 // and given the prefix "license_".
 func varName(file string) string {
@@ -97,82 +124,28 @@ var builtinLREs = []License{
 }
 `
 
-type fileData struct {
-	Name string
-	Type string
-	Data []byte
-}
-
-func buildLRE(filesLRE []string) []fileData {
-	var typ licensecheck.Type
-	setType := func(s string) (string, error) {
-		t, err := licensecheck.ParseType(s)
-		if err != nil {
-			return "", err
-		}
-		typ = t
-		return "", nil
-	}
-	var out []fileData
+// buildTemplates returns the {{define}} blocks for the shared templates,
+// such as "mit-grant", in the license files, for use by LoadLicenses.
+// The templates defining licenses themselves, named *.lre, are omitted.
+func buildTemplates(filesLRE []string) string {
 	t := template.New("").Funcs(template.FuncMap{
-		"list": templateList,
-		"Type": setType,
+		"list": func(...interface{}) []interface{} { return nil },
+		"Type": func(string) string { return "" },
 	})
 	t, err := t.ParseFiles(filesLRE...)
 	if err != nil {
 		log.Fatal("parsing LRE templates:", err)
 	}
+	var names []string
 	for _, t := range t.Templates() {
-		if strings.HasSuffix(t.Name(), ".lre") {
-			var buf bytes.Buffer
-			typ = licensecheck.Unknown
-			if err := t.Execute(&buf, nil); err != nil {
-				log.Fatalf("executing %s: %v", t.Name(), err)
-			}
-			if len(bytes.TrimSpace(buf.Bytes())) == 0 {
-				// Only contained useful definitions.
-				continue
-			}
-			tstr := ""
-			if typ != licensecheck.Unknown {
-				tstr = "Type: " + typ.String() + ","
-			}
-			out = append(out, fileData{strings.TrimSuffix(t.Name(), ".lre"), tstr, buf.Bytes()})
+		if t.Name() != "" && !strings.HasSuffix(t.Name(), ".lre") {
+			names = append(names, t.Name())
 		}
 	}
-	sort.Slice(out, func(i, j int) bool {
-		ni, nj := out[i].Name, out[j].Name
-
-		// Special case: BSD-4-Clause is a generalization of BSD-4-Clause-UC.
-		// In case of multiple matches, licensecheck always returns the one earlier in the list.
-		// Make BSD-4-Clause-UC the one earlier in the list.
-		if strings.HasPrefix(ni, "BSD-4-Clause") && strings.HasPrefix(nj, "BSD-4-Clause") {
-			ni, nj = nj, ni
-		}
-
-		// Special case: GFDL-1.[123]-invariants-* is a generalization of GFDL-1.[123]-no-invariants-*.
-		// Reverse that order too.
-		if strings.HasPrefix(ni, "GFDL-") && strings.HasPrefix(nj, "GFDL-") {
-			ni, nj = nj, ni
-		}
-
-		return ni < nj
-	})
-
-	return out
-}
-
-// templateList returns xs, but it flattens any nested []interface{} into the main list.
-// Called from templates as "list", to pass multiple arguments to templates.
-func templateList(xs ...interface{}) []interface{} {
-	var list []interface{}
-	for _, x := range xs {
-		switch x := x.(type) {
-		case []interface{}:
-			list = append(list, x...)
-		default:
-			list = append(list, x)
-		}
+	sort.Strings(names)
+	var buf bytes.Buffer
+	for _, name := range names {
+		fmt.Fprintf(&buf, "{{define %q}}%s{{end}}\n", name, t.Lookup(name).Tree.Root)
 	}
-	return list
+	return buf.String()
 }
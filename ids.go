@@ -0,0 +1,260 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// An IDAlias records that a license ID has been replaced by another.
+type IDAlias struct {
+	Old string // old, deprecated, or legacy ID
+	New string // current ID
+}
+
+// An IDAliasTable is a list of ID aliases for a single version of the SPDX
+// license list or of licensecheck itself. For SPDX, the Old IDs are the ones
+// deprecated in that version of the list. For licensecheck, the Old IDs are
+// the ones last used by that release.
+type IDAliasTable struct {
+	Version string // "SPDX 3.0", "licensecheck v0.1.0", and so on
+	Aliases []IDAlias
+}
+
+// idAliasTables lists the known aliases, oldest version first.
+//
+// SPDX deprecated the unsuffixed GNU IDs (GPL-2.0, LGPL-2.1, and so on) in
+// version 3.0 of the license list, but licensecheck still reports them for
+// the license text itself (see licenses/README.md), so they are not aliases.
+//
+// A few deprecated SPDX IDs were split into a license and an exception.
+// Their New entries are SPDX expressions using the WITH operator.
+var idAliasTables = []IDAliasTable{
+	{"SPDX 2.6", []IDAlias{
+		{"eCos-2.0", "GPL-2.0-or-later WITH eCos-exception-2.0"},
+		{"GPL-2.0-with-autoconf-exception", "GPL-2.0 WITH Autoconf-exception-2.0"},
+		{"GPL-2.0-with-bison-exception", "GPL-2.0 WITH Bison-exception-2.2"},
+		{"GPL-2.0-with-classpath-exception", "GPL-2.0 WITH Classpath-exception-2.0"},
+		{"GPL-2.0-with-font-exception", "GPL-2.0 WITH Font-exception-2.0"},
+		{"GPL-2.0-with-GCC-exception", "GPL-2.0 WITH GCC-exception-2.0"},
+		{"GPL-3.0-with-autoconf-exception", "GPL-3.0 WITH Autoconf-exception-3.0"},
+		{"GPL-3.0-with-GCC-exception", "GPL-3.0 WITH GCC-exception-3.1"},
+		{"StandardML-NJ", "SMLNJ"},
+		{"wxWindows", "LGPL-2.0-or-later WITH WxWindows-exception-3.1"},
+	}},
+	{"SPDX 3.0", []IDAlias{
+		{"GPL-1.0+", "GPL-1.0-or-later"},
+		{"GPL-2.0+", "GPL-2.0-or-later"},
+		{"GPL-3.0+", "GPL-3.0-or-later"},
+		{"LGPL-2.0+", "LGPL-2.0-or-later"},
+		{"LGPL-2.1+", "LGPL-2.1-or-later"},
+		{"LGPL-3.0+", "LGPL-3.0-or-later"},
+	}},
+	{"SPDX 3.9", []IDAlias{
+		{"BSD-2-Clause-NetBSD", "BSD-2-Clause"},
+	}},
+	{"SPDX 3.10", []IDAlias{
+		{"Nunito", "OFL-1.1"},
+	}},
+	{"SPDX 3.18", []IDAlias{
+		{"BSD-2-Clause-FreeBSD", "BSD-2-Clause-Views"},
+	}},
+	{"licensecheck v0.1.0", []IDAlias{
+		// IDs used by the old Cover API.
+		// (IDs that differ only in case, like GPL-2.0-Only, need no alias.)
+		{"Apache-2.0-Header", "Apache-2.0"},
+		{"BlueOak-1.0", "BlueOak-1.0.0"},
+		{"BSD-0-Clause", "0BSD"},
+		{"GPL2", "GPL-2.0"},
+		{"GPL3", "GPL-3.0"},
+		{"MPL-2.0-Header", "MPL-2.0"},
+
+		// SPDX IDs that licensecheck never reports.
+		// See licenses/README.md.
+		{"CAL-1.0-Combined-Work-Exception", "CAL-1.0"},
+		{"OFL-1.0-no-RFN", "OFL-1.0"},
+		{"OFL-1.0-RFN", "OFL-1.0"},
+		{"OFL-1.1-no-RFN", "OFL-1.1"},
+		{"OFL-1.1-RFN", "OFL-1.1"},
+	}},
+}
+
+// IDAliasTables returns the tables of license ID aliases used by CanonicalID.
+// The tables are ordered by version, oldest first.
+func IDAliasTables() []IDAliasTable {
+	// Return a copy so caller cannot change list entries.
+	var list []IDAliasTable
+	for _, t := range idAliasTables {
+		list = append(list, IDAliasTable{t.Version, append([]IDAlias{}, t.Aliases...)})
+	}
+	return list
+}
+
+var (
+	idIndexOnce sync.Once
+	idBuiltin   map[string]string // lower-case builtin ID -> builtin ID
+	idAlias     map[string]string // lower-case old ID -> new ID
+	idNonSPDX   map[string]bool   // lower-case builtin ID -> not an SPDX ID
+	idSPDXAlias map[string]bool   // lower-case old ID -> deprecated SPDX ID
+)
+
+func initIDIndex() {
+	idBuiltin = make(map[string]string)
+	for _, l := range builtinLREs {
+		idBuiltin[strings.ToLower(l.ID)] = l.ID
+	}
+	idAlias = make(map[string]string)
+	idSPDXAlias = make(map[string]bool)
+	for _, t := range idAliasTables {
+		for _, a := range t.Aliases {
+			idAlias[strings.ToLower(a.Old)] = a.New
+			if strings.HasPrefix(t.Version, "SPDX ") {
+				idSPDXAlias[strings.ToLower(a.Old)] = true
+			}
+		}
+	}
+	idNonSPDX = make(map[string]bool)
+	for _, id := range nonSPDXIDs {
+		idNonSPDX[strings.ToLower(id)] = true
+	}
+}
+
+// CanonicalID returns the current licensecheck ID for the license ID id.
+// Like SPDX, it compares IDs without regard to case.
+//
+// If id is a built-in license ID, CanonicalID returns it with canonical case.
+// If id is a deprecated SPDX ID or a legacy licensecheck ID listed in
+// IDAliasTables, CanonicalID returns its replacement, which may be an SPDX
+// expression like "GPL-2.0 WITH Classpath-exception-2.0" for deprecated
+// IDs that combined a license and an exception.
+// Otherwise CanonicalID returns id, false.
+func CanonicalID(id string) (string, bool) {
+	idIndexOnce.Do(initIDIndex)
+	key := strings.ToLower(id)
+	if c, ok := idBuiltin[key]; ok {
+		return c, true
+	}
+	if c, ok := idAlias[key]; ok {
+		return c, true
+	}
+	return id, false
+}
+
+// aliasID returns the replacement for id if id is an alias,
+// or else id itself.
+// Unlike CanonicalID, it does not change the case of unaliased IDs,
+// so that custom licenses passed to NewScanner keep their names.
+func aliasID(id string) string {
+	idIndexOnce.Do(initIDIndex)
+	if c, ok := idAlias[strings.ToLower(id)]; ok {
+		return c
+	}
+	return id
+}
+
+// resolveAliases returns a copy of licenses with any aliased IDs replaced.
+// It returns an error if replacing an alias makes two licenses with
+// patterns have the same ID, since the scanner could not tell them apart.
+func resolveAliases(licenses []License) ([]License, error) {
+	list := make([]License, 0, len(licenses))
+	old := make(map[string]string) // new ID and language -> ID as written, for licenses with LREs
+	for _, l := range licenses {
+		id := aliasID(l.ID)
+		if l.LRE != "" {
+			key := id + " " + l.Language
+			if prev, ok := old[key]; ok && (prev != id || l.ID != id) {
+				return nil, fmt.Errorf("licenses %s and %s are both %s", prev, l.ID, id)
+			}
+			old[key] = l.ID
+		}
+		l.ID = id
+		list = append(list, l)
+	}
+	return list, nil
+}
+
+// nonSPDXIDs lists the built-in license IDs that licensecheck defines itself,
+// which are not on the SPDX license list. See the “Delta from SPDX” notes
+// in licenses/README.md.
+var nonSPDXIDs = []string{
+	"Aladdin-9",
+	"Anti996",
+	"BSD-1-Clause-Clear",
+	"BSD-3-Clause-NoTrademark",
+	"CC-BY-NC-SA-3.0-US",
+	"CommonsClause",
+	"GPL-2.0-or-3.0",
+	"GooglePatentClause",
+	"GooglePatentsFile",
+	"MIT-NoAd",
+	"Prosperity-3.0.0",
+}
+
+// IsSPDX reports whether id is an SPDX license ID.
+// Like SPDX, it compares IDs without regard to case.
+//
+// The SPDX IDs are the built-in license IDs other than the ones licensecheck
+// defines itself (see licenses/README.md), along with the deprecated SPDX IDs
+// listed in IDAliasTables. Unsuffixed GNU IDs like GPL-2.0, which SPDX has
+// deprecated but licensecheck still reports, are SPDX IDs.
+func IsSPDX(id string) bool {
+	idIndexOnce.Do(initIDIndex)
+	key := strings.ToLower(id)
+	if _, ok := idBuiltin[key]; ok {
+		return !idNonSPDX[key]
+	}
+	return idSPDXAlias[key]
+}
+
+// SPDXLicenseID returns the identifier to use for the license ID id
+// in an SPDX document. If id is an SPDX ID or already begins with
+// "LicenseRef-", SPDXLicenseID returns id unchanged.
+// Otherwise it returns "LicenseRef-" followed by id, with any characters
+// not allowed in an SPDX identifier (letters, digits, "." and "-")
+// replaced by "-". For example, SPDXLicenseID("MIT-NoAd")
+// is "LicenseRef-MIT-NoAd".
+func SPDXLicenseID(id string) string {
+	if IsSPDX(id) || strings.HasPrefix(id, "LicenseRef-") {
+		return id
+	}
+	return "LicenseRef-" + strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, id)
+}
+
+// SPDXExpression returns the license expression x with each license ID
+// replaced by SPDXLicenseID of that ID, for use in an SPDX document
+// or other SPDX-based format. Operators, parentheses, the special values
+// NONE and NOASSERTION, and exception IDs following WITH are unchanged.
+// For example, SPDXExpression("MIT-NoAd OR Apache-2.0") is
+// "LicenseRef-MIT-NoAd OR Apache-2.0".
+func SPDXExpression(x string) string {
+	var out []string
+	f := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ").Replace(x))
+	for i := 0; i < len(f); i++ {
+		w := f[i]
+		switch w {
+		case "(", ")", "AND", "OR", "NONE", "NOASSERTION":
+		case "WITH":
+			if i+1 < len(f) {
+				out = append(out, w)
+				i++
+				w = f[i]
+			}
+		default:
+			w = SPDXLicenseID(w)
+		}
+		out = append(out, w)
+	}
+	s := strings.Join(out, " ")
+	s = strings.ReplaceAll(s, "( ", "(")
+	s = strings.ReplaceAll(s, " )", ")")
+	return s
+}
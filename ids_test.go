@@ -0,0 +1,132 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"strings"
+	"testing"
+)
+
+var canonicalIDTests = []struct {
+	in  string
+	out string
+	ok  bool
+}{
+	{"MIT", "MIT", true},
+	{"mit", "MIT", true},
+	{"GPL-2.0", "GPL-2.0", true},
+	{"GPL-2.0+", "GPL-2.0-or-later", true},
+	{"gpl-2.0+", "GPL-2.0-or-later", true},
+	{"GPL-2.0-Only", "GPL-2.0-only", true},
+	{"LGPL-2.1", "LGPL-2.1", true},
+	{"LGPL-2.1+", "LGPL-2.1-or-later", true},
+	{"eCos-2.0", "GPL-2.0-or-later WITH eCos-exception-2.0", true},
+	{"StandardML-NJ", "SMLNJ", true},
+	{"BSD-2-Clause-FreeBSD", "BSD-2-Clause-Views", true},
+	{"BSD-0-Clause", "0BSD", true},
+	{"OFL-1.1-RFN", "OFL-1.1", true},
+	{"Not-A-License", "Not-A-License", false},
+}
+
+func TestCanonicalID(t *testing.T) {
+	for _, tt := range canonicalIDTests {
+		out, ok := CanonicalID(tt.in)
+		if out != tt.out || ok != tt.ok {
+			t.Errorf("CanonicalID(%q) = %q, %v, want %q, %v", tt.in, out, ok, tt.out, tt.ok)
+		}
+	}
+}
+
+func TestIDAliasTables(t *testing.T) {
+	builtin := make(map[string]bool)
+	for _, l := range BuiltinLicenses() {
+		builtin[strings.ToLower(l.ID)] = true
+	}
+	seen := make(map[string]string)
+	for _, table := range IDAliasTables() {
+		for _, a := range table.Aliases {
+			if builtin[strings.ToLower(a.Old)] {
+				t.Errorf("%s: alias %s is a built-in license ID", table.Version, a.Old)
+			}
+			if v, ok := seen[strings.ToLower(a.Old)]; ok {
+				t.Errorf("%s: alias %s already listed in %s", table.Version, a.Old, v)
+			}
+			seen[strings.ToLower(a.Old)] = table.Version
+
+			// The first ID in a WITH expression is the license.
+			id := strings.Fields(a.New)[0]
+			if c, ok := CanonicalID(id); !ok || c != id {
+				t.Errorf("%s: alias %s -> %s: %s is not a built-in license ID", table.Version, a.Old, a.New, id)
+			}
+		}
+	}
+}
+
+func TestNewScannerAlias(t *testing.T) {
+	s, err := NewScanner([]License{
+		{ID: "GPL-2.0+", LRE: "this is a custom license"},
+		{ID: "Mine", LRE: "this is another custom license"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov := s.Scan([]byte("This is a custom license."))
+	if len(cov.Match) != 1 || cov.Match[0].ID != "GPL-2.0-or-later" {
+		t.Errorf("Scan: matches = %+v, want GPL-2.0-or-later", cov.Match)
+	}
+
+	_, err = NewScanner([]License{
+		{ID: "GPL-2.0-or-later", LRE: "this is a custom license"},
+		{ID: "GPL-2.0+", LRE: "this is another custom license"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "are both GPL-2.0-or-later") {
+		t.Errorf("NewScanner with duplicate alias: err = %v, want duplicate error", err)
+	}
+}
+
+var spdxLicenseIDTests = []struct {
+	id     string
+	isSPDX bool
+	spdx   string
+}{
+	{"MIT", true, "MIT"},
+	{"gpl-2.0", true, "gpl-2.0"},
+	{"GPL-2.0+", true, "GPL-2.0+"},
+	{"MIT-NoAd", false, "LicenseRef-MIT-NoAd"},
+	{"GooglePatentsFile", false, "LicenseRef-GooglePatentsFile"},
+	{"BSD-0-Clause", false, "LicenseRef-BSD-0-Clause"},
+	{"LicenseRef-Mine", false, "LicenseRef-Mine"},
+	{"My License_1", false, "LicenseRef-My-License-1"},
+}
+
+func TestSPDXLicenseID(t *testing.T) {
+	for _, tt := range spdxLicenseIDTests {
+		if is := IsSPDX(tt.id); is != tt.isSPDX {
+			t.Errorf("IsSPDX(%q) = %v, want %v", tt.id, is, tt.isSPDX)
+		}
+		if id := SPDXLicenseID(tt.id); id != tt.spdx {
+			t.Errorf("SPDXLicenseID(%q) = %q, want %q", tt.id, id, tt.spdx)
+		}
+	}
+	for _, id := range nonSPDXIDs {
+		if c, ok := CanonicalID(id); !ok || c != id {
+			t.Errorf("nonSPDXIDs: %s is not a built-in license ID", id)
+		}
+	}
+}
+
+func TestSPDXExpression(t *testing.T) {
+	for _, tt := range []struct{ in, out string }{
+		{"", ""},
+		{"MIT", "MIT"},
+		{"(MIT-NoAd OR Apache-2.0) AND Anti996", "(LicenseRef-MIT-NoAd OR Apache-2.0) AND LicenseRef-Anti996"},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0"},
+		{"NOASSERTION", "NOASSERTION"},
+	} {
+		if out := SPDXExpression(tt.in); out != tt.out {
+			t.Errorf("SPDXExpression(%q) = %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
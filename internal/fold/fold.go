@@ -0,0 +1,116 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fold implements the folding of words to a canonical case
+// and spelling, shared by package lre and licensecheck.ParseName.
+package fold
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rune returns the folded rune r.
+// It returns -1 if the rune r should be omitted entirely.
+//
+// Folding can be any canonicalizing transformation we want.
+// For now folding means:
+//   - fold to consistent case (unicode.SimpleFold, but moving to lower-case afterward)
+//   - return -1 for (drop) combining diacritical marks, such as U+0301
+//   - strip diacritics from pre-combined letters:
+//     é to e, ü to u, ł to l, etc. (for licenses and translations
+//     mentioning Québec or Commissariat à l'Energie Atomique)
+//   - map fullwidth forms, such as ｓ (U+FF53), to their ASCII equivalents
+//   - return -1 for (drop) ( and ), as in (c) or notice(s)
+//
+// The result is like Unicode NFKD normalization followed by removing
+// the combining marks, but limited to the scripts listed in base
+// and to the compatibility mappings for fullwidth forms and, in AppendRune,
+// the ligatures listed in expand, such as ﬁ and æ. It is not full NFKC:
+// other compatibility characters, such as superscripts, circled letters,
+// and halfwidth katakana, are left unchanged.
+func Rune(r rune) rune {
+	if 0xFF01 <= r && r <= 0xFF5E {
+		// Fullwidth ASCII variants, as in CJK text.
+		r -= 0xFF01 - '!'
+	}
+	if r < utf8.RuneSelf {
+		if 'A' <= r && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+		if r == '(' || r == ')' {
+			// delete ( ) in (c) or notice(s)
+			return -1
+		}
+		return r
+	}
+
+	// Iterate SimpleFold until we hit the min equivalent rune,
+	// which - for the ones we care about - is the upper case rune.
+	for {
+		r1 := unicode.SimpleFold(r)
+		if r1 >= r {
+			break
+		}
+		r = r1
+	}
+	if r < utf8.RuneSelf {
+		// For example, K (U+212A KELVIN SIGN) folds to K.
+		return Rune(r)
+	}
+	if isCombiningMark(r) {
+		return -1
+	}
+	if b, ok := base[r]; ok {
+		return b
+	}
+	return unicode.ToLower(r)
+}
+
+// String returns the folded form of s,
+// as used for the words in a Dict.
+func String(s string) string {
+	var buf []byte
+	for _, r := range s {
+		buf = AppendRune(buf, r)
+	}
+	return string(buf)
+}
+
+// AppendRune appends Rune(r) to buf and returns the updated buffer.
+// If r is a ligature listed in expand, it appends the expansion instead.
+func AppendRune(buf []byte, r rune) []byte {
+	if r >= utf8.RuneSelf {
+		if x, ok := expand[r]; ok {
+			return append(buf, x...)
+		}
+	}
+	r = Rune(r)
+	if r < 0 {
+		return buf
+	}
+	if r < utf8.RuneSelf {
+		return append(buf, byte(r))
+	}
+
+	n := len(buf)
+	s := utf8.RuneLen(r)
+	for cap(buf) < n+s {
+		buf = append(buf[:cap(buf)], 0)
+	}
+	buf = buf[:n+s]
+	utf8.EncodeRune(buf[n:], r)
+	return buf
+}
+
+// isCombiningMark reports whether r is a combining diacritical mark,
+// which folding removes, so that decomposed and precomposed
+// accented letters fold the same.
+func isCombiningMark(r rune) bool {
+	return 0x0300 <= r && r <= 0x036F || // Combining Diacritical Marks
+		0x1AB0 <= r && r <= 0x1AFF || // Combining Diacritical Marks Extended
+		0x1DC0 <= r && r <= 0x1DFF || // Combining Diacritical Marks Supplement
+		0x20D0 <= r && r <= 0x20FF || // Combining Diacritical Marks for Symbols
+		0xFE20 <= r && r <= 0xFE2F // Combining Half Marks
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Unicode folding tables.
+
+package fold
+
+// base maps letters with diacritics to their lower-case base letters.
+// It lists the Latin, Greek, and Cyrillic letters whose canonical
+// decomposition (as of Unicode 14.0) is a base letter followed by
+// combining marks, along with the letters with strokes, such as ø and ł,
+// that have no decomposition but are commonly written without the stroke.
+var base = map[rune]rune{
+	// Latin-1 Supplement, Latin Extended-A and -B
+	'À': 'a', 'Á': 'a', 'Â': 'a', 'Ã': 'a', 'Ä': 'a', 'Å': 'a', 'Ç': 'c', 'È': 'e',
+	'É': 'e', 'Ê': 'e', 'Ë': 'e', 'Ì': 'i', 'Í': 'i', 'Î': 'i', 'Ï': 'i', 'Ñ': 'n',
+	'Ò': 'o', 'Ó': 'o', 'Ô': 'o', 'Õ': 'o', 'Ö': 'o', 'Ø': 'o', 'Ù': 'u', 'Ú': 'u',
+	'Û': 'u', 'Ü': 'u', 'Ý': 'y', 'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a',
+	'å': 'a', 'ç': 'c', 'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ì': 'i', 'í': 'i',
+	'î': 'i', 'ï': 'i', 'ñ': 'n', 'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o',
+	'ø': 'o', 'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ý': 'y', 'ÿ': 'y', 'Ā': 'a',
+	'ā': 'a', 'Ă': 'a', 'ă': 'a', 'Ą': 'a', 'ą': 'a', 'Ć': 'c', 'ć': 'c', 'Ĉ': 'c',
+	'ĉ': 'c', 'Ċ': 'c', 'ċ': 'c', 'Č': 'c', 'č': 'c', 'Ď': 'd', 'ď': 'd', 'Đ': 'd',
+	'đ': 'd', 'Ē': 'e', 'ē': 'e', 'Ĕ': 'e', 'ĕ': 'e', 'Ė': 'e', 'ė': 'e', 'Ę': 'e',
+	'ę': 'e', 'Ě': 'e', 'ě': 'e', 'Ĝ': 'g', 'ĝ': 'g', 'Ğ': 'g', 'ğ': 'g', 'Ġ': 'g',
+	'ġ': 'g', 'Ģ': 'g', 'ģ': 'g', 'Ĥ': 'h', 'ĥ': 'h', 'Ħ': 'h', 'ħ': 'h', 'Ĩ': 'i',
+	'ĩ': 'i', 'Ī': 'i', 'ī': 'i', 'Ĭ': 'i', 'ĭ': 'i', 'Į': 'i', 'į': 'i', 'İ': 'i',
+	'ı': 'i', 'Ĵ': 'j', 'ĵ': 'j', 'Ķ': 'k', 'ķ': 'k', 'Ĺ': 'l', 'ĺ': 'l', 'Ļ': 'l',
+	'ļ': 'l', 'Ľ': 'l', 'ľ': 'l', 'Ł': 'l', 'ł': 'l', 'Ń': 'n', 'ń': 'n', 'Ņ': 'n',
+	'ņ': 'n', 'Ň': 'n', 'ň': 'n', 'Ō': 'o', 'ō': 'o', 'Ŏ': 'o', 'ŏ': 'o', 'Ő': 'o',
+	'ő': 'o', 'Ŕ': 'r', 'ŕ': 'r', 'Ŗ': 'r', 'ŗ': 'r', 'Ř': 'r', 'ř': 'r', 'Ś': 's',
+	'ś': 's', 'Ŝ': 's', 'ŝ': 's', 'Ş': 's', 'ş': 's', 'Š': 's', 'š': 's', 'Ţ': 't',
+	'ţ': 't', 'Ť': 't', 'ť': 't', 'Ŧ': 't', 'ŧ': 't', 'Ũ': 'u', 'ũ': 'u', 'Ū': 'u',
+	'ū': 'u', 'Ŭ': 'u', 'ŭ': 'u', 'Ů': 'u', 'ů': 'u', 'Ű': 'u', 'ű': 'u', 'Ų': 'u',
+	'ų': 'u', 'Ŵ': 'w', 'ŵ': 'w', 'Ŷ': 'y', 'ŷ': 'y', 'Ÿ': 'y', 'Ź': 'z', 'ź': 'z',
+	'Ż': 'z', 'ż': 'z', 'Ž': 'z', 'ž': 'z', 'Ɨ': 'i', 'Ơ': 'o', 'ơ': 'o', 'Ư': 'u',
+	'ư': 'u', 'Ǎ': 'a', 'ǎ': 'a', 'Ǐ': 'i', 'ǐ': 'i', 'Ǒ': 'o', 'ǒ': 'o', 'Ǔ': 'u',
+	'ǔ': 'u', 'Ǖ': 'u', 'ǖ': 'u', 'Ǘ': 'u', 'ǘ': 'u', 'Ǚ': 'u', 'ǚ': 'u', 'Ǜ': 'u',
+	'ǜ': 'u', 'Ǟ': 'a', 'ǟ': 'a', 'Ǡ': 'a', 'ǡ': 'a', 'Ǧ': 'g',
+	'ǧ': 'g', 'Ǩ': 'k', 'ǩ': 'k', 'Ǫ': 'o', 'ǫ': 'o', 'Ǭ': 'o', 'ǭ': 'o', 'Ǯ': 'ʒ',
+	'ǯ': 'ʒ', 'ǰ': 'j', 'Ǵ': 'g', 'ǵ': 'g', 'Ǹ': 'n', 'ǹ': 'n', 'Ǻ': 'a', 'ǻ': 'a',
+	'Ǿ': 'o', 'ǿ': 'o', 'Ȁ': 'a', 'ȁ': 'a', 'Ȃ': 'a', 'ȃ': 'a',
+	'Ȅ': 'e', 'ȅ': 'e', 'Ȇ': 'e', 'ȇ': 'e', 'Ȉ': 'i', 'ȉ': 'i', 'Ȋ': 'i', 'ȋ': 'i',
+	'Ȍ': 'o', 'ȍ': 'o', 'Ȏ': 'o', 'ȏ': 'o', 'Ȑ': 'r', 'ȑ': 'r', 'Ȓ': 'r', 'ȓ': 'r',
+	'Ȕ': 'u', 'ȕ': 'u', 'Ȗ': 'u', 'ȗ': 'u', 'Ș': 's', 'ș': 's', 'Ț': 't', 'ț': 't',
+	'Ȟ': 'h', 'ȟ': 'h', 'Ȧ': 'a', 'ȧ': 'a', 'Ȩ': 'e', 'ȩ': 'e', 'Ȫ': 'o', 'ȫ': 'o',
+	'Ȭ': 'o', 'ȭ': 'o', 'Ȯ': 'o', 'ȯ': 'o', 'Ȱ': 'o', 'ȱ': 'o', 'Ȳ': 'y', 'ȳ': 'y',
+	// Greek
+	'Ά': 'α', 'Έ': 'ε', 'Ή': 'η', 'Ί': 'ι', 'Ό': 'ο', 'Ύ': 'υ', 'Ώ': 'ω', 'ΐ': 'ι',
+	'Ϊ': 'ι', 'Ϋ': 'υ', 'ά': 'α', 'έ': 'ε', 'ή': 'η', 'ί': 'ι', 'ΰ': 'υ', 'ϊ': 'ι',
+	'ϋ': 'υ', 'ό': 'ο', 'ύ': 'υ', 'ώ': 'ω', 'ϓ': 'ϒ', 'ϔ': 'ϒ',
+	// Cyrillic
+	'Ѐ': 'е', 'Ё': 'е', 'Ѓ': 'г', 'Ї': 'і', 'Ќ': 'к', 'Ѝ': 'и', 'Ў': 'у', 'Й': 'и',
+	'й': 'и', 'ѐ': 'е', 'ё': 'е', 'ѓ': 'г', 'ї': 'і', 'ќ': 'к', 'ѝ': 'и', 'ў': 'у',
+	'Ѷ': 'ѵ', 'ѷ': 'ѵ', 'Ӂ': 'ж', 'ӂ': 'ж', 'Ӑ': 'а', 'ӑ': 'а', 'Ӓ': 'а', 'ӓ': 'а',
+	'Ӗ': 'е', 'ӗ': 'е', 'Ӛ': 'ә', 'ӛ': 'ә', 'Ӝ': 'ж', 'ӝ': 'ж', 'Ӟ': 'з', 'ӟ': 'з',
+	'Ӣ': 'и', 'ӣ': 'и', 'Ӥ': 'и', 'ӥ': 'и', 'Ӧ': 'о', 'ӧ': 'о', 'Ӫ': 'ө', 'ӫ': 'ө',
+	'Ӭ': 'э', 'ӭ': 'э', 'Ӯ': 'у', 'ӯ': 'у', 'Ӱ': 'у', 'ӱ': 'у', 'Ӳ': 'у', 'ӳ': 'у',
+	'Ӵ': 'ч', 'ӵ': 'ч', 'Ӹ': 'ы', 'ӹ': 'ы',
+	// Latin Extended Additional
+	'Ḁ': 'a', 'ḁ': 'a', 'Ḃ': 'b', 'ḃ': 'b', 'Ḅ': 'b', 'ḅ': 'b', 'Ḇ': 'b', 'ḇ': 'b',
+	'Ḉ': 'c', 'ḉ': 'c', 'Ḋ': 'd', 'ḋ': 'd', 'Ḍ': 'd', 'ḍ': 'd', 'Ḏ': 'd', 'ḏ': 'd',
+	'Ḑ': 'd', 'ḑ': 'd', 'Ḓ': 'd', 'ḓ': 'd', 'Ḕ': 'e', 'ḕ': 'e', 'Ḗ': 'e', 'ḗ': 'e',
+	'Ḙ': 'e', 'ḙ': 'e', 'Ḛ': 'e', 'ḛ': 'e', 'Ḝ': 'e', 'ḝ': 'e', 'Ḟ': 'f', 'ḟ': 'f',
+	'Ḡ': 'g', 'ḡ': 'g', 'Ḣ': 'h', 'ḣ': 'h', 'Ḥ': 'h', 'ḥ': 'h', 'Ḧ': 'h', 'ḧ': 'h',
+	'Ḩ': 'h', 'ḩ': 'h', 'Ḫ': 'h', 'ḫ': 'h', 'Ḭ': 'i', 'ḭ': 'i', 'Ḯ': 'i', 'ḯ': 'i',
+	'Ḱ': 'k', 'ḱ': 'k', 'Ḳ': 'k', 'ḳ': 'k', 'Ḵ': 'k', 'ḵ': 'k', 'Ḷ': 'l', 'ḷ': 'l',
+	'Ḹ': 'l', 'ḹ': 'l', 'Ḻ': 'l', 'ḻ': 'l', 'Ḽ': 'l', 'ḽ': 'l', 'Ḿ': 'm', 'ḿ': 'm',
+	'Ṁ': 'm', 'ṁ': 'm', 'Ṃ': 'm', 'ṃ': 'm', 'Ṅ': 'n', 'ṅ': 'n', 'Ṇ': 'n', 'ṇ': 'n',
+	'Ṉ': 'n', 'ṉ': 'n', 'Ṋ': 'n', 'ṋ': 'n', 'Ṍ': 'o', 'ṍ': 'o', 'Ṏ': 'o', 'ṏ': 'o',
+	'Ṑ': 'o', 'ṑ': 'o', 'Ṓ': 'o', 'ṓ': 'o', 'Ṕ': 'p', 'ṕ': 'p', 'Ṗ': 'p', 'ṗ': 'p',
+	'Ṙ': 'r', 'ṙ': 'r', 'Ṛ': 'r', 'ṛ': 'r', 'Ṝ': 'r', 'ṝ': 'r', 'Ṟ': 'r', 'ṟ': 'r',
+	'Ṡ': 's', 'ṡ': 's', 'Ṣ': 's', 'ṣ': 's', 'Ṥ': 's', 'ṥ': 's', 'Ṧ': 's', 'ṧ': 's',
+	'Ṩ': 's', 'ṩ': 's', 'Ṫ': 't', 'ṫ': 't', 'Ṭ': 't', 'ṭ': 't', 'Ṯ': 't', 'ṯ': 't',
+	'Ṱ': 't', 'ṱ': 't', 'Ṳ': 'u', 'ṳ': 'u', 'Ṵ': 'u', 'ṵ': 'u', 'Ṷ': 'u', 'ṷ': 'u',
+	'Ṹ': 'u', 'ṹ': 'u', 'Ṻ': 'u', 'ṻ': 'u', 'Ṽ': 'v', 'ṽ': 'v', 'Ṿ': 'v', 'ṿ': 'v',
+	'Ẁ': 'w', 'ẁ': 'w', 'Ẃ': 'w', 'ẃ': 'w', 'Ẅ': 'w', 'ẅ': 'w', 'Ẇ': 'w', 'ẇ': 'w',
+	'Ẉ': 'w', 'ẉ': 'w', 'Ẋ': 'x', 'ẋ': 'x', 'Ẍ': 'x', 'ẍ': 'x', 'Ẏ': 'y', 'ẏ': 'y',
+	'Ẑ': 'z', 'ẑ': 'z', 'Ẓ': 'z', 'ẓ': 'z', 'Ẕ': 'z', 'ẕ': 'z', 'ẖ': 'h', 'ẗ': 't',
+	'ẘ': 'w', 'ẙ': 'y', 'ẛ': 'ſ', 'Ạ': 'a', 'ạ': 'a', 'Ả': 'a', 'ả': 'a', 'Ấ': 'a',
+	'ấ': 'a', 'Ầ': 'a', 'ầ': 'a', 'Ẩ': 'a', 'ẩ': 'a', 'Ẫ': 'a', 'ẫ': 'a', 'Ậ': 'a',
+	'ậ': 'a', 'Ắ': 'a', 'ắ': 'a', 'Ằ': 'a', 'ằ': 'a', 'Ẳ': 'a', 'ẳ': 'a', 'Ẵ': 'a',
+	'ẵ': 'a', 'Ặ': 'a', 'ặ': 'a', 'Ẹ': 'e', 'ẹ': 'e', 'Ẻ': 'e', 'ẻ': 'e', 'Ẽ': 'e',
+	'ẽ': 'e', 'Ế': 'e', 'ế': 'e', 'Ề': 'e', 'ề': 'e', 'Ể': 'e', 'ể': 'e', 'Ễ': 'e',
+	'ễ': 'e', 'Ệ': 'e', 'ệ': 'e', 'Ỉ': 'i', 'ỉ': 'i', 'Ị': 'i', 'ị': 'i', 'Ọ': 'o',
+	'ọ': 'o', 'Ỏ': 'o', 'ỏ': 'o', 'Ố': 'o', 'ố': 'o', 'Ồ': 'o', 'ồ': 'o', 'Ổ': 'o',
+	'ổ': 'o', 'Ỗ': 'o', 'ỗ': 'o', 'Ộ': 'o', 'ộ': 'o', 'Ớ': 'o', 'ớ': 'o', 'Ờ': 'o',
+	'ờ': 'o', 'Ở': 'o', 'ở': 'o', 'Ỡ': 'o', 'ỡ': 'o', 'Ợ': 'o', 'ợ': 'o', 'Ụ': 'u',
+	'ụ': 'u', 'Ủ': 'u', 'ủ': 'u', 'Ứ': 'u', 'ứ': 'u', 'Ừ': 'u', 'ừ': 'u', 'Ử': 'u',
+	'ử': 'u', 'Ữ': 'u', 'ữ': 'u', 'Ự': 'u', 'ự': 'u', 'Ỳ': 'y', 'ỳ': 'y', 'Ỵ': 'y',
+	'ỵ': 'y', 'Ỷ': 'y', 'ỷ': 'y', 'Ỹ': 'y', 'ỹ': 'y',
+	// Greek Extended
+	'ἀ': 'α', 'ἁ': 'α', 'ἂ': 'α', 'ἃ': 'α', 'ἄ': 'α', 'ἅ': 'α', 'ἆ': 'α', 'ἇ': 'α',
+	'Ἀ': 'α', 'Ἁ': 'α', 'Ἂ': 'α', 'Ἃ': 'α', 'Ἄ': 'α', 'Ἅ': 'α', 'Ἆ': 'α', 'Ἇ': 'α',
+	'ἐ': 'ε', 'ἑ': 'ε', 'ἒ': 'ε', 'ἓ': 'ε', 'ἔ': 'ε', 'ἕ': 'ε', 'Ἐ': 'ε', 'Ἑ': 'ε',
+	'Ἒ': 'ε', 'Ἓ': 'ε', 'Ἔ': 'ε', 'Ἕ': 'ε', 'ἠ': 'η', 'ἡ': 'η', 'ἢ': 'η', 'ἣ': 'η',
+	'ἤ': 'η', 'ἥ': 'η', 'ἦ': 'η', 'ἧ': 'η', 'Ἠ': 'η', 'Ἡ': 'η', 'Ἢ': 'η', 'Ἣ': 'η',
+	'Ἤ': 'η', 'Ἥ': 'η', 'Ἦ': 'η', 'Ἧ': 'η', 'ἰ': 'ι', 'ἱ': 'ι', 'ἲ': 'ι', 'ἳ': 'ι',
+	'ἴ': 'ι', 'ἵ': 'ι', 'ἶ': 'ι', 'ἷ': 'ι', 'Ἰ': 'ι', 'Ἱ': 'ι', 'Ἲ': 'ι', 'Ἳ': 'ι',
+	'Ἴ': 'ι', 'Ἵ': 'ι', 'Ἶ': 'ι', 'Ἷ': 'ι', 'ὀ': 'ο', 'ὁ': 'ο', 'ὂ': 'ο', 'ὃ': 'ο',
+	'ὄ': 'ο', 'ὅ': 'ο', 'Ὀ': 'ο', 'Ὁ': 'ο', 'Ὂ': 'ο', 'Ὃ': 'ο', 'Ὄ': 'ο', 'Ὅ': 'ο',
+	'ὐ': 'υ', 'ὑ': 'υ', 'ὒ': 'υ', 'ὓ': 'υ', 'ὔ': 'υ', 'ὕ': 'υ', 'ὖ': 'υ', 'ὗ': 'υ',
+	'Ὑ': 'υ', 'Ὓ': 'υ', 'Ὕ': 'υ', 'Ὗ': 'υ', 'ὠ': 'ω', 'ὡ': 'ω', 'ὢ': 'ω', 'ὣ': 'ω',
+	'ὤ': 'ω', 'ὥ': 'ω', 'ὦ': 'ω', 'ὧ': 'ω', 'Ὠ': 'ω', 'Ὡ': 'ω', 'Ὢ': 'ω', 'Ὣ': 'ω',
+	'Ὤ': 'ω', 'Ὥ': 'ω', 'Ὦ': 'ω', 'Ὧ': 'ω', 'ὰ': 'α', 'ά': 'α', 'ὲ': 'ε', 'έ': 'ε',
+	'ὴ': 'η', 'ή': 'η', 'ὶ': 'ι', 'ί': 'ι', 'ὸ': 'ο', 'ό': 'ο', 'ὺ': 'υ', 'ύ': 'υ',
+	'ὼ': 'ω', 'ώ': 'ω', 'ᾀ': 'α', 'ᾁ': 'α', 'ᾂ': 'α', 'ᾃ': 'α', 'ᾄ': 'α', 'ᾅ': 'α',
+	'ᾆ': 'α', 'ᾇ': 'α', 'ᾈ': 'α', 'ᾉ': 'α', 'ᾊ': 'α', 'ᾋ': 'α', 'ᾌ': 'α', 'ᾍ': 'α',
+	'ᾎ': 'α', 'ᾏ': 'α', 'ᾐ': 'η', 'ᾑ': 'η', 'ᾒ': 'η', 'ᾓ': 'η', 'ᾔ': 'η', 'ᾕ': 'η',
+	'ᾖ': 'η', 'ᾗ': 'η', 'ᾘ': 'η', 'ᾙ': 'η', 'ᾚ': 'η', 'ᾛ': 'η', 'ᾜ': 'η', 'ᾝ': 'η',
+	'ᾞ': 'η', 'ᾟ': 'η', 'ᾠ': 'ω', 'ᾡ': 'ω', 'ᾢ': 'ω', 'ᾣ': 'ω', 'ᾤ': 'ω', 'ᾥ': 'ω',
+	'ᾦ': 'ω', 'ᾧ': 'ω', 'ᾨ': 'ω', 'ᾩ': 'ω', 'ᾪ': 'ω', 'ᾫ': 'ω', 'ᾬ': 'ω', 'ᾭ': 'ω',
+	'ᾮ': 'ω', 'ᾯ': 'ω', 'ᾰ': 'α', 'ᾱ': 'α', 'ᾲ': 'α', 'ᾳ': 'α', 'ᾴ': 'α', 'ᾶ': 'α',
+	'ᾷ': 'α', 'Ᾰ': 'α', 'Ᾱ': 'α', 'Ὰ': 'α', 'Ά': 'α', 'ᾼ': 'α', 'ῂ': 'η', 'ῃ': 'η',
+	'ῄ': 'η', 'ῆ': 'η', 'ῇ': 'η', 'Ὲ': 'ε', 'Έ': 'ε', 'Ὴ': 'η', 'Ή': 'η', 'ῌ': 'η',
+	'ῐ': 'ι', 'ῑ': 'ι', 'ῒ': 'ι', 'ΐ': 'ι', 'ῖ': 'ι', 'ῗ': 'ι', 'Ῐ': 'ι', 'Ῑ': 'ι',
+	'Ὶ': 'ι', 'Ί': 'ι', 'ῠ': 'υ', 'ῡ': 'υ', 'ῢ': 'υ', 'ΰ': 'υ', 'ῤ': 'ρ', 'ῥ': 'ρ',
+	'ῦ': 'υ', 'ῧ': 'υ', 'Ῠ': 'υ', 'Ῡ': 'υ', 'Ὺ': 'υ', 'Ύ': 'υ', 'Ῥ': 'ρ', 'ῲ': 'ω',
+	'ῳ': 'ω', 'ῴ': 'ω', 'ῶ': 'ω', 'ῷ': 'ω', 'Ὸ': 'ο', 'Ό': 'ο', 'Ὼ': 'ω', 'Ώ': 'ω',
+	'ῼ': 'ω',
+}
+
+// expand maps ligatures and similar letters to the letter
+// sequences they are commonly written as, including the ligatures
+// with diacritics, such as ǽ, which fold like the plain ligature.
+var expand = map[rune]string{
+	'Æ': "ae", 'æ': "ae", 'Ǣ': "ae", 'ǣ': "ae", 'Ǽ': "ae", 'ǽ': "ae",
+	'Œ': "oe", 'œ': "oe", 'Ĳ': "ij", 'ĳ': "ij",
+	'ß': "ss", 'ẞ': "ss",
+	'ﬀ': "ff", 'ﬁ': "fi", 'ﬂ': "fl", 'ﬃ': "ffi", 'ﬄ': "ffl", 'ﬅ': "st", 'ﬆ': "st",
+}
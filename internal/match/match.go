@@ -0,0 +1,40 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package match is the former home of package lre.
+// It remains only as a set of aliases for existing internal users;
+// new code should use package lre directly.
+package match
+
+import "github.com/google/licensecheck/lre"
+
+type (
+	Dict        = lre.Dict
+	WordID      = lre.WordID
+	Word        = lre.Word
+	LRE         = lre.LRE
+	MultiLRE    = lre.MultiLRE
+	Matches     = lre.Matches
+	Match       = lre.Match
+	Diagnostic  = lre.Diagnostic
+	SyntaxError = lre.SyntaxError
+)
+
+const (
+	BadWord = lre.BadWord
+	AnyWord = lre.AnyWord
+
+	DeadAlternative   = lre.DeadAlternative
+	DuplicateOptional = lre.DuplicateOptional
+	DelayedCut        = lre.DelayedCut
+	DFAStates         = lre.DFAStates
+	StartCollision    = lre.StartCollision
+)
+
+var (
+	ParseLRE    = lre.ParseLRE
+	NewMultiLRE = lre.NewMultiLRE
+	Format      = lre.Format
+	Lint        = lre.Lint
+)
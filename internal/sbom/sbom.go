@@ -0,0 +1,206 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sbom collects the per-file information shared by
+// the software bill of materials formats (spdx, cyclonedx).
+package sbom
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/vcs"
+)
+
+// A File describes a single file in a scanned tree.
+type File struct {
+	Path       string // slash-separated path in the file system
+	Size       int64  // size in bytes
+	SHA1       string // hex SHA-1 of content
+	SHA256     string // hex SHA-256 of content
+	Copyrights []string
+
+	// Coverage is the license scan result for the file.
+	// It is the zero Coverage for files with no licenses.
+	licensecheck.Coverage
+
+	// Texts holds the matched text for each entry in Coverage.Match.
+	Texts []string
+}
+
+// IDs returns the distinct license IDs matched in f, in sorted order.
+func (f *File) IDs() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, m := range f.Match {
+		if !seen[m.ID] {
+			seen[m.ID] = true
+			ids = append(ids, m.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Walk returns the regular files in the file tree rooted at root in fsys,
+// in lexical order, skipping version control metadata directories.
+// It hashes each file as it reads it, and like licensecheck.ScanFS,
+// it extracts copyright notices only from files of at most 4 MB.
+// The license information comes from scan, which should be the result of
+// licensecheck.ScanFS (or a Scanner's ScanFS method) for the same tree.
+// If scan is nil, Walk calls licensecheck.ScanFS itself.
+func Walk(fsys fs.FS, root string, scan []licensecheck.FileCoverage) ([]*File, error) {
+	if scan == nil {
+		var err error
+		scan, err = licensecheck.ScanFS(fsys, root)
+		if err != nil {
+			return nil, err
+		}
+	}
+	cov := make(map[string]licensecheck.Coverage)
+	for _, f := range scan {
+		cov[f.Path] = f.Coverage
+	}
+
+	var files []*File
+	err := fs.WalkDir(fsys, root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if file != root && vcs.IsMetadataDir(path.Base(file)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		h1, h256 := sha1.New(), sha256.New()
+		head := &prefixWriter{max: maxFile}
+		size, err := copyFile(io.MultiWriter(h1, h256, head), fsys, file)
+		if err != nil {
+			return err
+		}
+		f := &File{
+			Path:     file,
+			Size:     size,
+			SHA1:     hex.EncodeToString(h1.Sum(nil)),
+			SHA256:   hex.EncodeToString(h256.Sum(nil)),
+			Coverage: cov[file],
+		}
+		var data []byte
+		if size <= maxFile {
+			data = head.buf
+			if _, ok := cov[file]; ok || !isBinary(data) {
+				f.Copyrights = Copyrights(data)
+			}
+		}
+		for _, m := range f.Match {
+			text := ""
+			if 0 <= m.Start && m.Start <= m.End && m.End <= len(data) {
+				text = string(data[m.Start:m.End])
+			}
+			f.Texts = append(f.Texts, text)
+		}
+		files = append(files, f)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// maxFile is the maximum size of a file from which Walk extracts
+// copyright notices and matched texts, the same as licensecheck.ScanFS's limit.
+const maxFile = 4 << 20
+
+// copyFile copies the content of file in fsys to w
+// and returns the number of bytes copied.
+func copyFile(w io.Writer, fsys fs.FS, file string) (int64, error) {
+	f, err := fsys.Open(file)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	return io.Copy(w, f)
+}
+
+// A prefixWriter is an io.Writer that keeps the first max bytes written
+// and discards the rest.
+type prefixWriter struct {
+	buf []byte
+	max int
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	if n := w.max - len(w.buf); n > 0 {
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf = append(w.buf, p[:n]...)
+	}
+	return len(p), nil
+}
+
+// isBinary reports whether data appears to be binary,
+// using the same test as licensecheck.ScanFS.
+func isBinary(data []byte) bool {
+	if len(data) > 8<<10 {
+		data = data[:8<<10]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+var (
+	// copyrightRE matches a copyright line, after comment markers are removed.
+	copyrightRE = regexp.MustCompile(`(?i)^(copyright\b|\(c\)|©)`)
+
+	// copyrightYearRE matches the evidence that a copyright line is a notice
+	// and not prose about copyright: a year or a copyright symbol.
+	copyrightYearRE = regexp.MustCompile(`(?i)\b(19|20)[0-9][0-9]\b|\(c\)|©`)
+
+	// copyrightTemplateRE matches placeholders in copyright notice templates,
+	// like the "Copyright (C) <year> <name of author>" in the GPL.
+	copyrightTemplateRE = regexp.MustCompile(`(?i)<[a-z ,]+>|\[(yyyy|year)[^\]]*\]|\byyyy\b|{{`)
+)
+
+// maxCopyrightLine is the maximum length of an extracted copyright line.
+const maxCopyrightLine = 200
+
+// Copyrights returns the distinct copyright notices in text, in order.
+// A copyright notice is a line beginning with "Copyright", "(c)", or "©"
+// (possibly after comment markers) and mentioning a year or copyright symbol.
+func Copyrights(text []byte) []string {
+	var list []string
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(text), "\n") {
+		line = strings.TrimLeft(line, " \t#*/;!%\"'-<>")
+		if !copyrightRE.MatchString(line) {
+			continue
+		}
+		line = strings.TrimSpace(line)
+		line = strings.TrimSuffix(line, "-->")
+		line = strings.TrimSuffix(line, "*/")
+		line = strings.Join(strings.Fields(line), " ")
+		if len(line) > maxCopyrightLine || !copyrightYearRE.MatchString(line) || copyrightTemplateRE.MatchString(line) {
+			continue
+		}
+		if !seen[line] {
+			seen[line] = true
+			list = append(list, line)
+		}
+	}
+	return list
+}
@@ -0,0 +1,67 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sbom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+)
+
+var copyrightsTests = []struct {
+	in  string
+	out []string
+}{
+	{"Copyright 2020 The Go Authors. All rights reserved.\n", []string{"Copyright 2020 The Go Authors. All rights reserved."}},
+	{"// Copyright (c) 2019  Someone\n/* Copyright 2018 Else */\n", []string{"Copyright (c) 2019 Someone", "Copyright 2018 Else"}},
+	{"# © Foo Corp\n<!-- copyright 2001 Bar -->\n", []string{"© Foo Corp", "copyright 2001 Bar"}},
+	{" * (C) 1999 Baz\n * (C) 1999 Baz\n", []string{"(C) 1999 Baz"}},
+	{"copyright notice and this permission notice shall be included\n", nil},
+	{"Copyright (C) <year>  <name of author>\n", nil},
+	{"Copyright [yyyy] [name of copyright owner]\n", nil},
+	{"The above copyright 2020 line does not start with copyright.\n", nil},
+}
+
+func TestCopyrights(t *testing.T) {
+	for _, tt := range copyrightsTests {
+		if out := Copyrights([]byte(tt.in)); !reflect.DeepEqual(out, tt.out) {
+			t.Errorf("Copyrights(%q) = %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
+
+func TestWalk(t *testing.T) {
+	notice := "Copyright 2020 The Go Authors.\n"
+	big := notice + string(bytes.Repeat([]byte("x"), maxFile))
+	fsys := fstest.MapFS{
+		"big.txt":   {Data: []byte(big)},
+		"small.txt": {Data: []byte(notice)},
+	}
+	files, err := Walk(fsys, ".", []licensecheck.FileCoverage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Walk returned %d files, want 2", len(files))
+	}
+	for i, text := range []string{big, notice} {
+		f := files[i]
+		sum := sha256.Sum256([]byte(text))
+		if f.Size != int64(len(text)) || f.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("%s: Size=%d SHA256=%s, want %d %x", f.Path, f.Size, f.SHA256, len(text), sum)
+		}
+	}
+	if files[0].Copyrights != nil {
+		t.Errorf("%s: Copyrights = %q, want none in file over %d bytes", files[0].Path, files[0].Copyrights, maxFile)
+	}
+	if want := []string{"Copyright 2020 The Go Authors."}; !reflect.DeepEqual(files[1].Copyrights, want) {
+		t.Errorf("%s: Copyrights = %q, want %q", files[1].Path, files[1].Copyrights, want)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package textpos converts byte offsets in a text,
+// such as the Start and End of a licensecheck.Match,
+// into line and column positions.
+package textpos
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// A Position is a line and column position in a text.
+// Both are 1-based. The column counts Unicode code points,
+// not bytes, from the start of the line.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// A Table maps byte offsets in a single text to positions.
+type Table struct {
+	text  []byte
+	lines []int // byte offset of the start of each line
+}
+
+// NewTable returns a Table for the given text.
+// Lines end at "\n", "\r\n", or a lone "\r".
+// The Table refers to text, which must not be modified.
+func NewTable(text []byte) *Table {
+	t := &Table{text: text, lines: []int{0}}
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '\r':
+			if i+1 < len(text) && text[i+1] == '\n' {
+				i++
+			}
+			t.lines = append(t.lines, i+1)
+		case '\n':
+			t.lines = append(t.lines, i+1)
+		}
+	}
+	return t
+}
+
+// Position returns the position of the byte offset off.
+// Offsets outside the text are clamped to its start or end.
+// An offset in the middle of a "\r\n" or of a UTF-8 sequence
+// is reported as the position of the byte where that sequence starts.
+func (t *Table) Position(off int) Position {
+	if off < 0 {
+		off = 0
+	}
+	if off > len(t.text) {
+		off = len(t.text)
+	}
+	for off > 0 && off < len(t.text) && !utf8.RuneStart(t.text[off]) {
+		off--
+	}
+	if off > 0 && off < len(t.text) && t.text[off] == '\n' && t.text[off-1] == '\r' {
+		off--
+	}
+
+	// Find the last line starting at or before off.
+	line := sort.Search(len(t.lines), func(i int) bool { return t.lines[i] > off }) - 1
+	col := utf8.RuneCount(t.text[t.lines[line]:off])
+	return Position{Line: line + 1, Column: col + 1}
+}
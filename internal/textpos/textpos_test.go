@@ -0,0 +1,40 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package textpos
+
+import "testing"
+
+var positionTests = []struct {
+	text string
+	off  int
+	pos  Position
+}{
+	{"", 0, Position{1, 1}},
+	{"", 5, Position{1, 1}},
+	{"abc", -1, Position{1, 1}},
+	{"abc", 2, Position{1, 3}},
+	{"abc", 3, Position{1, 4}},
+	{"abc\ndef", 3, Position{1, 4}},
+	{"abc\ndef", 4, Position{2, 1}},
+	{"abc\ndef", 6, Position{2, 3}},
+	{"abc\r\ndef", 4, Position{1, 4}},
+	{"abc\r\ndef", 5, Position{2, 1}},
+	{"abc\rdef", 4, Position{2, 1}},
+	{"a\n\nb", 3, Position{3, 1}},
+	{"héllo", 3, Position{1, 3}},
+	{"héllo", 2, Position{1, 2}},
+	{"x\n世界", 5, Position{2, 2}},
+	{"x\n世界", 6, Position{2, 2}},
+	{"x\n世界", 8, Position{2, 3}},
+}
+
+func TestPosition(t *testing.T) {
+	for _, tt := range positionTests {
+		pos := NewTable([]byte(tt.text)).Position(tt.off)
+		if pos != tt.pos {
+			t.Errorf("NewTable(%q).Position(%d) = %v, want %v", tt.text, tt.off, pos, tt.pos)
+		}
+	}
+}
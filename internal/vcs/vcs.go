@@ -0,0 +1,18 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package vcs identifies version control metadata directories,
+// which licensecheck.ScanFS, package audit, and package sbom
+// all skip when walking a file tree.
+package vcs
+
+// IsMetadataDir reports whether name, the base name of a directory,
+// is a version control metadata directory (.git, .hg, .svn, or .bzr).
+func IsMetadataDir(name string) bool {
+	switch name {
+	case ".git", ".hg", ".svn", ".bzr":
+		return true
+	}
+	return false
+}
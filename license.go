@@ -23,10 +23,27 @@
 // Some matches report finding a known URL rather than complete license text.
 // (See licenses/README.md for details about the license set.)
 //
+// ScanWithOptions scans with non-default settings. For example,
+// it can transcode UTF-16 or Latin-1 text, strip the markup from RTF
+// documents or manual pages, or limit the scan of a source file to the
+// text of its comments, using the extract package, while still reporting
+// offsets in the original file.
+//
+// ScanDocument scans the text of a word processing document,
+// such as a license agreement distributed as a .docx or .odt file,
+// reporting the positions of matches as paragraph and character indexes.
+//
+// ScanFS scans the files in a file tree. ScanArchive and ScanArchiveStream
+// scan the license files in a zip or tar archive, such as a release tarball
+// or a jar file, without unpacking it to disk, and ScanFSWithOptions can
+// descend into the archives in a file tree the same way.
+//
 // A custom scanner can be created using NewScanner, passing in a set of license
 // patterns to scan for. The license patterns are written as license regular
 // expressions (LREs).
 // BuiltinLicenses returns the set of license patterns used by Scan.
+// LoadLicenses loads license patterns from template files written
+// like the built-in ones, which can reuse the built-in templates.
 //
 // License Regular Expressions
 //
@@ -40,14 +57,19 @@
 //  - __N__, any sequence of up to N words
 //  - expr1 expr2, concatenation of two expressions
 //  - expr1 || expr2, alternation of two expressions
+//  - expr1 && expr2, both expressions, in either order (at most 5 in a group)
 //  - (( expr )), grouping
 //  - (( expr ))??, zero or one instances of the grouped expression
+//  - (( expr )){m,n}, between m and n instances of the grouped expression (n at most 10)
+//  - (( expr )){n}, exactly n instances of the grouped expression
+//  - (( phrase1 || phrase2 ))!!, phrases that must not appear in the match
+//    or in the 20 words after it
 //  - //** text **//, a comment ignored by the parser
 //
 // To make patterns harder to misread in large texts:
 // (( must only appear at the start of a line (possibly indented);
-// )) and ))?? must only appear at the end of a line (with possible trailing spaces);
-// and || must only appear inside a (( )) or (( ))?? group.
+// )), ))??, ))!!, and )){m,n} must only appear at the end of a line (with possible trailing spaces);
+// and || and && must only appear inside a (( )) or (( ))?? group, not both in the same group.
 //
 // For example:
 //
@@ -58,6 +80,9 @@
 // 	((men || women || people))
 // 	to come to the aid of their __1__.
 //
+// The package lre underneath this one provides the LRE parser and matcher
+// directly, for recognizing texts other than licenses.
+//
 // The old Cover and Checker API
 //
 // An older, less precise matcher using the names Cover, New, and Checker
@@ -87,6 +112,14 @@ type License struct {
 	Type Type   // reported license type
 	LRE  string // license regular expression (see licenses/README.md)
 	URL  string // identifying URL
+
+	// Language is the BCP 47 tag of the language of the license text
+	// matched by LRE, such as "de" or "pt-BR", if the LRE matches
+	// a translation of the license identified by ID.
+	// It is empty for the original (usually English) text.
+	// The built-in licenses include no translations;
+	// custom ones can be loaded with LoadLicenses.
+	Language string
 }
 
 // Coverage describes how the text matches various licenses.
@@ -100,6 +133,18 @@ type Coverage struct {
 	// but if the input text is a concatenation of licenses it will contain
 	// a match value for each element of the concatenation.
 	Match []Match
+
+	// Corrections lists, in sequential order, the misspelled words
+	// accepted in the license text matches (see ScanOptions).
+	Corrections []Correction
+}
+
+// A Correction describes a misspelling accepted in a license text match:
+// the input text[Start:End] was read as Want.
+type Correction struct {
+	Start int    // Start offset of misspelled text.
+	End   int    // End offset of misspelled text.
+	Want  string // Expected word, or words separated by spaces.
 }
 
 // Match describes how a section of the input matches a license.
@@ -112,6 +157,10 @@ type Match struct {
 	Start int    // Start offset of match in text; match is at text[Start:End].
 	End   int    // End offset of match in text.
 	IsURL bool   // Whether match is a URL.
+
+	// Language is the language of the matched translation of the license
+	// (see License.Language), or empty for the original text or a URL.
+	Language string
 }
 
 // Type is a bit set describing the requirements imposed by a license or group of
@@ -0,0 +1,158 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// LoadLicenses returns the licenses defined by the .lre files in fsys
+// matching pattern (see fs.Glob), sorted by ID and then Language.
+//
+// The files are text/template input generating LRE output,
+// written the same way as the built-in license files in this
+// repository's licenses directory (see licenses/README.md).
+// Each file name.lre defines the license with ID name,
+// as do any {{define "name.lre"}} blocks in the files.
+// A name of the form id.lang, where lang is a BCP 47 language tag
+// such as "de" or "pt-BR", defines a translation of the license
+// with ID id, setting the license's Language to lang.
+// A file or block that expands to only spaces defines no license,
+// so a file can hold just {{define}} blocks for use by other files.
+//
+// The files can use the templates defined in each other
+// and in the built-in license files, such as "mit-grant" and "mit-disclaimer".
+// They can also call two functions: Type, which sets the license's Type
+// from a string accepted by ParseType, as in {{Type "Notice"}};
+// and list, which returns its arguments as a list
+// (flattening any list arguments), for passing multiple values
+// to a template, as in {{template "gfdl-header" list "1.3" "invariants" "only"}}.
+func LoadLicenses(fsys fs.FS, pattern string) ([]License, error) {
+	files, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .lre files match %s", pattern)
+	}
+
+	var typ Type
+	t, err := newLRETemplate(&typ)
+	if err != nil {
+		return nil, err
+	}
+	t, err = t.ParseFS(fsys, files...)
+	if err != nil {
+		return nil, fmt.Errorf("parsing LRE templates: %v", err)
+	}
+
+	var list []License
+	for _, t := range t.Templates() {
+		if !strings.HasSuffix(t.Name(), ".lre") {
+			continue
+		}
+		var buf bytes.Buffer
+		typ = Unknown
+		if err := t.Execute(&buf, nil); err != nil {
+			return nil, fmt.Errorf("executing %s: %v", t.Name(), err)
+		}
+		if len(bytes.TrimSpace(buf.Bytes())) == 0 {
+			// Only contained useful definitions.
+			continue
+		}
+		id, lang := splitLanguage(strings.TrimSuffix(t.Name(), ".lre"))
+		list = append(list, License{
+			ID:       id,
+			Type:     typ,
+			LRE:      buf.String(),
+			Language: lang,
+		})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].ID != list[j].ID {
+			return list[i].ID < list[j].ID
+		}
+		return list[i].Language < list[j].Language
+	})
+	return list, nil
+}
+
+// splitLanguage splits a license file name of the form id.lang,
+// where lang is a BCP 47 language tag, into id and lang.
+// If name does not end in a language tag, splitLanguage returns name, "".
+// Language tags start with two or three lower-case letters,
+// so they cannot be confused with version numbers in IDs like GPL-2.0.
+func splitLanguage(name string) (id, lang string) {
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return name, ""
+	}
+	lang = name[i+1:]
+	for j, sub := range strings.Split(lang, "-") {
+		if !isLanguageSubtag(sub, j == 0) {
+			return name, ""
+		}
+	}
+	return name[:i], lang
+}
+
+// isLanguageSubtag reports whether s is a valid BCP 47 subtag:
+// two or three lower-case letters for the first (primary language) subtag,
+// and one to eight letters or digits for the others.
+func isLanguageSubtag(s string, first bool) bool {
+	if first && (len(s) < 2 || len(s) > 3) || len(s) < 1 || len(s) > 8 {
+		return false
+	}
+	for _, c := range s {
+		if first && !('a' <= c && c <= 'z') ||
+			!('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// newLRETemplate returns a new template holding the built-in
+// LRE template definitions, with the functions available to .lre files.
+// The Type function sets *typ.
+func newLRETemplate(typ *Type) (*template.Template, error) {
+	setType := func(s string) (string, error) {
+		t, err := ParseType(s)
+		if err != nil {
+			return "", err
+		}
+		*typ = t
+		return "", nil
+	}
+	t := template.New("").Funcs(template.FuncMap{
+		"list": templateList,
+		"Type": setType,
+	})
+	t, err := t.Parse(builtinTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("parsing built-in LRE templates: %v", err)
+	}
+	return t, nil
+}
+
+// templateList returns xs, but it flattens any nested []interface{} into the main list.
+// Called from templates as "list", to pass multiple arguments to templates.
+func templateList(xs ...interface{}) []interface{} {
+	var list []interface{}
+	for _, x := range xs {
+		switch x := x.(type) {
+		case []interface{}:
+			list = append(list, x...)
+		default:
+			list = append(list, x)
+		}
+	}
+	return list
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadLicenses(t *testing.T) {
+	fsys := fstest.MapFS{
+		"lre/MyMIT.lre": {Data: []byte(`{{Type "Notice"}}
+			This is my variant of the MIT license.
+			{{template "mit-grant"}}
+			{{template "my-conditions"}}
+			{{template "mit-disclaimer"}}
+		`)},
+		"lre/defs.lre": {Data: []byte(`
+			{{define "my-conditions"}}
+				The above copyright notice shall be included in all copies.
+			{{end}}
+			{{define "Mine-1.0.lre"}}
+				{{Type "Notice|ShareChanges"}}
+				This is my own license, version 1.0.
+			{{end}}
+		`)},
+		"lre/README": {Data: []byte("not a license")},
+	}
+	list, err := LoadLicenses(fsys, "lre/*.lre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 {
+		t.Fatalf("LoadLicenses returned %d licenses, want 2: %+v", len(list), list)
+	}
+	if l := list[0]; l.ID != "Mine-1.0" || l.Type != Notice|ShareChanges || !strings.Contains(l.LRE, "my own license") {
+		t.Errorf("list[0] = %+v, want Mine-1.0", l)
+	}
+	l := list[1]
+	if l.ID != "MyMIT" || l.Type != Notice {
+		t.Errorf("list[1] = %s, %v, want MyMIT, Notice", l.ID, l.Type)
+	}
+	for _, text := range []string{"my variant", "Permission is hereby granted", "shall be included in all copies", "WITHOUT WARRANTY OF ANY KIND"} {
+		if !strings.Contains(l.LRE, text) {
+			t.Errorf("MyMIT LRE does not contain %q", text)
+		}
+	}
+	if _, err := NewScanner(list); err != nil {
+		t.Fatal(err)
+	}
+}
+
+var splitLanguageTests = []struct {
+	name, id, lang string
+}{
+	{"MIT", "MIT", ""},
+	{"GPL-2.0", "GPL-2.0", ""},
+	{"Prosperity-3.0.0", "Prosperity-3.0.0", ""},
+	{"CC-BY-3.0-AT", "CC-BY-3.0-AT", ""},
+	{"EUPL-1.2.de", "EUPL-1.2", "de"},
+	{"CC-BY-4.0.pt-BR", "CC-BY-4.0", "pt-BR"},
+	{"CECILL-2.1.fra", "CECILL-2.1", "fra"},
+	{"X.DE", "X.DE", ""},
+	{"X.d", "X.d", ""},
+	{"X.de-", "X.de-", ""},
+	{"X.de-toolongsubtag", "X.de-toolongsubtag", ""},
+}
+
+func TestSplitLanguage(t *testing.T) {
+	for _, tt := range splitLanguageTests {
+		id, lang := splitLanguage(tt.name)
+		if id != tt.id || lang != tt.lang {
+			t.Errorf("splitLanguage(%q) = %q, %q, want %q, %q", tt.name, id, lang, tt.id, tt.lang)
+		}
+	}
+}
+
+func TestLoadLicensesLanguage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Mine-1.0.lre":    {Data: []byte("{{Type \"Notice\"}} This is my own license, version 1.0.")},
+		"Mine-1.0.de.lre": {Data: []byte("{{Type \"Notice\"}} Dies ist meine eigene Lizenz, Version 1.0.")},
+	}
+	list, err := LoadLicenses(fsys, "*.lre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != "Mine-1.0" || list[0].Language != "" || list[1].ID != "Mine-1.0" || list[1].Language != "de" {
+		t.Fatalf("LoadLicenses = %+v, want Mine-1.0 and Mine-1.0 (de)", list)
+	}
+}
+
+func TestLoadLicensesError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad-type/X.lre":  {Data: []byte(`{{Type "Bogus"}} x y z`)},
+		"bad-parse/X.lre": {Data: []byte(`{{template "x"`)},
+		"bad-exec/X.lre":  {Data: []byte(`{{template "no-such-template"}}`)},
+	}
+	for _, tt := range []struct{ pattern, err string }{
+		{"none/*.lre", "no .lre files match none/*.lre"},
+		{"bad-type/*.lre", "executing X.lre"},
+		{"bad-parse/*.lre", "parsing LRE templates"},
+		{"bad-exec/*.lre", `template "no-such-template" not defined`},
+	} {
+		_, err := LoadLicenses(fsys, tt.pattern)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("LoadLicenses(%q) error = %v, want %q", tt.pattern, err, tt.err)
+		}
+	}
+}
+
+// TestLoadLicensesBuiltin checks that loading the license files
+// reproduces the built-in licenses.
+func TestLoadLicensesBuiltin(t *testing.T) {
+	list, err := LoadLicenses(os.DirFS("licenses"), "*.lre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != len(builtinLREs) {
+		t.Fatalf("LoadLicenses returned %d licenses, want %d", len(list), len(builtinLREs))
+	}
+	for i, l := range list {
+		b := builtinLREs[i]
+		if l.ID != b.ID || l.Type != b.Type || l.LRE != b.LRE {
+			t.Errorf("LoadLicenses()[%d] = %s (%v), want %s (%v); LREs equal: %v", i, l.ID, l.Type, b.ID, b.Type, l.LRE == b.LRE)
+		}
+	}
+}
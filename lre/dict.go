@@ -2,13 +2,16 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-// Package match defines matching algorithms and support code for the license checker.
-package match
+package lre
 
 import (
+	"fmt"
+	"sort"
 	"strings"
 	"unicode"
 	"unicode/utf8"
+
+	"github.com/google/licensecheck/internal/fold"
 )
 
 // A Dict maps words to integer indexes in a word list, of type WordID.
@@ -21,6 +24,54 @@ import (
 type Dict struct {
 	dict map[string]WordID // dict maps word to index in list
 	list []string          // list of known words
+
+	// rewrites maps the first word of each rewrite's from sequence
+	// to the rewrites starting with that word, longest first.
+	rewrites map[WordID][]rewrite
+
+	seg Segmentation // splitting of scripts written without spaces
+}
+
+// A Segmentation specifies how a Dict splits text written in scripts
+// that do not use spaces between words: Chinese (Han ideographs)
+// and Japanese (Han ideographs, Hiragana, and Katakana).
+// Korean Hangul, which is written with spaces between words,
+// is always split at spaces, the same as other scripts.
+type Segmentation int
+
+const (
+	// SegmentWords splits text only at spaces and punctuation,
+	// so that a run of Chinese or Japanese text is a single word.
+	SegmentWords Segmentation = iota
+
+	// SegmentChars splits Chinese and Japanese text into
+	// single characters, each its own word.
+	// A wildcard __N__ then matches up to N characters.
+	SegmentChars
+
+	// SegmentBigrams splits Chinese and Japanese text into
+	// overlapping pairs of adjacent characters: "ABCD" splits into
+	// the words "AB", "BC", and "CD". A character by itself is a word.
+	// Characters separated only by spaces including a line break
+	// count as adjacent, so that wrapped text splits the same as unwrapped text.
+	// A wildcard __N__ then matches up to N pairs.
+	SegmentBigrams
+)
+
+// SetSegmentation sets the way d splits Chinese and Japanese text.
+// The default is SegmentWords.
+// SetSegmentation must be called before adding any words to d.
+func (d *Dict) SetSegmentation(seg Segmentation) {
+	if len(d.list) > 0 {
+		panic("lre: SetSegmentation after Insert")
+	}
+	d.seg = seg
+}
+
+// A rewrite is a word sequence rewrite added by Dict.Rewrite.
+type rewrite struct {
+	from []WordID
+	to   []WordID
 }
 
 // A WordID is the index of a word in a dictionary.
@@ -51,6 +102,45 @@ func (d *Dict) Insert(w string) WordID {
 	return id
 }
 
+// Rewrite arranges for Split and InsertSplit to replace each occurrence
+// of the word sequence from with the word sequence to, after the built-in
+// canonicalizations (such as "(c)" to "copyright" and "these" to "the").
+// For example, after d.Rewrite("licenser", "licensor") and
+// d.Rewrite("free of charge", "free"), the texts "licenser" and "licensor"
+// split to the same words, as do "free of charge" and "free".
+// The words that replace from have the text position of all of from.
+//
+// Rewrite applies to texts split after the call, so all rewrites must
+// be added before parsing any LREs with d. Rewrites apply in a single pass,
+// using the longest from sequence that matches at each word, but the
+// to sequence is itself rewritten by the rewrites added before it.
+// Like Insert, Rewrite is a write operation.
+func (d *Dict) Rewrite(from, to string) error {
+	var r rewrite
+	for _, w := range d.splitWords(from, true) {
+		r.from = append(r.from, w.ID)
+	}
+	for _, w := range d.split(to, true) {
+		r.to = append(r.to, w.ID)
+	}
+	if len(r.from) == 0 || len(r.to) == 0 {
+		return fmt.Errorf("invalid rewrite %q -> %q: no words", from, to)
+	}
+	if d.rewrites == nil {
+		d.rewrites = make(map[WordID][]rewrite)
+	}
+	list := d.rewrites[r.from[0]]
+	for _, old := range list {
+		if equalWords(old.from, r.from) {
+			return fmt.Errorf("invalid rewrite %q -> %q: duplicate rewrite of %q", from, to, from)
+		}
+	}
+	list = append(list, r)
+	sort.SliceStable(list, func(i, j int) bool { return len(list[i].from) > len(list[j].from) })
+	d.rewrites[r.from[0]] = list
+	return nil
+}
+
 // Lookup looks for the word w in the word list and returns its index.
 // If w is not in the word list, Lookup returns BadWord.
 func (d *Dict) Lookup(w string) WordID {
@@ -90,9 +180,63 @@ func (d *Dict) Split(text string) []Word {
 // © is rewritten to this text.
 var copyright = []byte("copyright")
 
+// split splits text into words, applying any rewrites.
 func (d *Dict) split(text string, insert bool) []Word {
+	words := d.splitWords(text, insert)
+	if len(d.rewrites) > 0 {
+		words = d.rewrite(words)
+	}
+	return words
+}
+
+// rewrite applies the rewrites added by Rewrite to words.
+func (d *Dict) rewrite(words []Word) []Word {
+	var out []Word // nil until first rewrite
+Words:
+	for i := 0; i < len(words); i++ {
+		for _, r := range d.rewrites[words[i].ID] {
+			if !hasWordPrefix(words[i:], r.from) {
+				continue
+			}
+			if out == nil {
+				out = append(make([]Word, 0, len(words)), words[:i]...)
+			}
+			lo, hi := words[i].Lo, words[i+len(r.from)-1].Hi
+			for _, id := range r.to {
+				out = append(out, Word{id, lo, hi})
+			}
+			i += len(r.from) - 1 // loop will i++
+			continue Words
+		}
+		if out != nil {
+			out = append(out, words[i])
+		}
+	}
+	if out == nil {
+		return words
+	}
+	return out
+}
+
+// hasWordPrefix reports whether words begins with the word IDs in ids.
+func hasWordPrefix(words []Word, ids []WordID) bool {
+	if len(words) < len(ids) {
+		return false
+	}
+	for i, id := range ids {
+		if words[i].ID != id {
+			return false
+		}
+	}
+	return true
+}
+
+// splitWords splits text into words,
+// applying the built-in canonicalizations but not any rewrites.
+func (d *Dict) splitWords(text string, insert bool) []Word {
 	var wbuf []byte
 	var words []Word
+	bigramEnd := int32(-1) // end of last bigram, for SegmentBigrams
 	t := text
 	for t != "" {
 		var w []byte
@@ -135,7 +279,28 @@ func (d *Dict) split(text string, insert bool) []Word {
 				t = t[size:]
 				continue
 			}
-			wbuf = appendFoldRune(wbuf[:0], r)
+			wbuf = fold.AppendRune(wbuf[:0], r)
+
+			if d.seg != SegmentWords && isSegmented(r) {
+				lo = int32(len(text) - len(t))
+				hi = lo + int32(size)
+				if d.seg == SegmentBigrams {
+					if next, size2 := nextSegmented(t[size:]); size2 > 0 {
+						// Pair r with the next character.
+						r2, _ := utf8.DecodeRuneInString(t[size+next:])
+						wbuf = fold.AppendRune(wbuf, r2)
+						hi += int32(next + size2)
+						bigramEnd = hi
+					} else if bigramEnd == hi {
+						// Last character in run, already in the last pair.
+						t = t[size:]
+						continue
+					}
+				}
+				t = t[size:]
+				w = wbuf
+				goto Emit
+			}
 
 			// Scan whole word
 			// (except © which is already a word by itself,
@@ -145,11 +310,11 @@ func (d *Dict) split(text string, insert bool) []Word {
 			if r != '©' {
 				for size < len(t) {
 					r, s := utf8.DecodeRuneInString(t[size:])
-					if !isWordContinue(r) {
+					if !isWordContinue(r) || d.seg != SegmentWords && isSegmented(r) {
 						break
 					}
 					size += s
-					wbuf = appendFoldRune(wbuf, r)
+					wbuf = fold.AppendRune(wbuf, r)
 				}
 				if size+3 <= len(t) && t[size:size+3] == "(s)" {
 					// Read "notice(s)" as "notices" and let spell-check accept "notice" too.
@@ -208,82 +373,38 @@ func (d *Dict) split(text string, insert bool) []Word {
 	return words
 }
 
-// foldRune returns the folded rune r.
-// It returns -1 if the rune r should be omitted entirely.
-//
-// Folding can be any canonicalizing transformation we want.
-// For now folding means:
-//	- fold to consistent case (unicode.SimpleFold, but moving to lower-case afterward)
-//	- return -1 for (drop) combining grave and acute U+0300, U+0301
-//	- strip pre-combined graves and acutes on vowels:
-//		é to e, etc. (for Canadian or European licenses
-//		mentioning Québec or Commissariat à l'Energie Atomique)
-//
-// If necessary we could do a full Unicode-based conversion,
-// but that will require more thought about exactly what to do
-// and doing it efficiently. For now, the accents are enough.
-func foldRune(r rune) rune {
-	// Iterate SimpleFold until we hit the min equivalent rune,
-	// which - for the ones we care about - is the upper case ASCII rune.
-	for {
-		r1 := unicode.SimpleFold(r)
-		if r1 >= r {
-			break
-		}
-		r = r1
-	}
-
-	switch r {
-	case 'Á', 'À':
-		return 'a'
-	case 'É', 'È':
-		return 'e'
-	case 'Í', 'Ì':
-		return 'i'
-	case 'Ó', 'Ò':
-		return 'o'
-	case 'Ú', 'Ù':
-		return 'u'
-	}
-
-	if 'A' <= r && r <= 'Z' {
-		r += 'a' - 'A'
-	}
-	if r == '(' || r == ')' {
-		// delete ( ) in (c) or notice(s)
-		return -1
-	}
-
-	return r
+// isSegmented reports whether r is in a script split by SegmentChars and SegmentBigrams.
+func isSegmented(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
 }
 
-// toFold converts s to folded form.
-func toFold(s string) string {
-	var buf []byte
-	for _, r := range s {
-		buf = appendFoldRune(buf, r)
-	}
-	return string(buf)
+// isSegmentedWord reports whether w is a word split by SegmentChars or SegmentBigrams.
+func isSegmentedWord(w string) bool {
+	r, _ := utf8.DecodeRuneInString(w)
+	return isSegmented(r)
 }
 
-// appendFoldRune appends foldRune(r) to buf and returns the updated buffer.
-func appendFoldRune(buf []byte, r rune) []byte {
-	r = foldRune(r)
-	if r < 0 {
-		return buf
+// nextSegmented returns the offset and size of the character at the start of t,
+// or after spaces including a single line break at the start of t,
+// if it is in a script split by SegmentBigrams. Otherwise it returns 0, 0.
+// A blank line ends a paragraph, so bigrams are not formed across it.
+func nextSegmented(t string) (offset, size int) {
+	i := 0
+	nl := 0
+	for i < len(t) && (t[i] == ' ' || t[i] == '\t' || t[i] == '\r' || t[i] == '\n') {
+		if t[i] == '\n' {
+			nl++
+		}
+		i++
 	}
-	if r < utf8.RuneSelf {
-		return append(buf, byte(r))
+	if i > 0 && nl != 1 {
+		return 0, 0
 	}
-
-	n := len(buf)
-	s := utf8.RuneLen(r)
-	for cap(buf) < n+s {
-		buf = append(buf[:cap(buf)], 0)
+	r, size := utf8.DecodeRuneInString(t[i:])
+	if size == 0 || !isSegmented(r) {
+		return 0, 0
 	}
-	buf = buf[:n+s]
-	utf8.EncodeRune(buf[n:], r)
-	return buf
+	return i, size
 }
 
 // isWordStart reports whether r can appear at the start of a word.
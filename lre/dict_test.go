@@ -2,13 +2,14 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package match
+package lre
 
 import (
 	"io/ioutil"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 )
 
@@ -155,6 +156,17 @@ var insertSplitTests = []struct {
 	{"http://golang.org", "http golang org"},
 	{"https://golang.org", "http golang org"},
 	{"the notice(s) must", "the notices must"},
+
+	{"Québec QUÉBEC Que\u0301bec", "quebec quebec quebec"},
+	{"Über Gewähr ÖFFENTLICHE", "uber gewahr offentliche"},
+	{"Straße STRASSE Œuvre æther", "strasse strasse oeuvre aether"},
+	{"Łódź Øre Đakovo", "lodz ore dakovo"},
+	{"ǾRE ǿre Ǽther ǽther Ǣther ǣther", "ore ore aether aether aether aether"},
+	{"licença português", "licenca portugues"},
+	{"ﬁle ﬂag", "file flag"},
+	{"eﬀect ｓｏｆｔｗａｒｅ ＭＩＴ １２", "effect software mit 12"},
+	{"ΆΔΕΙΑ άδεια", "αδεια αδεια"},
+	{"ЛИЦЕНЗИЯ лицензия ёлка", "лицензия лицензия елка"},
 }
 
 func TestDictInsertSplit(t *testing.T) {
@@ -210,6 +222,107 @@ func TestDictSplit(t *testing.T) {
 	}
 }
 
+var rewriteTests = []struct {
+	in  string
+	out string
+}{
+	{"The Licenser grants", "the licensor grants"},
+	{"THIS organisation", "the organization"},
+	{"provided free of charge", "provided free"},
+	{"free of", "free of"},
+	{"open-source software", "open source program"},
+	{"software", "program"},
+	{"licensee", "licensee"},
+}
+
+func TestDictRewrite(t *testing.T) {
+	var d Dict
+	for _, r := range []struct{ from, to string }{
+		{"licenser", "licensor"},
+		{"organisation", "organization"},
+		{"free of charge", "free"},
+		{"software", "program"},
+		{"open source software", "open source software"},
+	} {
+		if err := d.Rewrite(r.from, r.to); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range rewriteTests {
+		words := d.InsertSplit(tt.in)
+		var out []string
+		for _, w := range words {
+			out = append(out, d.Words()[w.ID])
+		}
+		if s := strings.Join(out, " "); s != tt.out {
+			t.Errorf("InsertSplit(%q) = %q, want %q", tt.in, s, tt.out)
+		}
+	}
+
+	// Replacement words have the position of the whole rewritten text.
+	text := "is free of charge."
+	words := d.Split(text)
+	if len(words) != 2 || text[words[1].Lo:words[1].Hi] != "free of charge" {
+		t.Errorf("Split(%q) = %v, want is, free at [3:17]", text, words)
+	}
+
+	for _, r := range []struct{ from, to, err string }{
+		{"", "x", "no words"},
+		{"x", "--", "no words"},
+		{"Software", "code", "duplicate rewrite"},
+	} {
+		err := d.Rewrite(r.from, r.to)
+		if err == nil || !strings.Contains(err.Error(), r.err) {
+			t.Errorf("Rewrite(%q, %q) = %v, want %q", r.from, r.to, err, r.err)
+		}
+	}
+}
+
+var segmentTests = []struct {
+	seg Segmentation
+	in  string
+	out string
+}{
+	{SegmentWords, "本软件 按原样提供。MIT", "本软件 按原样提供 mit"},
+	{SegmentChars, "本软件 按原样提供。MIT", "本 软 件 按 原 样 提 供 mit"},
+	{SegmentChars, "See 許諾書, ひらがな カタカナ", "see 許 諾 書 ひ ら が な カ タ カ ナ"},
+	{SegmentChars, "한국어 라이선스", "한국어 라이선스"},
+	{SegmentChars, "abc中文def", "abc 中 文 def"},
+	{SegmentBigrams, "本软件按原样提供", "本软 软件 件按 按原 原样 样提 提供"},
+	{SegmentBigrams, "本软件\n  按原样", "本软 软件 件按 按原 原样"},
+	{SegmentBigrams, "本软件 按原样", "本软 软件 按原 原样"},
+	{SegmentBigrams, "本软件\n\n按原样", "本软 软件 按原 原样"},
+	{SegmentBigrams, "本软件按原样提\r\n  \r\n供", "本软 软件 件按 按原 原样 样提 供"},
+	{SegmentBigrams, "本。软件", "本 软件"},
+}
+
+func TestDictSegment(t *testing.T) {
+	for _, tt := range segmentTests {
+		var d Dict
+		d.SetSegmentation(tt.seg)
+		words := d.InsertSplit(tt.in)
+		var out []string
+		for _, w := range words {
+			out = append(out, d.Words()[w.ID])
+		}
+		if s := strings.Join(out, " "); s != tt.out {
+			t.Errorf("SetSegmentation(%d); InsertSplit(%q) = %q, want %q", tt.seg, tt.in, s, tt.out)
+		}
+	}
+
+	// Words record the byte offsets of their characters.
+	var d Dict
+	d.SetSegmentation(SegmentBigrams)
+	text := "x 中文\n字"
+	var got []string
+	for _, w := range d.InsertSplit(text) {
+		got = append(got, text[w.Lo:w.Hi])
+	}
+	if want := []string{"x", "中文", "文\n字"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("InsertSplit(%q) texts = %q, want %q", text, got, want)
+	}
+}
+
 var mitLicenseRot13 = ` // MIT License, rot13 to hide from license scanners
 pbclevtug 2020 gur evtug tbcure
 
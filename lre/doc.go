@@ -0,0 +1,91 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lre implements license regular expressions (LREs),
+// the word-based patterns that package licensecheck uses to recognize
+// license texts. The same machinery is useful for recognizing any large,
+// mostly fixed English text with minor variations, such as contributor
+// license agreements, export control notices, or codes of conduct.
+//
+// # Syntax
+//
+// A license regular expression (LRE) is a pattern syntax intended for
+// describing large English texts such as software licenses, with minor
+// allowed variations. The pattern syntax and the matching are word-based
+// and case-insensitive; punctuation is ignored in the pattern and in
+// the matched text.
+//
+// The valid LRE patterns are:
+//
+//	word            - a single case-insensitive word
+//	__N__           - any sequence of up to N words
+//	expr1 expr2     - concatenation
+//	expr1 || expr2  - alternation
+//	expr1 && expr2  - both expressions, in either order (at most 5 in a group)
+//	(( expr ))      - grouping
+//	expr??          - zero or one instances of expr
+//	expr{m,n}       - m to n instances of expr (n at most 10)
+//	expr{n}         - exactly n instances of expr
+//	//** text **//  - a comment
+//	(( phrases ))!! - phrases that must not appear in the match
+//
+// To make patterns harder to misread in large texts:
+//
+//   - || and && must only appear inside (( )), and not both in the same group
+//   - ??, !!, and {m,n} must only follow (( ))
+//   - (( ))!! must not be inside another (( )) and must contain only
+//     words or alternatives of words
+//   - (( must be at the start of a line, preceded only by spaces
+//   - )) must be at the end of a line, followed only by spaces and ??, !!, or {m,n}.
+//
+// For example:
+//
+//	//** https://en.wikipedia.org/wiki/Filler_text **//
+//	Now is
+//	((not))??
+//	the time for all good
+//	((men || women || people))
+//	to come to the aid of their __1__.
+//
+// An excluded phrase (( phrase ))!! matches empty text wherever it appears.
+// Instead, a MultiLRE rejects any match of the LRE in which the phrase appears,
+// or in which it appears within 20 words after the match.
+// This distinguishes a license from a variant adding a restriction
+// that the license's pattern would otherwise accept, for example
+// in text matched by a wildcard.
+//
+// Format rewrites an LRE in a canonical layout,
+// and Lint reports patterns that are likely mistakes.
+//
+// # Matching
+//
+// Patterns and texts are split into words by a Dict, which assigns
+// each distinct word an integer WordID. Splitting folds case and accents
+// and canonicalizes some common variations, such as "(c)" and "©"
+// for "copyright" and "these" for "this". During a match, a word that is
+// a near misspelling of the expected word, or a pair of words that joins
+// to form it, is accepted in its place (see SpellCheck). All LREs used
+// together must be parsed with the same Dict. Dict.Rewrite adds more
+// canonicalizations, and Dict.SetSegmentation splits Chinese and Japanese
+// text, which does not use spaces between words, into characters or
+// character pairs, so that LREs can be written in those languages.
+//
+// A MultiLRE matches a list of LREs simultaneously,
+// reporting the leftmost-longest, non-overlapping matches in a text.
+// Every LRE in a MultiLRE must begin with at least two words
+// that do not match an empty text or a wildcard:
+// the MultiLRE only starts a match at one of those leading phrases.
+//
+// # Compatibility
+//
+// This package follows the Go 1 compatibility guidelines:
+// code that compiles and runs using this package will continue
+// to do so with later versions. New syntax may be added to LREs,
+// but existing valid LREs will continue to be accepted and to have
+// the same meaning. The details of word splitting, canonicalization,
+// and spelling correction may be refined in ways that change
+// which texts an LRE matches, in order to match license texts better.
+// The exact set of diagnostics reported by Lint may also change.
+// TraceDFA is a debugging aid and may change or be removed.
+package lre
@@ -0,0 +1,114 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lre_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/google/licensecheck/lre"
+)
+
+func ExampleMultiLRE_Match() {
+	// Patterns for two kinds of project boilerplate.
+	patterns := []struct{ name, lre string }{
+		{"sign-off", `
+			By making a contribution to this project, I certify that
+			the contribution was created
+			((in whole or in part))??
+			by me and I have the right to submit it
+			under the __3__ license indicated in the file.
+		`},
+		{"conduct", `
+			We as
+			((members || contributors || maintainers || and)){1,5}
+			pledge to make participation in our community
+			a harassment-free experience for everyone.
+		`},
+	}
+
+	var d lre.Dict
+	var list []*lre.LRE
+	for _, p := range patterns {
+		re, err := lre.ParseLRE(&d, p.name, p.lre)
+		if err != nil {
+			log.Fatal(err)
+		}
+		list = append(list, re)
+	}
+	multi, err := lre.NewMultiLRE(list)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	text := `Contributing
+
+By making a contribution to this project, I certify that the
+contribution was created by me and I have the right to submit it under
+the open source license indicated in the file.
+
+Our Pledge
+
+We as members, contributors, and maintainers pledge to make
+participation in our community a harassment-free experience
+for everyone.
+`
+	matches := multi.Match(text)
+	for _, m := range matches.List {
+		start := matches.Words[m.Start].Lo
+		end := matches.Words[m.End-1].Hi
+		fmt.Printf("%s at [%d:%d]\n", list[m.ID].File(), start, end)
+	}
+
+	// Output:
+	// sign-off at [14:191]
+	// conduct at [206:338]
+}
+
+func ExampleDict_Split() {
+	var d lre.Dict
+	_, err := lre.ParseLRE(&d, "", "copyright the notices")
+	if err != nil {
+		log.Fatal(err)
+	}
+	text := "(C) These NOTICES"
+	words := d.Split(text)
+	for _, w := range words {
+		id := "BadWord"
+		if w.ID >= 0 {
+			id = d.Words()[w.ID]
+		}
+		fmt.Printf("%q: %s\n", text[w.Lo:w.Hi], id)
+	}
+
+	// Output:
+	// "(C)": copyright
+	// "These": the
+	// "NOTICES": notices
+}
+
+func ExampleFormat() {
+	src := []byte(`The name of the author   
+  ((may ||
+      must)) ??
+not   be used to endorse products derived from this software.
+
+
+`)
+	out, err := lre.Format(src)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s", out)
+
+	// Output:
+	// The name of the author
+	// ((
+	// 	may
+	// ||
+	// 	must
+	// ))??
+	// not be used to endorse products derived from this software.
+}
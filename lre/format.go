@@ -0,0 +1,331 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Canonical formatting of LRE source files.
+
+package lre
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Format returns the canonical layout of the LRE source file src.
+// Unlike ParseLRE, Format accepts the text/template actions
+// (such as {{define "x"}} and {{template "x"}}) used in .lre files,
+// and it preserves them and all //** comments **// in its output.
+//
+// The canonical layout is:
+//
+//   - A (( )) group written on a single line stays on a single line,
+//     with its alternatives separated by " || " (or " && ")
+//     and no spaces inside the parentheses, unless the text
+//     inside starts with ( or ends with ).
+//   - Any other group is written with the ((, each ||, and the ))
+//     on lines of their own, and the alternatives indented one tab
+//     more than the group.
+//   - Text lines are indented by one tab per enclosing multi-line group,
+//     with the words separated by single spaces. Line breaks in the
+//     text are kept, but runs of blank lines are reduced to one,
+//     and blank lines at the start or end of a group alternative
+//     or of the file are removed.
+//   - The ??, !!, or {m,n} suffix of a group follows its )) directly.
+//   - Template actions are kept as written, as are the continuation
+//     lines of comments spanning multiple lines.
+//
+// Formatting does not change the meaning of the LRE: Format checks
+// that the result parses with the same meaning as src. In a file
+// with template actions, it checks the text around the actions,
+// treating each action as a single word.
+func Format(src []byte) ([]byte, error) {
+	s := strings.Replace(string(src), "\r\n", "\n", -1)
+	out, err := fmtLayout(s)
+	if err != nil {
+		return nil, err
+	}
+
+	var d Dict
+	re1, err := reParse(&d, fmtSkeleton(s), true)
+	if err != nil {
+		return nil, err
+	}
+	re2, err := reParse(&d, fmtSkeleton(string(out)), true)
+	if err != nil {
+		return nil, fmt.Errorf("formatting produced invalid LRE: %v", err)
+	}
+	if re1.string(&d) != re2.string(&d) {
+		return nil, fmt.Errorf("formatting changed LRE meaning")
+	}
+	return out, nil
+}
+
+// fmtSkeleton returns s with each template action replaced
+// by a placeholder word, so that it can be checked by reParse.
+// An action made optional, as in {{template "x"}}??,
+// is replaced by an optional group containing the placeholder.
+func fmtSkeleton(s string) string {
+	if !strings.Contains(s, "{{") {
+		return s
+	}
+	var buf strings.Builder
+	for {
+		i := strings.Index(s, "{{")
+		if i < 0 {
+			buf.WriteString(s)
+			break
+		}
+		j := strings.Index(s[i:], "}}")
+		if j < 0 {
+			buf.WriteString(s)
+			break
+		}
+		buf.WriteString(s[:i])
+		s = s[i+j+2:]
+		if k := skipSpace(s, 0); strings.HasPrefix(s[k:], "??") {
+			buf.WriteString("((template))??")
+			s = s[k+2:]
+		} else {
+			buf.WriteString(" template ")
+		}
+	}
+	return buf.String()
+}
+
+// A fmtNode is a node in the layout tree built by fmtLayout:
+// a line of text, a blank line, or a group.
+type fmtNode struct {
+	text  string       // text line, without indentation; "" for a blank line
+	group bool         // node is a group
+	multi bool         // group spans multiple lines
+	sep   string       // group separator: "||" or "&&"
+	alts  [][]*fmtNode // group alternatives
+	suf   string       // group suffix: "", "??", "!!", or "{m,n}"
+}
+
+// fmtLayout checks the group structure of s and returns s in canonical layout.
+func fmtLayout(s string) ([]byte, error) {
+	top := new(fmtNode)
+	top.alts = [][]*fmtNode{nil}
+	stack := []*fmtNode{top}
+	var opens []int // offsets of unclosed ((
+
+	var line bytes.Buffer // text of current line
+	space := false        // whitespace pending before next text on line
+	empty := true         // current source line has no tokens
+	add := func(n *fmtNode) {
+		g := stack[len(stack)-1]
+		k := len(g.alts) - 1
+		g.alts[k] = append(g.alts[k], n)
+	}
+	flush := func() {
+		if line.Len() > 0 {
+			add(&fmtNode{text: line.String()})
+			line.Reset()
+		}
+		space = false
+	}
+	text := func(t string) {
+		if space && line.Len() > 0 {
+			line.WriteByte(' ')
+		}
+		space = false
+		empty = false
+		line.WriteString(t)
+		if strings.Contains(t, "\n") {
+			for _, g := range stack[1:] {
+				g.multi = true
+			}
+		}
+	}
+
+	i := 0
+	for i < len(s) {
+		switch {
+		case s[i] == '\n':
+			flush()
+			if empty {
+				add(&fmtNode{})
+			}
+			for _, g := range stack[1:] {
+				g.multi = true
+			}
+			empty = true
+			i++
+
+		case s[i] == ' ' || s[i] == '\t':
+			space = true
+			i++
+
+		case strings.HasPrefix(s[i:], "{{"):
+			j := strings.Index(s[i:], "}}")
+			if j < 0 {
+				return nil, reSyntaxError(s, i, fmt.Errorf("opening {{ without closing }}"))
+			}
+			t := s[i : i+j+2]
+			i += j + 2
+			// A template expanding to a group can be made optional: {{template "x"}}??
+			if k := skipSpace(s, i); strings.HasPrefix(s[k:], "??") {
+				t += "??"
+				i = k + 2
+			}
+			text(t)
+
+		case strings.HasPrefix(s[i:], "//**"):
+			j := strings.Index(s[i+4:], "**//")
+			if j < 0 {
+				return nil, reSyntaxError(s, i, fmt.Errorf("opening //** without closing **//"))
+			}
+			lines := strings.Split(s[i:i+4+j+4], "\n")
+			for k, l := range lines {
+				lines[k] = strings.TrimRight(l, " \t")
+			}
+			text(strings.Join(lines, "\n"))
+			i += 4 + j + 4
+
+		case strings.HasPrefix(s[i:], "(("):
+			flush()
+			g := &fmtNode{group: true, alts: [][]*fmtNode{nil}}
+			add(g)
+			stack = append(stack, g)
+			opens = append(opens, i)
+			empty = false
+			i += 2
+
+		case strings.HasPrefix(s[i:], "||"), strings.HasPrefix(s[i:], "&&"):
+			sep := s[i : i+2]
+			if len(opens) == 0 {
+				return nil, reSyntaxError(s, i, fmt.Errorf("%s outside (( ))", sep))
+			}
+			g := stack[len(stack)-1]
+			if g.sep != "" && g.sep != sep {
+				return nil, reSyntaxError(s, i, errMixedSeparators)
+			}
+			flush()
+			g.sep = sep
+			g.alts = append(g.alts, nil)
+			empty = false
+			i += 2
+
+		case strings.HasPrefix(s[i:], "))"):
+			if len(opens) == 0 {
+				return nil, reSyntaxError(s, i, fmt.Errorf("unexpected ))"))
+			}
+			flush()
+			g := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			opens = opens[:len(opens)-1]
+			empty = false
+			i += 2
+			j := skipSpace(s, i)
+			if strings.HasPrefix(s[j:], "??") || strings.HasPrefix(s[j:], "!!") {
+				g.suf = s[j : j+2]
+				i = j + 2
+			} else if strings.HasPrefix(s[j:], "{") {
+				k := strings.Index(s[j:], "}")
+				if k < 0 {
+					return nil, reSyntaxError(s, j, fmt.Errorf("missing } in repetition"))
+				}
+				g.suf = strings.Join(strings.Fields(s[j:j+k+1]), "")
+				i = j + k + 1
+			}
+
+		case strings.HasPrefix(s[i:], "??"), strings.HasPrefix(s[i:], "!!"):
+			return nil, reSyntaxError(s, i, fmt.Errorf("%s not preceded by ))", s[i:i+2]))
+
+		default:
+			j := i + 1
+			for j < len(s) && !strings.ContainsRune(" \t\n", rune(s[j])) && !fmtSpecial(s[j:]) {
+				j++
+			}
+			text(s[i:j])
+			i = j
+		}
+	}
+	if len(opens) > 0 {
+		return nil, reSyntaxError(s, len(s), fmt.Errorf("missing )) at end"))
+	}
+	flush()
+
+	var buf bytes.Buffer
+	fmtPrint(&buf, top.alts[0], 0)
+	return buf.Bytes(), nil
+}
+
+// fmtSpecial reports whether s starts with an LRE or template token
+// that ends a run of text.
+func fmtSpecial(s string) bool {
+	for _, t := range []string{"{{", "//**", "((", "))", "||", "&&", "??", "!!"} {
+		if strings.HasPrefix(s, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// fmtPrint prints the nodes, indented by depth tabs, to buf.
+func fmtPrint(buf *bytes.Buffer, nodes []*fmtNode, depth int) {
+	indent := strings.Repeat("\t", depth)
+	blank := false
+	printed := false
+	for _, n := range nodes {
+		if !n.group && n.text == "" {
+			blank = printed
+			continue
+		}
+		if blank {
+			buf.WriteString("\n")
+			blank = false
+		}
+		printed = true
+		switch {
+		case !n.group:
+			buf.WriteString(indent + n.text + "\n")
+		case !n.multi:
+			buf.WriteString(indent + fmtInline(n) + "\n")
+		default:
+			buf.WriteString(indent + "((\n")
+			for k, alt := range n.alts {
+				if k > 0 {
+					buf.WriteString(indent + n.sep + "\n")
+				}
+				fmtPrint(buf, alt, depth+1)
+			}
+			buf.WriteString(indent + "))" + n.suf + "\n")
+		}
+	}
+}
+
+// fmtInline returns the single-line form of the group n.
+func fmtInline(n *fmtNode) string {
+	var alts []string
+	for _, alt := range n.alts {
+		var words []string
+		for _, x := range alt {
+			if x.group {
+				words = append(words, fmtInline(x))
+			} else if x.text != "" {
+				words = append(words, x.text)
+			}
+		}
+		alts = append(alts, strings.Join(words, " "))
+	}
+	inner := strings.Join(alts, " "+n.sep+" ")
+	if inner == "" || strings.HasPrefix(inner, "(") || strings.HasSuffix(inner, ")") || strings.HasPrefix(inner, " ") || strings.HasSuffix(inner, " ") {
+		inner = " " + strings.TrimSpace(inner) + " "
+		if strings.TrimSpace(inner) == "" {
+			inner = " "
+		}
+	}
+	return "((" + inner + "))" + n.suf
+}
+
+// skipSpace returns the offset of the first byte at or after i in s
+// that is not a space or tab.
+func skipSpace(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\t') {
+		i++
+	}
+	return i
+}
@@ -0,0 +1,152 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lre
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+var formatTests = []struct {
+	in  string
+	out string
+}{
+	{"a   b\nc", "a b\nc\n"},
+	{"\n\na b  \r\n\n\nc\t\n\n", "a b\n\nc\n"},
+	{"a\n  ((b||c))\nd", "a\n((b || c))\nd\n"},
+	{"a\n((b))   ??\nd", "a\n((b))??\nd\n"},
+	{"a\n(( b || c )) { 1, 3 }\nd", "a\n((b || c)){1,3}\nd\n"},
+	{"a\n((b c || d)) !!\ne", "a\n((b c || d))!!\ne\n"},
+	{"a\n((b&&c\n\t((d)) ??\n))\ne", "a\n((\n\tb\n&&\n\tc\n\t((d))??\n))\ne\n"},
+	{"a\n(( 1) ))??\nd", "a\n(( 1) ))??\nd\n"},
+	{"a\n(( (the License) ))??\nd", "a\n(( (the License) ))??\nd\n"},
+	{"a\n(( || b ))\nc", "a\n(( || b ))\nc\n"},
+	{"a\n(( ))\nc", "a\n(( ))\nc\n"},
+	{"((\n\tto use\n||\n\tto copy\n))", "((\n\tto use\n||\n\tto copy\n))\n"},
+	{"((\n        to use\n||\n        to copy\n))", "((\n\tto use\n||\n\tto copy\n))\n"},
+	{"((\n  to use\n||\n  to copy\n))", "((\n\tto use\n||\n\tto copy\n))\n"},
+	{"((\n to use\n||\n to copy\n))", "((\n\tto use\n||\n\tto copy\n))\n"},
+	{"((to use\n|| to copy))", "((\n\tto use\n||\n\tto copy\n))\n"},
+	{"a\n((b\n((c))??\n\n\nd\n\n|| e))??\nf",
+		"a\n((\n\tb\n\t((c))??\n\n\td\n||\n\te\n))??\nf\n"},
+	{"//** comment  \n  kept as is ?? **//\na\n//** inline **// b", "//** comment\n  kept as is ?? **//\na\n//** inline **// b\n"},
+	{"a __7__   //** trailing **//\nb", "a __7__ //** trailing **//\nb\n"},
+	{"a\n{{template \"x\"}} ??\nb", "a\n{{template \"x\"}}??\nb\n"},
+	{"version {{$v}}\n(({{$a}}v{{$v}}))??", "version {{$v}}\n(({{$a}}v{{$v}}))??\n"},
+	{"{{define \"x\"}}\n\ta\n\t((b || c)) ??\n{{end}}\n{{define \"y\"}}\nd\n{{end}}",
+		"{{define \"x\"}}\na\n((b || c))??\n{{end}}\n{{define \"y\"}}\nd\n{{end}}\n"},
+	{"", ""},
+}
+
+func TestFormat(t *testing.T) {
+	for _, tt := range formatTests {
+		out, err := Format([]byte(tt.in))
+		if err != nil {
+			t.Errorf("Format(%q): %v", tt.in, err)
+			continue
+		}
+		if string(out) != tt.out {
+			t.Errorf("Format(%q):\nhave %q\nwant %q", tt.in, out, tt.out)
+		}
+		out2, err := Format(out)
+		if err != nil || !bytes.Equal(out, out2) {
+			t.Errorf("Format(Format(%q)) = %q, %v, want no change", tt.in, out2, err)
+		}
+	}
+}
+
+var formatErrorTests = []struct {
+	in  string
+	err string
+}{
+	{"a\n((b", "missing )) at end"},
+	{"a))", "unexpected ))"},
+	{"a || b", "|| outside (( ))"},
+	{"a b??", "?? not preceded by ))"},
+	{"a {{define", "opening {{ without closing }}"},
+	{"a //** b", "opening //** without closing **//"},
+	{"a ((b))", "(( not at beginning of line"},
+	{"a\n((b)){1,2", "missing } in repetition"},
+	{"a\n((b || c && d))", "|| and && mixed in (( ))"},
+	{"a && b", "&& outside (( ))"},
+	{"a b!!", "!! not preceded by ))"},
+}
+
+func TestFormatError(t *testing.T) {
+	for _, tt := range formatErrorTests {
+		_, err := Format([]byte(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Format(%q) error = %v, want %q", tt.in, err, tt.err)
+		}
+	}
+}
+
+// TestFormatLicenses checks that every built-in license is in canonical layout
+// and that formatting leaves its meaning unchanged.
+func TestFormatLicenses(t *testing.T) {
+	files, err := filepath.Glob("../licenses/*.lre")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatal("no license files")
+	}
+	orig := template.New("").Funcs(fmtTestFuncs)
+	formatted := template.New("").Funcs(fmtTestFuncs)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Format(data)
+		if err != nil {
+			t.Errorf("%s: %v", file, err)
+			continue
+		}
+		if !bytes.Equal(out, data) {
+			t.Errorf("%s: not in canonical layout", file)
+		}
+		name := filepath.Base(file)
+		template.Must(orig.New(name).Parse(string(data)))
+		template.Must(formatted.New(name).Parse(string(out)))
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		var b1, b2 bytes.Buffer
+		if err := orig.ExecuteTemplate(&b1, name, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := formatted.ExecuteTemplate(&b2, name, nil); err != nil {
+			t.Errorf("%s: executing formatted template: %v", name, err)
+			continue
+		}
+		if len(bytes.TrimSpace(b1.Bytes())) == 0 {
+			continue
+		}
+		var d Dict
+		re1, err := reParse(&d, b1.String(), true)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		re2, err := reParse(&d, b2.String(), true)
+		if err != nil {
+			t.Errorf("%s: parsing formatted license: %v", name, err)
+			continue
+		}
+		if re1.string(&d) != re2.string(&d) {
+			t.Errorf("%s: formatting changed the license", name)
+		}
+	}
+}
+
+var fmtTestFuncs = template.FuncMap{
+	"list": func(xs ...interface{}) []interface{} { return xs },
+	"Type": func(string) string { return "" },
+}
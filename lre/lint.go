@@ -0,0 +1,494 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// LRE quality checks.
+
+package lre
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Diagnostic is a quality problem found in an LRE by Lint.
+// Unlike syntax errors, diagnostics do not stop an LRE from being used,
+// but they usually indicate a pattern that is slower or looser than intended.
+type Diagnostic struct {
+	File string // file name of the LRE
+	Kind string // kind of problem; see Lint for the list
+	Msg  string
+}
+
+func (d *Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s: %s", d.File, d.Kind, d.Msg)
+}
+
+// Diagnostic kinds.
+const (
+	DeadAlternative   = "dead-alternative"
+	DuplicateOptional = "duplicate-optional"
+	DelayedCut        = "delayed-cut"
+	DFAStates         = "dfa-states"
+	StartCollision    = "start-collision"
+)
+
+// Lint checks the LREs in list and returns the problems it finds, sorted by file name.
+// The LREs in context, if any, are not themselves checked, but the LREs in list
+// are checked for collisions with them as well as with each other.
+// All the LREs must have been parsed using the same Dict.
+// The kinds of problems are:
+//
+//   - DeadAlternative: an alternative in (( || )) that can never be chosen,
+//     because every text it matches is also matched by the other alternatives;
+//   - DuplicateOptional: an optional ((x))?? group that repeats the required words
+//     immediately before or after it, so that the text may repeat them too;
+//   - DelayedCut: a __N__ wildcard or )){m,n} repetition whose implicit
+//     cut (see rematch.go) is delayed by more than a couple of optional words
+//     following it, letting it contribute more DFA states than necessary;
+//   - DFAStates: an LRE whose DFA, compiled on its own, has more than maxStates states
+//     (if maxStates > 0);
+//   - StartCollision: a leading phrase that is also a leading phrase of
+//     another LRE, when the two LREs can match the same text,
+//     so that a MultiLRE cannot tell which one it has found.
+func Lint(list, context []*LRE, maxStates int) []*Diagnostic {
+	var diags []*Diagnostic
+	for _, re := range list {
+		diags = append(diags, re.lint()...)
+		if maxStates > 0 {
+			if n := re.NumStates(); n > maxStates {
+				diags = append(diags, &Diagnostic{re.file, DFAStates, fmt.Sprintf("DFA has %d states (> %d)", n, maxStates)})
+			}
+		}
+	}
+	diags = append(diags, lintStarts(list, context)...)
+	sort.SliceStable(diags, func(i, j int) bool { return diags[i].File < diags[j].File })
+	return diags
+}
+
+// NumStates returns the number of states in the DFA for re alone.
+// It approximates the cost re contributes to a MultiLRE containing it.
+func (re *LRE) NumStates() int {
+	re.onceDFA.Do(re.compile)
+	return re.dfa.numStates()
+}
+
+// numStates returns the number of states in the DFA.
+func (dfa reDFA) numStates() int {
+	n := 0
+	for i := int32(0); int(i) < len(dfa); n++ {
+		_, delta := dfa.stateAt(i)
+		i += 1 + dfa[i]&1 + int32(len(delta))
+	}
+	return n
+}
+
+// lint returns the problems found in re alone.
+func (re *LRE) lint() []*Diagnostic {
+	l := &linter{re: re}
+	l.walk(re.syntax)
+	l.cuts()
+	return l.diags
+}
+
+// A linter holds the state for checking a single LRE.
+type linter struct {
+	re    *LRE
+	diags []*Diagnostic
+}
+
+func (l *linter) report(kind, format string, args ...interface{}) {
+	l.diags = append(l.diags, &Diagnostic{l.re.file, kind, fmt.Sprintf(format, args...)})
+}
+
+// text returns a short, single-line form of re for use in messages.
+func (l *linter) text(re *reSyntax) string {
+	s := strings.Join(strings.Fields(re.string(l.re.dict)), " ")
+	if len(s) > 60 {
+		s = s[:57] + "..."
+	}
+	return s
+}
+
+// walk checks the syntax tree re and its subexpressions.
+func (l *linter) walk(re *reSyntax) {
+	switch re.op {
+	case opAlternate:
+		l.alternate(re)
+	case opConcat:
+		l.concat(re)
+	}
+	for _, sub := range re.sub {
+		l.walk(sub)
+	}
+}
+
+// alternate checks for dead alternatives in the alternation re.
+// Of two alternatives matching the same text, only the later one
+// is dead: the earlier one is tried first.
+func (l *linter) alternate(re *reSyntax) {
+	for i, sub := range re.sub {
+		var others []*reSyntax
+		dup := false
+		for j, o := range re.sub {
+			if j == i {
+				continue
+			}
+			if subsumes(o, sub) && subsumes(sub, o) {
+				dup = dup || j < i
+				continue
+			}
+			others = append(others, o)
+		}
+		var other *reSyntax
+		switch len(others) {
+		case 0:
+		case 1:
+			other = others[0]
+		default:
+			other = &reSyntax{op: opAlternate, sub: others}
+		}
+		if dup || other != nil && subsumes(other, sub) {
+			l.report(DeadAlternative, "alternative %q in %q matches nothing the others do not", l.text(sub), l.text(re))
+		}
+	}
+}
+
+// concat checks for optional groups in the concatenation re
+// that duplicate their neighbors.
+// Only repeats of required text are reported: repeating optional text,
+// as in ((Title))?? ((Title, Version 2))??, is a choice between forms.
+// Two kinds of optional groups repeat their neighbors on purpose
+// and are not reported either: a list label, such as ((1))?? or ((a))??,
+// which only happens to match the words next to it, and an optional
+// title at the start of the LRE, which the text itself often repeats.
+func (l *linter) concat(re *reSyntax) {
+	for i, sub := range re.sub {
+		if sub.op != opQuest || sub.sub[0].op != opWords {
+			continue
+		}
+		w := sub.sub[0].w
+		if l.isLabel(w) || i == 0 && re == l.re.syntax {
+			continue
+		}
+		if i > 0 && endsWith(re.sub[i-1], w) {
+			l.report(DuplicateOptional, "optional %q repeats preceding words", l.text(sub))
+		} else if i+1 < len(re.sub) && startsWith(re.sub[i+1], w) {
+			l.report(DuplicateOptional, "optional %q repeats following words", l.text(sub))
+		}
+	}
+}
+
+// isLabel reports whether w is a list label: a single number or letter.
+func (l *linter) isLabel(w []WordID) bool {
+	if len(w) != 1 || w[0] < 0 {
+		return false
+	}
+	word := l.re.dict.Words()[w[0]]
+	if utf8.RuneCountInString(word) == 1 && unicode.IsLetter([]rune(word)[0]) {
+		return true
+	}
+	return strings.Trim(word, "0123456789") == ""
+}
+
+// literal returns the words matched by re if re is a literal word sequence.
+func literal(re *reSyntax) []WordID {
+	if re.op == opWords {
+		return re.w
+	}
+	return nil
+}
+
+// endsWith reports whether re's literal words end with w.
+func endsWith(re *reSyntax, w []WordID) bool {
+	x := literal(re)
+	return len(x) >= len(w) && equalWords(x[len(x)-len(w):], w)
+}
+
+// startsWith reports whether re's literal words start with w.
+func startsWith(re *reSyntax, w []WordID) bool {
+	x := literal(re)
+	return len(x) >= len(w) && equalWords(x[:len(w)], w)
+}
+
+func equalWords(x, y []WordID) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// maxSubsumeStates bounds the work done by subsumes.
+const maxSubsumeStates = 10000
+
+// subsumes reports whether every text matched by sub is also matched by re.
+// If the check would take too long, subsumes returns false.
+func subsumes(re, sub *reSyntax) bool {
+	progA, _ := sub.compile(nil, 0)
+	progB, _ := re.compile(nil, 0)
+
+	type pair struct{ a, b nfaState }
+	var enc []byte
+	key := func(p pair) string {
+		enc = p.a.appendEncoding(enc[:0])
+		enc = append(enc, 0xff, 0xff, 0xff, 0xff)
+		enc = p.b.appendEncoding(enc)
+		return string(enc)
+	}
+	start := pair{nfaStart(progA), nfaStart(progB)}
+	seen := map[string]bool{key(start): true}
+	queue := []pair{start}
+	for len(queue) > 0 {
+		if len(seen) > maxSubsumeStates {
+			return false
+		}
+		p := queue[0]
+		queue = queue[1:]
+		if p.a.match(progA) >= 0 && p.b.match(progB) < 0 {
+			return false
+		}
+		// AnyWord stands for all the words that neither program mentions.
+		words := append(p.a.words(progA), p.b.words(progB)...)
+		words = append(words, AnyWord)
+		sortWordIDs(words)
+		words = uniqWords(words)
+		for _, w := range words {
+			next := pair{p.a.next(progA, w), p.b.next(progB, w)}
+			if len(next.a) == 0 {
+				continue
+			}
+			if k := key(next); !seen[k] {
+				seen[k] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return true
+}
+
+// uniqWords removes adjacent duplicates from words.
+func uniqWords(words []WordID) []WordID {
+	out := words[:0]
+	for _, w := range words {
+		if len(out) == 0 || w != out[len(out)-1] {
+			out = append(out, w)
+		}
+	}
+	return out
+}
+
+// maxCutDelay is the number of words by which optional text may delay
+// a cut without being reported. A short optional group, such as a list
+// label or an optional "AND", delays the cut by a word or two at most,
+// costing few DFA states; a run of several such groups delays it further.
+const maxCutDelay = 2
+
+// cuts checks for wildcards whose implicit cut is delayed.
+func (l *linter) cuts() {
+	prog := l.re.prog
+	for pc, inst := range prog {
+		if inst.op != instCut {
+			continue
+		}
+		// The cut target is the first alt of a wildcard or of the
+		// optional copies in a repetition; either way, it jumps
+		// to the end of the wildcard or repetition.
+		start := pc + 1 + int(inst.arg)
+		end := start + 1 + int(prog[start].arg)
+		what := "repetition"
+		if n := countAny(prog, start); n > 0 {
+			what = fmt.Sprintf("__%d__", n)
+		}
+		memo := make(map[int]int)
+		if words := wordsBeforeCut(prog, end, pc, start, memo); words > 3+maxCutDelay {
+			l.report(DelayedCut, "cut after %s delayed by optional text: up to %d words instead of 3", what, words)
+		}
+	}
+}
+
+// countAny returns the number of wildcard words in the
+// wildcard program starting at pc (see opWild in reCompile.compile).
+func countAny(prog reProg, pc int) int {
+	n := 0
+	for pc+1 < len(prog) && prog[pc].op == instAlt && prog[pc+1].op == instAny {
+		n++
+		pc += 2
+	}
+	return n
+}
+
+// wordsBeforeCut returns the maximum number of literal words on any path
+// from pc to the cut instruction at cut, or -1 if there is no such path.
+// A path through another cut of the same target, such as the copy of the
+// cut compiled inside an optional group, has already cut off the wildcard
+// and does not count.
+func wordsBeforeCut(prog reProg, pc, cut, target int, memo map[int]int) int {
+	if pc == cut {
+		return 0
+	}
+	if pc > cut || pc >= len(prog) {
+		return -1
+	}
+	if n, ok := memo[pc]; ok {
+		return n
+	}
+	memo[pc] = -1 // in case of cycles
+	n := -1
+	switch inst := prog[pc]; inst.op {
+	case instWord:
+		if m := wordsBeforeCut(prog, pc+1, cut, target, memo); m >= 0 {
+			n = m + 1
+		}
+	case instAlt:
+		n = wordsBeforeCut(prog, pc+1, cut, target, memo)
+		if m := wordsBeforeCut(prog, pc+1+int(inst.arg), cut, target, memo); m > n {
+			n = m
+		}
+	case instJump:
+		n = wordsBeforeCut(prog, pc+1+int(inst.arg), cut, target, memo)
+	case instCut:
+		if pc+1+int(inst.arg) != target {
+			n = wordsBeforeCut(prog, pc+1, cut, target, memo)
+		}
+	}
+	memo[pc] = n
+	return n
+}
+
+// lintStarts checks for leading phrases shared by different LREs,
+// reporting those in list.
+// A shared leading phrase is only a problem if the LREs can match
+// the same text: otherwise the DFA in a MultiLRE tells their matches apart
+// by the words that follow. LREs with excluded phrases are expected to
+// overlap with the LREs they defer to, so they are not reported either.
+func lintStarts(list, context []*LRE) []*Diagnostic {
+	check := make(map[*LRE]bool)
+	for _, re := range list {
+		check[re] = true
+	}
+	users := make(map[phrase][]*LRE)
+	var phrases []phrase
+	for _, re := range append(list[:len(list):len(list)], context...) {
+		for _, p := range re.syntax.leadingPhrases() {
+			if p[0] < 0 || p[1] < 0 {
+				// Invalid phrases are reported by NewMultiLRE.
+				continue
+			}
+			if len(users[p]) == 0 {
+				phrases = append(phrases, p)
+			}
+			if u := users[p]; len(u) == 0 || u[len(u)-1] != re {
+				users[p] = append(u, re)
+			}
+		}
+	}
+
+	type pair struct{ x, y *LRE }
+	overlap := make(map[pair]bool)
+	var diags []*Diagnostic
+	for _, p := range phrases {
+		u := users[p]
+		if len(u) < 2 {
+			continue
+		}
+		for _, re := range u {
+			if !check[re] || len(re.excludes) > 0 {
+				continue
+			}
+			var others []string
+			for _, o := range u {
+				if o == re || len(o.excludes) > 0 {
+					continue
+				}
+				ok, done := overlap[pair{re, o}]
+				if !done {
+					ok = overlaps(re.prog, o.prog)
+					overlap[pair{re, o}] = ok
+					overlap[pair{o, re}] = ok
+				}
+				if ok {
+					others = append(others, o.file)
+				}
+			}
+			if len(others) == 0 {
+				continue
+			}
+			words := re.dict.Words()
+			diags = append(diags, &Diagnostic{re.file, StartCollision,
+				fmt.Sprintf("leading phrase %q also starts %s, which can match the same text", words[p[0]]+" "+words[p[1]], strings.Join(others, ", "))})
+		}
+	}
+	return diags
+}
+
+// overlaps reports whether some text is matched by both progA and progB
+// with a word that one of them matches only by a wildcard, as when
+// "version 2 __5__ is distributed" matches "version 2 or later is distributed".
+// The other license's distinguishing words are then lost in the wildcard,
+// so that a MultiLRE can report either license for the text.
+// (LREs that match the same text word for word, such as licenses
+// that differ only in an optional title, are not reported:
+// their overlap is in the license texts themselves.)
+// If the check would take too long, overlaps returns false.
+func overlaps(progA, progB reProg) bool {
+	type pair struct {
+		a, b nfaState
+		wild bool // some word so far was matched by a wildcard in only one
+	}
+	var enc []byte
+	key := func(p pair) string {
+		enc = p.a.appendEncoding(enc[:0])
+		enc = append(enc, 0xff, 0xff, 0xff, 0xff)
+		enc = p.b.appendEncoding(enc)
+		if p.wild {
+			enc = append(enc, 1)
+		}
+		return string(enc)
+	}
+	start := pair{nfaStart(progA), nfaStart(progB), false}
+	seen := map[string]bool{key(start): true}
+	queue := []pair{start}
+	for len(queue) > 0 {
+		if len(seen) > maxSubsumeStates {
+			return false
+		}
+		p := queue[0]
+		queue = queue[1:]
+		if p.wild && p.a.match(progA) >= 0 && p.b.match(progB) >= 0 {
+			return true
+		}
+		wordsA, wordsB := p.a.words(progA), p.b.words(progB)
+		words := append(wordsA[:len(wordsA):len(wordsA)], wordsB...)
+		words = append(words, AnyWord)
+		sortWordIDs(words)
+		words = uniqWords(words)
+		for _, w := range words {
+			next := pair{p.a.next(progA, w), p.b.next(progB, w), p.wild}
+			if len(next.a) == 0 || len(next.b) == 0 {
+				continue
+			}
+			if w != AnyWord && hasWord(wordsA, w) != hasWord(wordsB, w) {
+				next.wild = true
+			}
+			if k := key(next); !seen[k] {
+				seen[k] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
+
+// hasWord reports whether the sorted list words contains w.
+func hasWord(words []WordID, w WordID) bool {
+	i := sort.Search(len(words), func(i int) bool { return words[i] >= w })
+	return i < len(words) && words[i] == w
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lre
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var lintTests = []struct {
+	re    string
+	diags []string // kind: msg substring
+}{
+	{"a b c\n((d || e))\nf", nil},
+	{"a b c\n((d || d))\nf", []string{`dead-alternative: alternative "d" in "((d || d))"`}},
+	{"a b c\n((to copy || to copy || to copy))\nf", []string{"dead-alternative", "dead-alternative"}},
+	{"a b c\n((d e || x || d e || d __2__))\nf", []string{
+		`dead-alternative: alternative "d e" in`,
+		`dead-alternative: alternative "d e" in`,
+	}},
+	{"a b c\n((d e || d __2__))\nf", []string{`dead-alternative: alternative "d e"`}},
+	{"a b c\n((d || d e))\nf", nil},
+	{"a b c\n((is || are))\nf", []string{"dead-alternative"}}, // canonicalized to the same word
+	{"a b the\n((the))??\nf", []string{`duplicate-optional: optional "((the))??" repeats preceding words`}},
+	{"a b\n((c d))??\nc d e", []string{`duplicate-optional: optional "((c d))??" repeats following words`}},
+	{"a b\n((c))??\nd", nil},
+	{"a b 17 d\n((d))??\ne f", nil},         // list label
+	{"((c d))??\nc d e f", nil},             // title
+	{"a b\n((c d))??\n((c d e))??\nf", nil}, // alternate forms
+	{"a b __10__ c d e f", nil},
+	{"a b __10__ c\n((x))??\nd e f", nil}, // short delay
+	{"a b __10__ c\n((x))??\n((y))??\n((z))??\nd e f", []string{`delayed-cut: cut after __10__ delayed by optional text: up to 6 words instead of 3`}},
+	{"a b __10__\n((x y || z))\n((w))??\n((v))??\nc d e f", []string{`delayed-cut: cut after __10__ delayed by optional text: up to 6 words instead of 3`}},
+	{"a b __10__ c\n((x y z w))??\nd e f", nil},            // cut inside optional text
+	{"a b __2__\n((x))??\n((y))??\n((z))??\nc d e f", nil}, // no cut for short wildcards
+	{"a b\n((c d)){1,3}\ne f g h", nil},
+	{"a b\n((c d)){1,3}\ne\n((x))??\n((y))??\n((z))??\nf g h", []string{`delayed-cut: cut after repetition delayed by optional text: up to 6 words instead of 3`}},
+}
+
+func TestLint(t *testing.T) {
+	for i, tt := range lintTests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			var d Dict
+			re, err := ParseLRE(&d, "x.lre", tt.re)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diags := Lint([]*LRE{re}, nil, 0)
+			ok := len(diags) == len(tt.diags)
+			for j := 0; ok && j < len(diags); j++ {
+				ok = diags[j].File == "x.lre" && strings.HasPrefix(diags[j].Kind+": "+diags[j].Msg, tt.diags[j])
+			}
+			if !ok {
+				var have []string
+				for _, d := range diags {
+					have = append(have, d.String())
+				}
+				t.Errorf("Lint(%q):\nhave %q\nwant %q", tt.re, have, tt.diags)
+			}
+		})
+	}
+}
+
+func TestLintMulti(t *testing.T) {
+	var d Dict
+	var list []*LRE
+	for i, s := range []string{"a b c d", "a b __2__ d", "x\n((a || y))\nb z", "a b e f"} {
+		re, err := ParseLRE(&d, fmt.Sprintf("%d.lre", i), s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list = append(list, re)
+	}
+	var have []string
+	for _, d := range Lint(list[:2], list[2:], 5) {
+		have = append(have, d.String())
+	}
+	want := []string{
+		`0.lre: start-collision: leading phrase "a b" also starts 1.lre, which can match the same text`,
+		"1.lre: dfa-states: DFA has 8 states (> 5)",
+		`1.lre: start-collision: leading phrase "a b" also starts 0.lre, which can match the same text`,
+	}
+	if strings.Join(have, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Lint:\nhave:\n%s\nwant:\n%s", strings.Join(have, "\n"), strings.Join(want, "\n"))
+	}
+	if n := list[2].NumStates(); n != 5 {
+		t.Errorf("NumStates = %d, want 5", n)
+	}
+}
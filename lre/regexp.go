@@ -4,7 +4,7 @@
 
 // Exported LRE interface.
 
-package match
+package lre
 
 import (
 	"fmt"
@@ -12,47 +12,17 @@ import (
 )
 
 // An LRE is a compiled license regular expression.
-//
-// TODO: Move this comment somewhere non-internal later.
-//
-// A license regular expression (LRE) is a pattern syntax intended for
-// describing large English texts such as software licenses, with minor
-// allowed variations. The pattern syntax and the matching are word-based
-// and case-insensitive; punctuation is ignored in the pattern and in
-// the matched text.
-//
-// The valid LRE patterns are:
-//
-//	word            - a single case-insensitive word
-//	__N__           - any sequence of up to N words
-//	expr1 expr2     - concatenation
-//	expr1 || expr2  - alternation
-//	(( expr ))      - grouping
-//	expr??          - zero or one instances of expr
-//	//** text **//  - a comment
-//
-// To make patterns harder to misread in large texts:
-//
-//	- || must only appear inside (( ))
-//	- ?? must only follow (( ))
-//	- (( must be at the start of a line, preceded only by spaces
-//	- )) must be at the end of a line, followed only by spaces and ??.
-//
-// For example:
-//
-//	//** https://en.wikipedia.org/wiki/Filler_text **//
-//	Now is
-//	((not))??
-//	the time for all good
-//	((men || women || people))
-//	to come to the aid of their __1__.
-//
+// See the package documentation for the syntax.
 type LRE struct {
 	dict   *Dict
 	file   string
 	syntax *reSyntax
 	prog   reProg
 
+	// excludes lists the phrases that must not appear in
+	// or just after a match (see MultiLRE.Match).
+	excludes [][]WordID
+
 	onceDFA sync.Once
 	dfa     reDFA
 }
@@ -68,7 +38,7 @@ func ParseLRE(d *Dict, file, s string) (*LRE, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &LRE{dict: d, file: file, syntax: syntax, prog: prog}, nil
+	return &LRE{dict: d, file: file, syntax: syntax, prog: prog, excludes: syntax.excludes()}, nil
 }
 
 // Dict returns the Dict used by the LRE.
@@ -84,7 +54,7 @@ func (re *LRE) File() string {
 // Match reports whether text matches the license regexp.
 func (re *LRE) match(text string) bool {
 	re.onceDFA.Do(re.compile)
-	match, _ := re.dfa.match(re.dict, text, re.dict.Split(text))
+	match, _ := re.dfa.match(re.dict, text, re.dict.Split(text), SpellDefault, nil)
 	return match >= 0
 }
 
@@ -105,6 +75,13 @@ type MultiLRE struct {
 	// where a match can validly start,
 	// to allow for faster scans over non-license text.
 	start map[phrase]struct{}
+
+	// If any LRE has excluded phrases, list holds the LREs
+	// and byStart maps each start phrase to the indexes in list
+	// of the LREs that can start with it, so that Match can
+	// look for another match when one is ruled out.
+	list    []*LRE
+	byStart map[phrase][]int32
 }
 
 // A phrase is a phrase of up to two words.
@@ -133,7 +110,14 @@ func NewMultiLRE(list []*LRE) (_ *MultiLRE, err error) {
 	}
 
 	start := make(map[phrase]struct{})
+	var byStart map[phrase][]int32
 	for _, sub := range list {
+		if len(sub.excludes) > 0 {
+			byStart = make(map[phrase][]int32)
+			break
+		}
+	}
+	for i, sub := range list {
 		phrases := sub.syntax.leadingPhrases()
 		if len(phrases) == 0 {
 			return nil, fmt.Errorf("%s: no leading phrases", sub.File())
@@ -158,13 +142,23 @@ func NewMultiLRE(list []*LRE) (_ *MultiLRE, err error) {
 				return nil, fmt.Errorf("%s: invalid pattern: begins with wildcard phrase: %s __", sub.File(), dict.Words()[p[0]])
 			}
 			start[p] = struct{}{}
+			if byStart != nil {
+				if ids := byStart[p]; len(ids) == 0 || ids[len(ids)-1] != int32(i) {
+					byStart[p] = append(ids, int32(i))
+				}
+			}
 		}
 	}
 
 	prog := reCompileMulti(progs)
 	dfa := reCompileDFA(prog)
 
-	return &MultiLRE{dict, dfa, start}, nil
+	re := &MultiLRE{dict: dict, dfa: dfa, start: start}
+	if byStart != nil {
+		re.list = list
+		re.byStart = byStart
+	}
+	return re, nil
 }
 
 // Dict returns the Dict used by the MultiLRE.
@@ -174,9 +168,10 @@ func (re *MultiLRE) Dict() *Dict {
 
 // A Matches is a collection of all leftmost-longest, non-overlapping matches in text.
 type Matches struct {
-	Text  string  // the entire text
-	Words []Word  // the text, split into Words
-	List  []Match // the matches
+	Text        string       // the entire text
+	Words       []Word       // the text, split into Words
+	List        []Match      // the matches
+	Corrections []Correction // the misspellings accepted in the matches
 }
 
 // A Match records the position of a single match in a text.
@@ -186,22 +181,45 @@ type Match struct {
 	End   int // word index of end of match
 }
 
+// MatchOptions holds optional settings for MatchWithOptions.
+type MatchOptions struct {
+	// SpellCheck is the policy for accepting misspelled words.
+	SpellCheck SpellCheck
+}
+
 // Match reports all leftmost-longest, non-overlapping matches in text.
 // It always returns a non-nil *Matches, in order to return the split text.
 // Check len(matches.List) to see whether any matches were found.
 func (re *MultiLRE) Match(text string) *Matches {
+	return re.MatchWithOptions(text, MatchOptions{})
+}
+
+// MatchWithOptions is like Match but uses the settings in opts.
+func (re *MultiLRE) MatchWithOptions(text string, opts MatchOptions) *Matches {
 	m := &Matches{
 		Text:  text,
 		Words: re.dict.Split(text),
 	}
+	var fixes []Correction
 	p := phrase{BadWord, BadWord}
 	for i := 0; i < len(m.Words); i++ {
 		p[0], p[1] = p[1], m.Words[i].ID
 		if _, ok := re.start[p]; ok {
-			match, end := re.dfa.match(re.dict, text, m.Words[i-1:])
+			fixes = fixes[:0]
+			match, end := re.dfa.match(re.dict, text, m.Words[i-1:], opts.SpellCheck, &fixes)
 			if match >= 0 && end > 0 {
 				end += i - 1 // translate from index in m.Words[i-1:] to index in m.Words
+				if re.list != nil && re.list[match].excluded(m.Words, i-1, end) {
+					fixes = fixes[:0]
+					match, end = re.retry(m, i-1, match, opts.SpellCheck, &fixes)
+					if match < 0 {
+						continue
+					}
+				}
 				m.List = append(m.List, Match{ID: int(match), Start: i - 1, End: end})
+				for _, f := range fixes {
+					m.Corrections = append(m.Corrections, Correction{f.Start + i - 1, f.End + i - 1, f.Want})
+				}
 
 				// Continue search at end of match.
 				i = end - 1 // loop will i++
@@ -212,3 +230,58 @@ func (re *MultiLRE) Match(text string) *Matches {
 	}
 	return m
 }
+
+// excludeWindow is the number of words after a match
+// in which the LRE's excluded phrases must not appear either.
+const excludeWindow = 20
+
+// excluded reports whether any of re's excluded phrases appears
+// in words[start:end] or in the excludeWindow words after it.
+func (re *LRE) excluded(words []Word, start, end int) bool {
+	end += excludeWindow
+	if end > len(words) {
+		end = len(words)
+	}
+	for _, x := range re.excludes {
+	Search:
+		for i := start; i+len(x) <= end; i++ {
+			for j, w := range x {
+				if words[i+j].ID != w {
+					continue Search
+				}
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// retry looks for the best match starting at m.Words[start]
+// after the match of LRE number bad was ruled out by its excluded phrases.
+// It runs each other LRE that can start there on its own,
+// returning the longest match, with ties going to the earliest LRE,
+// as the full DFA would. If there is no such match, retry returns -1, 0.
+// Like reDFA.match, retry appends the match's corrections to *fixes.
+func (re *MultiLRE) retry(m *Matches, start int, bad int32, spell SpellCheck, fixes *[]Correction) (match int32, end int) {
+	match = -1
+	words := m.Words[start:]
+	var try []Correction
+	for _, id := range re.byStart[phrase{words[0].ID, words[1].ID}] {
+		sub := re.list[id]
+		if id == bad {
+			continue
+		}
+		sub.onceDFA.Do(sub.compile)
+		try = try[:0]
+		ok, e := sub.dfa.match(re.dict, m.Text, words, spell, &try)
+		if ok < 0 || e == 0 || start+e <= end {
+			continue
+		}
+		if sub.excluded(m.Words, start, start+e) {
+			continue
+		}
+		match, end = id, start+e
+		*fixes = append((*fixes)[:0], try...)
+	}
+	return match, end
+}
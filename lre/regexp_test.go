@@ -0,0 +1,97 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lre
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var multiMatchTests = []struct {
+	re   string
+	in   string
+	list []Match
+}{
+	{"a\n((b || c))\nd", `a b d`, []Match{{0, 0, 3}}},
+	{"a\n((b || c))\nd", `a b c d`, nil},
+	{"a b c / a\n((c || d))\ne", `a b c x a c e x a d e x`, []Match{{0, 0, 3}, {1, 4, 7}, {1, 8, 11}}},
+	{"a b c / a b c d / b c e", `a b c d e a b c b c e`, []Match{{1, 0, 4}, {0, 5, 8}, {2, 8, 11}}},
+
+	// Excluded phrases.
+	{"a b __3__ d\n((x y || z))!!\n / a b x y d", `a b x y d`, []Match{{1, 0, 5}}},
+	{"a b __3__ d\n((x y || z))!!\n / a b x y d", `a b x d`, []Match{{0, 0, 4}}},
+	{"a b __3__ d\n((x y || z))!!\n / a b x y d", `a b z d`, nil},
+	{"a b __3__ d\n((z))!!\n / a b", `a b z d`, []Match{{1, 0, 2}}},
+	{"a b c\n((z))!!", `a b c q z`, nil},
+	{"a b c\n((z))!!", `a b c q`, []Match{{0, 0, 3}}},
+	{"a b c\n((z))!!", `a b c ` + strings.Repeat("q ", 20) + `z`, []Match{{0, 0, 3}}},
+	{"a b c\n((z))!!\n / a b c", `a b c z a b c`, []Match{{1, 0, 3}, {0, 4, 7}}},
+}
+
+func TestMultiLREMatch(t *testing.T) {
+	var d Dict
+	for id, tt := range multiMatchTests {
+		t.Run(fmt.Sprint(id), func(t *testing.T) {
+			var list []*LRE
+			for _, expr := range strings.Split(tt.re, "/") {
+				re, err := ParseLRE(&d, "x", expr)
+				if err != nil {
+					t.Fatalf("Parse(%q): %v", expr, err)
+				}
+				list = append(list, re)
+			}
+			re, err := NewMultiLRE(list)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := re.Match(tt.in)
+			var mlist []Match
+			if m != nil {
+				if m.Text != tt.in {
+					t.Errorf("invalid text in m")
+				}
+				words := d.Split(tt.in)
+				if !reflect.DeepEqual(m.Words, words) {
+					t.Errorf("invalid words in m")
+				}
+				mlist = m.List
+			}
+			if !reflect.DeepEqual(mlist, tt.list) {
+				t.Errorf("incorrect match:\nhave %+v\nwant %+v", mlist, tt.list)
+			}
+		})
+	}
+}
+
+func TestMultiLRESegment(t *testing.T) {
+	for _, seg := range []Segmentation{SegmentChars, SegmentBigrams} {
+		var d Dict
+		d.SetSegmentation(seg)
+		re, err := ParseLRE(&d, "x", "本软件按原样提供 __5__ 不提供任何担保")
+		if err != nil {
+			t.Fatal(err)
+		}
+		multi, err := NewMultiLRE([]*LRE{re})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, tt := range []struct {
+			text  string
+			match bool
+		}{
+			{"前言。本软件按原样提供，不提供任何担保。", true},
+			{"本软件\n按原样提供，作者不提供任何担保。", true},
+			{"本软件按原样提供，作者在此声明不提供任何担保。", false},
+			{"本软件按原样提供，提供任何担保。", false},
+		} {
+			m := multi.Match(tt.text)
+			if match := len(m.List) == 1; match != tt.match {
+				t.Errorf("Segmentation %d: Match(%q) = %v, want match %v", seg, tt.text, m.List, tt.match)
+			}
+		}
+	}
+}
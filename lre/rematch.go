@@ -57,6 +57,8 @@
 //  - copy copies
 //
 // See the canonicalRewrites list in dict.go and its uses for the details.
+// Dict.Rewrite adds further rewrites, including multi-word ones,
+// for a particular Dict.
 // Regular singular/plural forms are handled by spell checking.
 //
 // Spell Checking
@@ -115,6 +117,13 @@
 // (Spell checking only applies inside a potential match that is already started,
 // but word canonicalization applies to every word in the file.)
 //
+// All of this describes the default policy, SpellDefault.
+// MultiLRE.MatchWithOptions can instead use SpellNone, which disables
+// spell checking entirely, or SpellLoose, which also accepts
+// transpositions, more edits in long words, and OCR confusions.
+// Either way, the accepted misspellings are reported in Matches.Corrections.
+// See spell.go for the implementation.
+//
 // Early-Cut Wildcard Matching
 //
 // This implementation adds "cut" operations to reduce the number
@@ -187,6 +196,12 @@
 // optional words or phrases, so some state blowup is still possible,
 // but not nearly as much.
 //
+// A bounded repetition ((x)){m,n} has the same problem as a wildcard:
+// the optional copies of x can match text that is also the start of
+// what follows the repetition. It gets the same cut, three literal words
+// after the repetition, cutting off any threads still matching the
+// optional copies.
+//
 // Overall, at time of writing, implicit cuts reduce the size of the
 // DFA for the full license set from 5.8M states (240 MB and 39s to build)
 // to 615k states (10 MB, under one second to build).
@@ -195,13 +210,15 @@
 // other NFA threads still matching a particular wildcard. The application
 // of instCut happens in (*nfaState).trim.
 
-package match
+package lre
 
 import (
 	"encoding/binary"
 	"fmt"
 	"sort"
 	"strings"
+
+	"github.com/google/licensecheck/internal/fold"
 )
 
 // A reProg is a regexp program: an instruction list.
@@ -284,8 +301,8 @@ func (c *reCompile) compile(re *reSyntax) {
 	default:
 		panic(fmt.Sprintf("unexpected re.op %d", re.op))
 
-	case opEmpty:
-		// nothing
+	case opEmpty, opExclude:
+		// nothing; exclusions are checked after matching (see MultiLRE.Match)
 
 	case opWords:
 		for _, w := range re.w {
@@ -352,6 +369,34 @@ func (c *reCompile) compile(re *reSyntax) {
 			c.prog[j].arg = int32(end - (j + 1))
 		}
 
+	case opRepeat:
+		// Compile as min copies of the subexpression followed by
+		// max-min nested optional copies, as if it were
+		//	x x ((x ((x))??))??
+		// for x{2,4}. As with opWild, nesting the optional copies
+		// makes all their alts jump to the end of the expression,
+		// so that a single cut can cut them all off.
+		endPattern := c.endPattern
+		for i := int32(0); i < re.min; i++ {
+			c.endPattern = endPattern && i+1 == re.min
+			c.compile(re.sub[0])
+		}
+		if opt := repeatOptional(re.sub[0], re.max-re.min); opt != nil {
+			start := len(c.prog)
+			c.endPattern = endPattern
+			c.compile(opt)
+			if !endPattern {
+				// Like a wildcard, the optional copies can match text
+				// that also matches the start of what follows.
+				// Cut them off three literal words later.
+				c.cut = c.mergeCut(c.cut, []reCut{{start: start, trigger: 3}})
+			}
+		}
+		c.endPattern = endPattern
+
+	case opUnordered:
+		c.unordered(re)
+
 	case opWild:
 		// All alts jump to the end of the expression, as if it were
 		//	(.(.(.(.)?)?)?)?
@@ -373,6 +418,84 @@ func (c *reCompile) compile(re *reSyntax) {
 	}
 }
 
+// unordered appends the compiled program for the unordered group re.
+//
+// Rather than compile every ordering of the n subexpressions,
+// which would take n! copies of each, unordered compiles one block
+// of code for each set of subexpressions that might have been matched
+// so far, ordered by set size. The block for set S chooses one of the
+// subexpressions x not in S, matches x, and jumps to the block for S+{x}.
+// The block for the full set is the end of the group.
+// This takes n * 2**(n-1) copies of the subexpressions in total.
+//
+// Pending cuts from before the group apply to the first subexpression
+// matched. Cuts arising inside a subexpression are emitted by the end of it,
+// since the code that follows is shared by many paths; for the same reason,
+// each subexpression must end in required text, as if it ended the pattern.
+func (c *reCompile) unordered(re *reSyntax) {
+	n := len(re.sub)
+	full := 1<<n - 1
+	cut := c.cut
+	endPattern := c.endPattern
+
+	// Lay out blocks by increasing set size, so that all jumps are forward.
+	var sets []int
+	for size := 0; size < n; size++ {
+		for set := 0; set < full; set++ {
+			if bitCount(set) == size {
+				sets = append(sets, set)
+			}
+		}
+	}
+
+	type jump struct{ pc, set int }
+	var jumps []jump
+	block := make(map[int]int)
+	for _, set := range sets {
+		block[set] = len(c.prog)
+		var choices []int
+		for i := 0; i < n; i++ {
+			if set&(1<<i) == 0 {
+				choices = append(choices, i)
+			}
+		}
+		for j, i := range choices {
+			alt := -1
+			if j+1 < len(choices) {
+				alt = len(c.prog)
+				c.prog = append(c.prog, reInst{op: instAlt})
+			}
+			c.cut = nil
+			if set == 0 {
+				c.cut = cut
+			}
+			c.endPattern = true
+			c.compile(re.sub[i])
+			c.compileCuts()
+			jumps = append(jumps, jump{len(c.prog), set | 1<<i})
+			c.prog = append(c.prog, reInst{op: instJump})
+			if alt >= 0 {
+				c.prog[alt].arg = int32(len(c.prog) - (alt + 1))
+			}
+		}
+	}
+	block[full] = len(c.prog)
+	for _, j := range jumps {
+		c.prog[j.pc].arg = int32(block[j.set] - (j.pc + 1))
+	}
+	c.cut = nil
+	c.endPattern = endPattern
+}
+
+// bitCount returns the number of 1 bits in x.
+func bitCount(x int) int {
+	n := 0
+	for ; x != 0; x &= x - 1 {
+		n++
+	}
+	return n
+}
+
 // compileCuts emits instCut instructions for all pending cuts.
 // See comment at top of file for information about cuts.
 func (c *reCompile) compileCuts() {
@@ -435,6 +558,17 @@ func (c *reCompile) mergeCut(cut1, cut2 []reCut) []reCut {
 // canMatchEmpty reports whether re can match an empty text.
 func canMatchEmpty(re *reSyntax) bool {
 	switch re.op {
+	case opRepeat:
+		return re.min == 0 || canMatchEmpty(re.sub[0])
+
+	case opUnordered:
+		for _, sub := range re.sub {
+			if !canMatchEmpty(sub) {
+				return false
+			}
+		}
+		return true
+
 	case opAlternate:
 		for _, sub := range re.sub {
 			if canMatchEmpty(sub) {
@@ -804,15 +938,41 @@ func (dfa reDFA) stateAt(off int32) (match int32, delta []int32) {
 var TraceDFA int
 
 // match looks for a match of DFA at the start of words,
-// which are the result of dict.Split(text) or a subslice of it.
+// which are the result of dict.Split(text) or a subslice of it,
+// accepting misspelled words according to spell.
 // match returns the match ID of the longest match, as well as
 // the index in words immediately following the last matched word.
 // If there is no match, match returns -1, 0.
-func (dfa reDFA) match(dict *Dict, text string, words []Word) (match int32, end int) {
+// If fixes is non-nil, match appends to *fixes the corrections
+// made in the returned match, with word indexes relative to words.
+func (dfa reDFA) match(dict *Dict, text string, words []Word, spell SpellCheck, fixes *[]Correction) (match int32, end int) {
 	match, end = -1, 0
 	off := int32(0) // offset of current state in DFA
 	dictWords := dict.Words()
 
+	// fix records a correction, which done discards
+	// if it turns out not to be part of the match.
+	nfix := 0
+	if fixes != nil {
+		nfix = len(*fixes)
+	}
+	fix := func(start, end int, want string) {
+		if fixes != nil {
+			*fixes = append(*fixes, Correction{start, end, want})
+		}
+	}
+	done := func() {
+		if fixes != nil {
+			list := (*fixes)[:nfix]
+			for _, f := range (*fixes)[nfix:] {
+				if f.End <= end {
+					list = append(list, f)
+				}
+			}
+			*fixes = list
+		}
+	}
+
 	// No range loop here: misspellings can adjust i.
 Words:
 	for i := 0; i < len(words); i++ {
@@ -846,57 +1006,61 @@ Words:
 		// We know the words that could usefully come next.
 		// Do any of those look enough like the word we have?
 		// TODO: Should the misspellings reduce the match percent?
+		if spell != SpellNone {
+			// have is the current word; have2 is the word after that.
+			have := fold.String(text[words[i].Lo:words[i].Hi])
+			have2 := ""
+			if i+1 < len(words) {
+				have2 = fold.String(text[words[i+1].Lo:words[i+1].Hi])
+			}
 
-		// have is the current word; have2 is the word after that.
-		have := toFold(text[words[i].Lo:words[i].Hi])
-		have2 := ""
-		if i+1 < len(words) {
-			have2 = toFold(text[words[i+1].Lo:words[i+1].Hi])
-		}
+			for j := 0; j < len(delta); j += 2 {
+				dw, dnext := WordID(delta[j]), delta[j+1]
+				want := dictWords[dw]
 
-		for j := 0; j < len(delta); j += 2 {
-			dw, dnext := WordID(delta[j]), delta[j+1]
-			want := dictWords[dw]
-
-			// Can we spell want by joining have and have2?
-			// This can happen with hyphenated line breaks.
-			if canMisspellJoin(want, have, have2) {
-				off = dnext
-				i++ // for have; loop will i++ again for have2
-				continue Words
-			}
+				// Can we spell want by joining have and have2?
+				// This can happen with hyphenated line breaks.
+				if canMisspellJoin(want, have, have2) {
+					fix(i, i+2, want)
+					off = dnext
+					i++ // for have; loop will i++ again for have2
+					continue Words
+				}
 
-			// misspell split
-			// Or can have be split into two words such that
-			// the pair is something we'd expect to see right now?
-			if len(have) > len(want) && have[:len(want)] == want {
-				// have[:len(want)] matches want.
-				// Look to see if have[len(want):] can be the word after want.
-				rest := have[len(want):]
-				m2, delta2 := dfa.stateAt(dnext)
-				next2 := int32(-1)
-				for j2 := 0; j2 < len(delta2); j2 += 2 {
-					dw2, dnext2 := WordID(delta2[j2]), delta2[j2+1]
-					if dw2 == AnyWord || dictWords[dw2] == rest {
-						next2 = dnext2
+				// misspell split
+				// Or can have be split into two words such that
+				// the pair is something we'd expect to see right now?
+				if len(have) > len(want) && have[:len(want)] == want {
+					// have[:len(want)] matches want.
+					// Look to see if have[len(want):] can be the word after want.
+					rest := have[len(want):]
+					m2, delta2 := dfa.stateAt(dnext)
+					next2 := int32(-1)
+					for j2 := 0; j2 < len(delta2); j2 += 2 {
+						dw2, dnext2 := WordID(delta2[j2]), delta2[j2+1]
+						if dw2 == AnyWord || dictWords[dw2] == rest {
+							next2 = dnext2
+						}
 					}
-				}
-				if next2 >= 0 {
-					// Successfully split have into two words
-					// to drive the DFA forward two steps.
-					if m2 >= 0 {
-						match = m2
-						end = i
+					if next2 >= 0 {
+						// Successfully split have into two words
+						// to drive the DFA forward two steps.
+						fix(i, i+1, want+" "+rest)
+						if m2 >= 0 {
+							match = m2
+							end = i
+						}
+						off = next2
+						continue Words
 					}
-					off = next2
-					continue Words
 				}
-			}
 
-			// Can we misspell want as have?
-			if canMisspell(want, have) {
-				off = dnext
-				continue Words
+				// Can we misspell want as have?
+				if spell.CanMisspell(want, have) {
+					fix(i, i+1, want)
+					off = dnext
+					continue Words
+				}
 			}
 		}
 
@@ -922,6 +1086,7 @@ Words:
 			}
 
 			// Return best match we found.
+			done()
 			return match, end
 		}
 		off = nextAny
@@ -938,6 +1103,7 @@ Words:
 		}
 		println("DFA ran out of input at «", text[words[start].Lo:], "|", "EOF", "»\n")
 	}
+	done()
 	return match, end
 }
 
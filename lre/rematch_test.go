@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package match
+package lre
 
 import (
 	"strings"
@@ -333,6 +333,53 @@ The name __10__ may not be used.
 25	cut [2, 22]
 26	word used
 27	match 0
+
+a ((b c)){1,3} d e f g
+0	word a
+1	word b
+2	word c
+3	alt 9
+4	word b
+5	word c
+6	alt 9
+7	word b
+8	word c
+9	word d
+10	word e
+11	word f
+12	cut [3, 9]
+13	word g
+14	match 0
+
+a ((b)){2}
+0	word a
+1	word b
+2	word b
+3	match 0
+
+x ((a && b c)) d
+0	word x
+1	alt 4
+2	word a
+3	jump 7
+4	word b
+5	word c
+6	jump 10
+7	word b
+8	word c
+9	jump 12
+10	word a
+11	jump 12
+12	word d
+13	match 0
+
+a ((b)){0,2}
+0	word a
+1	alt 5
+2	word b
+3	alt 5
+4	word b
+5	match 0
 `
 
 func TestCompile(t *testing.T) {
@@ -646,6 +693,24 @@ var matchTests = []struct {
 	{`a b ((__5__ c d e))??`, `a b X X X c d e`, 0, 8},
 
 	{`a b __5__ c d ((x?? y?? z z z || y w w w))`, `a b X X X c d y z z z`, 0, 11},
+
+	// Repetition
+	{`a ((b c)){1,3} d`, `a d`, -1, 0},
+	{`a ((b c)){1,3} d`, `a b c d`, 0, 4},
+	{`a ((b c)){1,3} d`, `a b c b c b c d`, 0, 8},
+	{`a ((b c)){1,3} d`, `a b c b c b c b c d`, -1, 0},
+	{`a ((b c)){2} d`, `a b c d`, -1, 0},
+	{`a ((b c)){2} d`, `a b c b c d`, 0, 6},
+	{`a ((b || c)){0,2}`, `a c b b`, 0, 3},
+
+	// Unordered groups
+	{`x ((a b && c && d e)) f`, `x a b c d e f`, 0, 7},
+	{`x ((a b && c && d e)) f`, `x d e a b c f`, 0, 7},
+	{`x ((a b && c && d e)) f`, `x c d e a b f`, 0, 7},
+	{`x ((a b && c && d e)) f`, `x c a b f`, -1, 0},
+	{`x ((a b && c && d e)) f`, `x c a b c d e f`, -1, 0},
+	{`x ((a __3__ b && c d e)) f`, `x c d e a X X b f`, 0, 9},
+	{`x ((a __3__ b && c d e)) f`, `x a X c d e f`, -1, 0},
 }
 
 func TestReDFAMatch(t *testing.T) {
@@ -656,7 +721,7 @@ func TestReDFAMatch(t *testing.T) {
 			continue
 		}
 		dfa := reCompileDFA(prog)
-		match, end := dfa.match(&d, tt.in, d.Split(tt.in))
+		match, end := dfa.match(&d, tt.in, d.Split(tt.in), SpellDefault, nil)
 		if match != tt.match || end != tt.end {
 			t.Errorf("reDFA(%q).match(%v) = %v, %v, want %v, %v", tt.re, tt.in, match, end, tt.match, tt.end)
 		}
@@ -3,9 +3,9 @@
 // license that can be found in the LICENSE file.
 
 // License regexp syntax and parsing
-// See LRE doc comment in regexp.go for syntax.
+// See the package doc comment in doc.go for syntax.
 
-package match
+package lre
 
 import (
 	"bytes"
@@ -62,9 +62,11 @@ func reSyntaxError(s string, i int, err error) error {
 // A reSyntax is a regexp syntax tree.
 type reSyntax struct {
 	op  reOp        // opcode
-	sub []*reSyntax // subexpressions (opConcat, opAlternate, opWild, opQuest)
+	sub []*reSyntax // subexpressions (opConcat, opAlternate, opWild, opQuest, opRepeat, opUnordered, opExclude)
 	w   []WordID    // words (opWords)
 	n   int32       // wildcard count (opWild)
+	min int32       // minimum repetition count (opRepeat)
+	max int32       // maximum repetition count (opRepeat)
 }
 
 // A reOp is the opcode for a regexp syntax tree node.
@@ -78,11 +80,15 @@ const (
 	opAlternate
 	opWild
 	opQuest
+	opRepeat
+	opUnordered
+	opExclude // excluded phrases; matches empty text
 
 	// pseudo-ops during parsing
 	opPseudo
 	opLeftParen
 	opVerticalBar
+	opAmpersand
 )
 
 // string returns a text form for the regexp syntax.
@@ -137,6 +143,28 @@ func rePrint(b *bytes.Buffer, re *reSyntax, d *Dict) {
 		}
 		b.WriteString("))\n")
 
+	case opUnordered:
+		nl(b)
+		b.WriteString("((")
+		for i, sub := range re.sub {
+			if i > 0 {
+				b.WriteString(" && ")
+			}
+			rePrint(b, sub, d)
+		}
+		b.WriteString("))\n")
+
+	case opExclude:
+		nl(b)
+		b.WriteString("((")
+		for i, sub := range re.sub {
+			if i > 0 {
+				b.WriteString(" || ")
+			}
+			rePrint(b, sub, d)
+		}
+		b.WriteString("))!!\n")
+
 	case opWild:
 		fmt.Fprintf(b, "__%d__", re.n)
 
@@ -153,6 +181,19 @@ func rePrint(b *bytes.Buffer, re *reSyntax, d *Dict) {
 		}
 		b.WriteString("??\n")
 
+	case opRepeat:
+		sub := re.sub[0]
+		nl(b)
+		if sub.op == opAlternate {
+			rePrint(b, sub, d)
+			b.Truncate(b.Len() - 1) // strip \n
+		} else {
+			b.WriteString("((")
+			rePrint(b, sub, d)
+			b.WriteString("))")
+		}
+		fmt.Fprintf(b, "{%d,%d}\n", re.min, re.max)
+
 	case opWords:
 		if len(re.w) == 0 {
 			b.WriteString("«empty opWords»")
@@ -214,14 +255,25 @@ func reParse(d *Dict, s string, strict bool) (*reSyntax, error) {
 			i += 2
 			start = i
 
+		case strings.HasPrefix(s[i:], "&&"):
+			if strict && parens == 0 {
+				return nil, reSyntaxError(s, i, fmt.Errorf("&& outside (( ))"))
+			}
+			p.words(s[start:i], "&&")
+			if err := p.ampersand(); err != nil {
+				return nil, reSyntaxError(s, i, err)
+			}
+			i += 2
+			start = i
+
 		case strings.HasPrefix(s[i:], "))"):
-			// )) must be followed by ?? or end line
+			// )) must be followed by ??, !!, {m,n}, or end line
+			j := i + 2
+			for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
+				j++
+			}
 			if strict {
-				j := i + 2
-				for j < len(s) && (s[j] == ' ' || s[j] == '\t') {
-					j++
-				}
-				if j < len(s) && s[j] != '\n' && (j+1 >= len(s) || s[j] != '?' || s[j+1] != '?') {
+				if j < len(s) && s[j] != '\n' && s[j] != '{' && !strings.HasPrefix(s[j:], "??") && !strings.HasPrefix(s[j:], "!!") {
 					return nil, reSyntaxError(s, i, fmt.Errorf(")) not at end of line"))
 				}
 			}
@@ -234,6 +286,44 @@ func reParse(d *Dict, s string, strict bool) (*reSyntax, error) {
 			start = i
 			parens--
 
+			// )){m,n} must end the line.
+			if j < len(s) && s[j] == '{' {
+				k := strings.Index(s[j:], "}")
+				if k < 0 {
+					return nil, reSyntaxError(s, j, errors.New("missing } in repetition"))
+				}
+				min, max, err := parseRepeat(s[j : j+k+1])
+				if err != nil {
+					return nil, reSyntaxError(s, j, err)
+				}
+				if strict && !atEOL(s, j+k+1) {
+					return nil, reSyntaxError(s, j, fmt.Errorf("%s not at end of line", s[j:j+k+1]))
+				}
+				if err := p.repeat(min, max); err != nil {
+					return nil, reSyntaxError(s, j, err)
+				}
+				i = j + k + 1
+				start = i
+			}
+
+			// ((phrases))!! must end the line and cannot be nested.
+			if strings.HasPrefix(s[j:], "!!") {
+				if strict && !atEOL(s, j+2) {
+					return nil, reSyntaxError(s, j, fmt.Errorf("!! not at end of line"))
+				}
+				if parens > 0 {
+					return nil, reSyntaxError(s, j, fmt.Errorf("!! inside (( ))"))
+				}
+				if err := p.exclude(); err != nil {
+					return nil, reSyntaxError(s, j, err)
+				}
+				i = j + 2
+				start = i
+			}
+
+		case strings.HasPrefix(s[i:], "!!"):
+			return nil, reSyntaxError(s, i, fmt.Errorf("!! not preceded by ))"))
+
 		case strings.HasPrefix(s[i:], "??"):
 			// ?? must be preceded by )) on same line and must end the line.
 			if strict {
@@ -293,12 +383,9 @@ func reParse(d *Dict, s string, strict bool) (*reSyntax, error) {
 	}
 
 	p.words(s[start:], "")
-	p.concat()
-	if p.swapVerticalBar() {
-		// pop vertical bar
-		p.stack = p.stack[:len(p.stack)-1]
+	if err := p.group(); err != nil {
+		return nil, reSyntaxError(s, len(s), err)
 	}
-	p.alternate()
 
 	n := len(p.stack)
 	if n != 1 {
@@ -372,22 +459,41 @@ func (p *reParser) verticalBar() error {
 	// If it sits above an opVerticalBar, swap it below
 	// (things below an opVerticalBar become an alternation).
 	// Otherwise, push a new vertical bar.
-	if !p.swapVerticalBar() {
+	if p.swapSeparator(opAmpersand) {
+		return errMixedSeparators
+	}
+	if !p.swapSeparator(opVerticalBar) {
 		p.push(&reSyntax{op: opVerticalBar})
 	}
 
 	return nil
 }
 
-// If the top of the stack is an element followed by an opVerticalBar
-// swapVerticalBar swaps the two and returns true.
+// ampersand handles a && in the input.
+// It works like verticalBar, except that the things below
+// an opAmpersand become an unordered group.
+func (p *reParser) ampersand() error {
+	p.concat()
+	if p.swapSeparator(opVerticalBar) {
+		return errMixedSeparators
+	}
+	if !p.swapSeparator(opAmpersand) {
+		p.push(&reSyntax{op: opAmpersand})
+	}
+	return nil
+}
+
+var errMixedSeparators = errors.New("|| and && mixed in (( ))")
+
+// If the top of the stack is an element followed by the separator op
+// (opVerticalBar or opAmpersand), swapSeparator swaps the two and returns true.
 // Otherwise it returns false.
-func (p *reParser) swapVerticalBar() bool {
+func (p *reParser) swapSeparator(op reOp) bool {
 	n := len(p.stack)
 	if n >= 2 {
 		re1 := p.stack[n-1]
 		re2 := p.stack[n-2]
-		if re2.op == opVerticalBar {
+		if re2.op == op {
 			p.stack[n-2] = re1
 			p.stack[n-1] = re2
 			return true
@@ -396,14 +502,27 @@ func (p *reParser) swapVerticalBar() bool {
 	return false
 }
 
-// rightParen handles a )) in the input.
-func (p *reParser) rightParen() error {
+// group replaces the top of the stack (above the topmost '((')
+// with its alternation or unordered group, depending on the separator used.
+func (p *reParser) group() error {
 	p.concat()
-	if p.swapVerticalBar() {
+	if p.swapSeparator(opVerticalBar) {
 		// pop vertical bar
 		p.stack = p.stack[:len(p.stack)-1]
+	} else if p.swapSeparator(opAmpersand) {
+		// pop ampersand
+		p.stack = p.stack[:len(p.stack)-1]
+		return p.unordered()
 	}
 	p.alternate()
+	return nil
+}
+
+// rightParen handles a )) in the input.
+func (p *reParser) rightParen() error {
+	if err := p.group(); err != nil {
+		return err
+	}
 
 	n := len(p.stack)
 	if n < 2 {
@@ -439,6 +558,88 @@ func (p *reParser) quest() error {
 	return nil
 }
 
+// maxRepeat is the largest count allowed in a )){m,n} repetition.
+// Each possible repetition is compiled as a separate copy of the
+// repeated expression, so large counts make large programs.
+const maxRepeat = 10
+
+// parseRepeat parses a repetition suffix {m,n} or {n}
+// and returns the minimum and maximum counts.
+func parseRepeat(s string) (min, max int32, err error) {
+	bad := fmt.Errorf("invalid repetition %s", s)
+	f := strings.Split(strings.TrimSuffix(strings.TrimPrefix(s, "{"), "}"), ",")
+	if len(f) > 2 {
+		return 0, 0, bad
+	}
+	var n [2]int
+	for i, x := range f {
+		n[i], err = strconv.Atoi(strings.TrimSpace(x))
+		if err != nil {
+			return 0, 0, bad
+		}
+	}
+	if len(f) == 1 {
+		n[1] = n[0]
+	}
+	if n[0] < 0 || n[1] < 1 || n[0] > n[1] {
+		return 0, 0, bad
+	}
+	if n[1] > maxRepeat {
+		return 0, 0, fmt.Errorf("repetition count %d too large (max %d)", n[1], maxRepeat)
+	}
+	return int32(n[0]), int32(n[1]), nil
+}
+
+// repeat replaces the top stack element with itself repeated min to max times.
+func (p *reParser) repeat(min, max int32) error {
+	n := len(p.stack)
+	if n == 0 || p.stack[n-1].op >= opPseudo {
+		return fmt.Errorf("missing argument to repetition")
+	}
+	p.stack[n-1] = &reSyntax{op: opRepeat, sub: []*reSyntax{p.stack[n-1]}, min: min, max: max}
+	return nil
+}
+
+// repeatOptional returns the syntax for n nested optional copies of sub,
+// as in ((sub ((sub ((sub))??))??))?? for n = 3.
+// It returns nil if n is 0.
+func repeatOptional(sub *reSyntax, n int32) *reSyntax {
+	var re *reSyntax
+	for i := int32(0); i < n; i++ {
+		if re == nil {
+			re = &reSyntax{op: opQuest, sub: []*reSyntax{sub}}
+			continue
+		}
+		cat := &reSyntax{op: opConcat}
+		if sub.op == opConcat {
+			cat.sub = append(cat.sub, sub.sub...)
+		} else {
+			cat.sub = append(cat.sub, sub)
+		}
+		cat.sub = append(cat.sub, re)
+		re = &reSyntax{op: opQuest, sub: []*reSyntax{cat}}
+	}
+	return re
+}
+
+// expand returns the syntax for the repetition re (an opRepeat)
+// written without repetition, as min copies of re.sub[0]
+// followed by max-min nested optional copies.
+func (re *reSyntax) expand() *reSyntax {
+	sub := re.sub[0]
+	cat := &reSyntax{op: opConcat}
+	for i := int32(0); i < re.min; i++ {
+		cat.sub = append(cat.sub, sub)
+	}
+	if opt := repeatOptional(sub, re.max-re.min); opt != nil {
+		cat.sub = append(cat.sub, opt)
+	}
+	if len(cat.sub) == 1 {
+		return cat.sub[0]
+	}
+	return cat
+}
+
 // concat replaces the top of the stack (above the topmost '||' or '((') with its concatenation.
 func (p *reParser) concat() *reSyntax {
 	// Scan down to find pseudo-operator || or ((.
@@ -471,6 +672,63 @@ func (p *reParser) alternate() *reSyntax {
 	return p.push(p.collapse(opAlternate, subs))
 }
 
+// exclude replaces the top stack element, which must be a list of
+// alternative phrases, with an opExclude of those phrases.
+func (p *reParser) exclude() error {
+	n := len(p.stack)
+	if n == 0 || p.stack[n-1].op >= opPseudo {
+		return fmt.Errorf("missing argument to !!")
+	}
+	sub := p.stack[n-1]
+	alts := []*reSyntax{sub}
+	if sub.op == opAlternate {
+		alts = sub.sub
+	}
+	for _, alt := range alts {
+		if alt.op != opWords {
+			return fmt.Errorf("((phrases))!! must contain only words")
+		}
+	}
+	p.stack[n-1] = &reSyntax{op: opExclude, sub: alts}
+	return nil
+}
+
+// excludes returns the phrases excluded by opExclude nodes in re.
+func (re *reSyntax) excludes() [][]WordID {
+	var list [][]WordID
+	if re.op == opExclude {
+		for _, sub := range re.sub {
+			list = append(list, sub.w)
+		}
+		return list
+	}
+	for _, sub := range re.sub {
+		list = append(list, sub.excludes()...)
+	}
+	return list
+}
+
+// maxUnordered is the largest number of subexpressions allowed
+// in an unordered (( a && b )) group. A group of n subexpressions
+// compiles to n * 2**(n-1) copies of them.
+const maxUnordered = 5
+
+// unordered replaces the top of the stack (above the topmost '((')
+// with an unordered group of its elements.
+func (p *reParser) unordered() error {
+	i := len(p.stack)
+	for i > 0 && p.stack[i-1].op < opPseudo {
+		i--
+	}
+	subs := p.stack[i:]
+	if len(subs) > maxUnordered {
+		return fmt.Errorf("too many && in (( )): %d clauses (max %d)", len(subs), maxUnordered)
+	}
+	p.stack = p.stack[:i]
+	p.push(&reSyntax{op: opUnordered, sub: append([]*reSyntax(nil), subs...)})
+	return nil
+}
+
 // collapse returns the result of applying op to sub.
 // If sub contains op nodes, they all get hoisted up
 // so that there is never a concat of a concat or an
@@ -500,7 +758,7 @@ func (re *reSyntax) leadingPhrases() []phrase {
 	case opWild:
 		return []phrase{{BadWord, BadWord}, {AnyWord, BadWord}, {AnyWord, AnyWord}}
 
-	case opEmpty:
+	case opEmpty, opExclude:
 		return []phrase{{BadWord, BadWord}}
 
 	case opWords:
@@ -515,6 +773,25 @@ func (re *reSyntax) leadingPhrases() []phrase {
 		}
 		return []phrase{p}
 
+	case opRepeat:
+		return re.expand().leadingPhrases()
+
+	case opUnordered:
+		// Each subexpression can come first,
+		// followed by the others in any order.
+		var alts []*reSyntax
+		for i, sub := range re.sub {
+			var rest []*reSyntax
+			rest = append(rest, re.sub[:i]...)
+			rest = append(rest, re.sub[i+1:]...)
+			next := rest[0]
+			if len(rest) > 1 {
+				next = &reSyntax{op: opUnordered, sub: rest}
+			}
+			alts = append(alts, &reSyntax{op: opConcat, sub: []*reSyntax{sub, next}})
+		}
+		return (&reSyntax{op: opAlternate, sub: alts}).leadingPhrases()
+
 	case opQuest:
 		list := re.sub[0].leadingPhrases()
 		for _, l := range list {
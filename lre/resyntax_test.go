@@ -2,7 +2,7 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
-package match
+package lre
 
 import (
 	"fmt"
@@ -26,6 +26,12 @@ var reParseTests = []struct {
 	{in: "z \n(( w ))\n(( a b c )) ??\n", out: "z w\n((a b c))??"},
 	{in: "(( a __123__ c )) ??", out: "((a __123__ c))??"},
 	{in: "a b ((c ||| d e)) f", out: "a b\n((c || d e))\nf"},
+	{in: "a\n((b c)){1,3}\nd", out: "a\n((b c)){1,3}\nd"},
+	{in: "a\n((b || c)) { 2 }\nd", out: "a\n((b || c)){2,2}\nd"},
+	{in: "a ((b)){0,2} c", out: "a\n((b)){0,2}\nc"},
+	{in: "a\n((b c && d &&e))\nf", out: "a\n((b c && d && e))\nf"},
+	{in: "a b\n((c d || e))!!\nf", out: "a b\n((c d || e))!!\nf"},
+	{in: "a ((b && ((c || d)) )) e", out: "a\n((b &&\n((c || d))\n))\ne"},
 }
 
 func TestReParse(t *testing.T) {
@@ -66,6 +72,23 @@ var reParseErrorTests = []struct {
 	{"((b)) c", ")) not at end of line"},
 	{"a??", "?? not preceded by ))"},
 	{"((a))\n??", "?? not preceded by ))"},
+	{"((a)){1,2} b", "{1,2} not at end of line"},
+	{"((a)){1,2}??", "{1,2} not at end of line"},
+	{"((a)){1,2", "missing } in repetition"},
+	{"((a)){2,1}", "invalid repetition {2,1}"},
+	{"((a)){0}", "invalid repetition {0}"},
+	{"((a)){x}", "invalid repetition {x}"},
+	{"((a)){1,2,3}", "invalid repetition {1,2,3}"},
+	{"((a)){1,11}", "repetition count 11 too large"},
+	{"a && b", "&& outside (( ))"},
+	{"((a || b && c))", "|| and && mixed in (( ))"},
+	{"((a && b || c))", "|| and && mixed in (( ))"},
+	{"((a && b && c && d && e && f))", "too many && in (( )): 6 clauses (max 5)"},
+	{"a!!", "!! not preceded by ))"},
+	{"((a))!! b", "!! not at end of line"},
+	{"((\n((a))!!\n))", "!! inside (( ))"},
+	{"((a __1__ b))!!", "((phrases))!! must contain only words"},
+	{"((a\n((b))??\n))!!", "((phrases))!! must contain only words"},
 }
 
 func TestReParseError(t *testing.T) {
@@ -99,6 +122,12 @@ var phraseRETests = []struct {
 	{in: "a?? b c", out: "[[a b] [b c]]"},
 	{in: "((a __1__))?? b c", out: "[[a ?] [a b] [b c]]"},
 	{in: "a __20__", out: "[[a ?] [a]]"},
+	{in: "((a)){2,3} b", out: "[[a a]]"},
+	{in: "((a)){1,3} b", out: "[[a a] [a b]]"},
+	{in: "((a)){0,3} b c", out: "[[a a] [a b] [b c]]"},
+	{in: "((a && b c)) d", out: "[[a b] [b c]]"},
+	{in: "((a && b && c))", out: "[[a b] [a c] [b a] [b c] [c a] [c b]]"},
+	{in: "((x y))!! a ((z))!! b", out: "[[a b]]"},
 }
 
 func TestLeadingPhrases(t *testing.T) {
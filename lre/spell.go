@@ -0,0 +1,143 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Spell checking policies.
+
+package lre
+
+import "strings"
+
+// A SpellCheck is a policy for accepting misspelled words during a match.
+// See the spell checking discussion in rematch.go for the details
+// of the default policy.
+type SpellCheck int
+
+const (
+	// SpellDefault accepts a single-byte insertion, deletion, or replacement
+	// in words of at least four bytes, "c" and "copyright" for each other,
+	// a word split in two (as by hyphenation), and two words run together.
+	SpellDefault SpellCheck = iota
+
+	// SpellNone accepts no misspellings:
+	// every word must match exactly, after canonicalization.
+	SpellNone
+
+	// SpellLoose accepts everything SpellDefault does, as well as
+	// two transposed bytes in words of at least four bytes,
+	// up to two edits in words of at least eight bytes,
+	// and common OCR confusions, such as "rn" for "m" and "1" for "l".
+	SpellLoose
+)
+
+// A Correction records a misspelling accepted during a match:
+// the text of Matches.Words[Start:End] was read as Want.
+type Correction struct {
+	Start int    // word index of start of misspelled text
+	End   int    // word index of end of misspelled text
+	Want  string // the expected word, or words separated by spaces
+}
+
+// CanMisspell reports whether want can be misspelled as have under policy s,
+// using the same rules as spell checking during a match.
+// Both words must already be folded, as the words in a Dict are.
+// Words split by SegmentChars or SegmentBigrams are never misspelled:
+// a single-byte edit would change one character to an unrelated one.
+func (s SpellCheck) CanMisspell(want, have string) bool {
+	if isSegmentedWord(want) {
+		return false
+	}
+	switch s {
+	case SpellNone:
+		return false
+	case SpellLoose:
+		return canMisspell(want, have) || canMisspellLoose(want, have)
+	}
+	return canMisspell(want, have)
+}
+
+// canMisspellLoose reports whether want can be misspelled as have
+// under SpellLoose but not SpellDefault.
+// Both words have been converted to lowercase already.
+// Edits are counted after canonicalizing OCR confusions,
+// so that "rnodlfy" is one edit from "modify".
+func canMisspellLoose(want, have string) bool {
+	want, have = ocrFold(want), ocrFold(have)
+	if want == have {
+		return true
+	}
+	if len(want) < 4 && len(have) < 4 {
+		return false
+	}
+	max := 1
+	if len(want) >= 8 && len(have) >= 8 {
+		max = 2
+	}
+	return editDistance(want, have, max) <= max
+}
+
+// ocrReplacer rewrites letter sequences commonly confused by
+// optical character recognition to a single canonical form.
+var ocrReplacer = strings.NewReplacer(
+	"rn", "m",
+	"cl", "d",
+	"vv", "w",
+	"1", "l",
+	"0", "o",
+)
+
+// ocrFold returns w with OCR confusions canonicalized.
+func ocrFold(w string) string {
+	return ocrReplacer.Replace(w)
+}
+
+// editDistance returns the number of single-byte insertions, deletions,
+// replacements, and transpositions of adjacent bytes needed to turn a into b,
+// or max+1 if that number is greater than max.
+func editDistance(a, b string, max int) int {
+	if d := len(a) - len(b); d > max || -d > max {
+		return max + 1
+	}
+	// Rows of the dynamic programming table:
+	// prev2 and prev for a[:i-2] and a[:i-1], cur for a[:i].
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		least := cur[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := prev[j-1] + cost
+			if x := prev[j] + 1; x < d {
+				d = x
+			}
+			if x := cur[j-1] + 1; x < d {
+				d = x
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				if x := prev2[j-2] + 1; x < d {
+					d = x
+				}
+			}
+			cur[j] = d
+			if d < least {
+				least = d
+			}
+		}
+		if least > max {
+			return max + 1
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	if d := prev[len(b)]; d <= max {
+		return d
+	}
+	return max + 1
+}
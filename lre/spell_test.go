@@ -0,0 +1,112 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lre
+
+import (
+	"reflect"
+	"testing"
+)
+
+var editDistanceTests = []struct {
+	a, b string
+	max  int
+	d    int
+}{
+	{"license", "license", 1, 0},
+	{"license", "licence", 1, 1},
+	{"license", "licnese", 1, 1},
+	{"license", "lcense", 1, 1},
+	{"license", "liceense", 1, 1},
+	{"license", "lcnse", 1, 2},
+	{"license", "lcnse", 2, 2},
+	{"license", "lcns", 2, 3},
+	{"distribute", "distirbtue", 2, 2},
+	{"abc", "", 2, 3},
+}
+
+func TestEditDistance(t *testing.T) {
+	for _, tt := range editDistanceTests {
+		if d := editDistance(tt.a, tt.b, tt.max); d != tt.d {
+			t.Errorf("editDistance(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.max, d, tt.d)
+		}
+	}
+}
+
+var spellCheckTests = []struct {
+	want, have string
+	def, loose bool
+}{
+	{"network", "notwork", true, true},
+	{"notices", "notice", true, true},
+	{"copyright", "c", true, true},
+	{"software", "sofwtare", false, true},
+	{"merchantability", "merchantabliity", false, true},
+	{"merchantability", "merchntabilty", false, true},
+	{"modify", "rnodify", false, true},
+	{"modify", "rnodlfy", false, true},
+	{"of", "0f", false, true},
+	{"all", "a11", false, true},
+	{"and", "adn", false, false},
+	{"permission", "submission", false, false},
+}
+
+func TestSpellCheck(t *testing.T) {
+	for _, tt := range spellCheckTests {
+		if ok := SpellNone.CanMisspell(tt.want, tt.have); ok {
+			t.Errorf("SpellNone.CanMisspell(%q, %q) = true, want false", tt.want, tt.have)
+		}
+		if ok := SpellDefault.CanMisspell(tt.want, tt.have); ok != tt.def {
+			t.Errorf("SpellDefault.CanMisspell(%q, %q) = %v, want %v", tt.want, tt.have, ok, tt.def)
+		}
+		if ok := SpellLoose.CanMisspell(tt.want, tt.have); ok != tt.loose {
+			t.Errorf("SpellLoose.CanMisspell(%q, %q) = %v, want %v", tt.want, tt.have, ok, tt.loose)
+		}
+	}
+}
+
+func TestMatchSpellCheck(t *testing.T) {
+	var d Dict
+	re, err := ParseLRE(&d, "x", "permission is hereby granted to use and/or modify the noninfringement software")
+	if err != nil {
+		t.Fatal(err)
+	}
+	multi, err := NewMultiLRE([]*LRE{re})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exact := "Permission is hereby granted to use and/or modify the noninfringement software."
+	typos := "Permission is hereby grantd to use andor modify the non-infringement software."
+	ocr := "Permission is hereby granted to use and/or rnodify the noninfringement sofwtare."
+	tests := []struct {
+		spell SpellCheck
+		text  string
+		match bool
+		fixes []Correction
+	}{
+		{SpellNone, exact, true, nil},
+		{SpellNone, typos, false, nil},
+		{SpellDefault, typos, true, []Correction{
+			{3, 4, "granted"},
+			{6, 7, "and or"},
+			{9, 11, "noninfringement"},
+		}},
+		{SpellDefault, ocr, false, nil},
+		{SpellLoose, ocr, true, []Correction{
+			{8, 9, "modify"},
+			{11, 12, "software"},
+		}},
+	}
+	for _, tt := range tests {
+		m := multi.MatchWithOptions(tt.text, MatchOptions{SpellCheck: tt.spell})
+		if match := len(m.List) == 1; match != tt.match {
+			t.Errorf("MatchWithOptions(%q, %d): matches = %v, want match %v", tt.text, tt.spell, m.List, tt.match)
+			continue
+		}
+		if !reflect.DeepEqual(m.Corrections, tt.fixes) {
+			t.Errorf("MatchWithOptions(%q, %d): corrections = %v, want %v", tt.text, tt.spell, m.Corrections, tt.fixes)
+		}
+	}
+}
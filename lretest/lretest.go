@@ -0,0 +1,282 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lretest runs license test files against a license scanner,
+// so that authors of custom license regular expressions (.lre files)
+// can test them the same way this repository tests its built-in licenses.
+//
+// A test file, conventionally named Kind.tN, starts with a header
+// terminated by a blank line, followed by the test input.
+// The header starts with any number of comment lines beginning with #,
+// followed by the expected Coverage percent and then one line per
+// expected Match, giving the license ID, the start and end offsets,
+// and the word URL for URL matches:
+//
+//	# optional comment
+//	90.5%
+//	BSD-3-Clause 0,1234
+//	Apache-2.0 1300,$ URL
+//
+// An end offset of $ means the end of the test input.
+// See testdata/README in this repository for details.
+package lretest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// A Case is a single parsed test file.
+type Case struct {
+	File     string   // file name
+	Comments []string // leading comment lines, including the #
+	Want     licensecheck.Coverage
+	Text     []byte // test input, after the header
+}
+
+// A Result is the result of running a single Case.
+type Result struct {
+	*Case
+	Have licensecheck.Coverage
+
+	// Diff shows the differences between Want and Have,
+	// with lines prefixed by "-" for expected results missing from Have,
+	// "+" for unexpected results in Have, and two spaces for results in both.
+	// It is the empty string when the results agree.
+	Diff string
+}
+
+// Failed reports whether the result differs from the expected one.
+func (r *Result) Failed() bool {
+	return r.Diff != ""
+}
+
+// ParseCase parses the content of the test file with the given name.
+func ParseCase(file string, data []byte) (*Case, error) {
+	i := bytes.Index(data, []byte("\n\n"))
+	if i < 0 {
+		return nil, fmt.Errorf("%s: invalid test data file: no blank line terminating header", file)
+	}
+	hdr, text := strings.Split(string(data[:i]), "\n"), data[i+2:]
+	c := &Case{File: file, Text: text}
+
+	lineno := 1
+	for len(hdr) > 0 && strings.HasPrefix(hdr[0], "#") {
+		c.Comments = append(c.Comments, hdr[0])
+		hdr = hdr[1:]
+		lineno++
+	}
+	if len(hdr) < 1 {
+		return nil, fmt.Errorf("%s: header too short", file)
+	}
+
+	var err error
+	c.Want.Percent, err = parsePercent(hdr[0])
+	if err != nil {
+		return nil, fmt.Errorf("%s:%d: parsing percent: %v", file, lineno, err)
+	}
+	for _, line := range hdr[1:] {
+		lineno++
+		f := strings.Fields(line)
+		if len(f) != 2 && len(f) != 3 {
+			return nil, fmt.Errorf("%s:%d: bad match field count", file, lineno)
+		}
+		var m licensecheck.Match
+		m.ID = f[0]
+		m.Start, m.End, err = parseRange(f[1], len(text))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: parsing match range: %v", file, lineno, err)
+		}
+		if len(f) == 3 {
+			if f[2] != "URL" {
+				return nil, fmt.Errorf("%s:%d: field 2 should be omitted or should be 'URL'", file, lineno)
+			}
+			m.IsURL = true
+		}
+		c.Want.Match = append(c.Want.Match, m)
+	}
+	return c, nil
+}
+
+// Header returns the test file header recording the coverage cov
+// for the case's text, preserving the case's comments.
+// It does not include the blank line that ends the header.
+func (c *Case) Header(cov licensecheck.Coverage) string {
+	var buf bytes.Buffer
+	for _, line := range c.Comments {
+		fmt.Fprintf(&buf, "%s\n", line)
+	}
+	fmt.Fprintf(&buf, "%s\n", formatPercent(cov.Percent))
+	for _, m := range cov.Match {
+		fmt.Fprintf(&buf, "%s\n", formatMatch(m, len(c.Text)))
+	}
+	return buf.String()
+}
+
+// Run runs the case using scanner, or the built-in scanner if scanner is nil.
+func (c *Case) Run(scanner *licensecheck.Scanner) *Result {
+	r := &Result{Case: c}
+	if scanner != nil {
+		r.Have = scanner.Scan(c.Text)
+	} else {
+		r.Have = licensecheck.Scan(c.Text)
+	}
+
+	mismatch := false
+	var buf bytes.Buffer
+	have, want := r.Have, c.Want
+	if !matchPercent(have.Percent, want.Percent) {
+		fmt.Fprintf(&buf, "- %s\n+ %s\n", formatPercent(want.Percent), formatPercent(have.Percent))
+		mismatch = true
+	} else {
+		fmt.Fprintf(&buf, "  %s\n", formatPercent(have.Percent))
+	}
+
+	end := len(c.Text)
+	havem, wantm := have.Match, want.Match
+	for len(havem) > 0 || len(wantm) > 0 {
+		switch {
+		case len(havem) > 0 && (len(wantm) == 0 || havem[0].End < wantm[0].Start):
+			fmt.Fprintf(&buf, "+ %s\n", formatMatch(havem[0], end))
+			havem = havem[1:]
+			mismatch = true
+
+		case len(havem) > 0 && len(wantm) > 0 && matchMatch(havem[0], wantm[0]):
+			fmt.Fprintf(&buf, "  %s\n", formatMatch(havem[0], end))
+			havem = havem[1:]
+			wantm = wantm[1:]
+
+		default:
+			fmt.Fprintf(&buf, "- %s\n", formatMatch(wantm[0], end))
+			wantm = wantm[1:]
+			mismatch = true
+		}
+	}
+	if mismatch {
+		r.Diff = buf.String()
+	}
+	return r
+}
+
+// testFileRE matches the names of test files.
+var testFileRE = regexp.MustCompile(`\.t[0-9]+$`)
+
+// RunDir runs the test files in dir, those with names ending in .tN,
+// using scanner, or the built-in scanner if scanner is nil.
+// It returns the results in lexical order by file name.
+func RunDir(scanner *licensecheck.Scanner, dir string) ([]*Result, error) {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var results []*Result
+	for _, info := range infos {
+		if info.IsDir() || !testFileRE.MatchString(info.Name()) {
+			continue
+		}
+		file := filepath.Join(dir, info.Name())
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		c, err := ParseCase(file, data)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, c.Run(scanner))
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%s: no test files", dir)
+	}
+	return results, nil
+}
+
+// Update rewrites the header of the result's test file
+// to record the coverage actually found.
+func (r *Result) Update() error {
+	info, err := os.Stat(r.File)
+	if err != nil {
+		return err
+	}
+	data := []byte(r.Header(r.Have) + "\n")
+	data = append(data, r.Text...)
+	return ioutil.WriteFile(r.File, data, info.Mode())
+}
+
+// formatPercent formats a percentage as in a test file header.
+func formatPercent(p float64) string {
+	s := strconv.FormatFloat(p, 'f', 1, 64)
+	return strings.TrimSuffix(s, ".0") + "%"
+}
+
+// formatMatch formats the match m as in a test file header,
+// for a text of length end.
+func formatMatch(m licensecheck.Match, end int) string {
+	// Special case for EOF end position.
+	var hi string
+	if m.End == end {
+		hi = "$"
+	} else {
+		hi = fmt.Sprintf("%d", m.End)
+	}
+	s := fmt.Sprintf("%s %d,%s", m.ID, m.Start, hi)
+	if m.IsURL {
+		s += " URL"
+	}
+	return s
+}
+
+// parsePercent parses a percentage (float ending in %).
+func parsePercent(s string) (float64, error) {
+	if !strings.HasSuffix(s, "%") {
+		return 0, fmt.Errorf("missing %% suffix")
+	}
+	return strconv.ParseFloat(s[:len(s)-len("%")], 64)
+}
+
+// parseRange parses a start,end range (two decimals separated by a comma).
+// As a special case, the second decimal can be $ meaning end-of-file.
+func parseRange(s string, end int) (int, int, error) {
+	i := strings.Index(s, ",")
+	if i < 0 {
+		return 0, 0, fmt.Errorf("malformed range")
+	}
+	lo, err := strconv.Atoi(s[:i])
+	if err != nil {
+		return 0, 0, err
+	}
+	var hi int
+	if s[i+1:] == "$" {
+		hi = end
+	} else {
+		hi, err = strconv.Atoi(s[i+1:])
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	return lo, hi, nil
+}
+
+// matchPercent reports whether have matches want.
+// We require that they match to within 0.1.
+func matchPercent(have, want float64) bool {
+	return math.Abs(have-want) < 0.1
+}
+
+// matchMatch reports whether have matches want.
+func matchMatch(have, want licensecheck.Match) bool {
+	return have.ID == want.ID &&
+		have.Start == want.Start &&
+		have.End == want.End &&
+		have.IsURL == want.IsURL
+}
@@ -0,0 +1,118 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lretest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+func TestParseCase(t *testing.T) {
+	c, err := ParseCase("x.t1", []byte("# comment\n# more\n90.5%\nMIT 0,10\nApache-2.0 12,$ URL\n\n0123456789 see url\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Case{
+		File:     "x.t1",
+		Comments: []string{"# comment", "# more"},
+		Want: licensecheck.Coverage{Percent: 90.5, Match: []licensecheck.Match{
+			{ID: "MIT", Start: 0, End: 10},
+			{ID: "Apache-2.0", Start: 12, End: 19, IsURL: true},
+		}},
+		Text: []byte("0123456789 see url\n"),
+	}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("ParseCase = %+v, want %+v", c, want)
+	}
+	if h := c.Header(c.Want); h != "# comment\n# more\n90.5%\nMIT 0,10\nApache-2.0 12,$ URL\n" {
+		t.Errorf("Header = %q", h)
+	}
+}
+
+var parseErrorTests = []struct {
+	data string
+	err  string
+}{
+	{"100%\nMIT 0,$\n", "no blank line"},
+	{"# only\n\ntext", "header too short"},
+	{"100\n\ntext", "x.t1:1: parsing percent"},
+	{"# c\n100%\nMIT\n\ntext", "x.t1:3: bad match field count"},
+	{"100%\nMIT 0-4\n\ntext", "x.t1:2: parsing match range"},
+	{"100%\nMIT 0,4 url\n\ntext", "field 2 should be omitted"},
+}
+
+func TestParseCaseError(t *testing.T) {
+	for _, tt := range parseErrorTests {
+		_, err := ParseCase("x.t1", []byte(tt.data))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("ParseCase(%q) error = %v, want %q", tt.data, err, tt.err)
+		}
+	}
+}
+
+func TestRunDir(t *testing.T) {
+	mit, err := ioutil.ReadFile("../testdata/MIT.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(mit[strings.Index(string(mit), "\n\n")+2:])
+
+	dir, err := ioutil.TempDir("", "lretest-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"MIT.t1":  string(mit),
+		"MIT.t2":  "# wrong\n50%\nMIT 0,4\n\n" + text,
+		"README":  "not a test\n",
+		"notes.t": "not a test\n",
+	}
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := RunDir(nil, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("RunDir returned %d results, want 2", len(results))
+	}
+	if r := results[0]; r.Failed() {
+		t.Errorf("%s failed:\n%s", r.File, r.Diff)
+	}
+	r := results[1]
+	wantDiff := "- 50%\n+ 100%\n- MIT 0,4\n+ MIT 0,$\n"
+	if r.Diff != wantDiff {
+		t.Errorf("%s: Diff:\n%s\nwant:\n%s", r.File, r.Diff, wantDiff)
+	}
+
+	if err := r.Update(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(r.File)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# wrong\n100%\nMIT 0,$\n\n" + text; string(data) != want {
+		t.Errorf("after Update:\n%s\nwant:\n%s", data, want)
+	}
+	c, err := ParseCase(r.File, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := c.Run(nil); r.Failed() {
+		t.Errorf("after Update, %s failed:\n%s", r.File, r.Diff)
+	}
+}
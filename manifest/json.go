@@ -0,0 +1,109 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// JSON manifests: package.json and composer.json.
+
+package manifest
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// parsePackageJSON parses an npm package.json.
+//
+// The license field is normally an SPDX expression,
+// but it can also be "SEE LICENSE IN <file>" or "UNLICENSED",
+// and old packages use an object {"type": ..., "url": ...}
+// or a licenses list of those objects, meaning any one of them.
+func parsePackageJSON(d *Declaration, data []byte) error {
+	var pkg struct {
+		License  json.RawMessage
+		Licenses []json.RawMessage
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return err
+	}
+
+	var names []string
+	for _, raw := range append([]json.RawMessage{pkg.License}, pkg.Licenses...) {
+		if len(raw) == 0 {
+			continue
+		}
+		var s string
+		var obj struct {
+			Type string
+			URL  string
+		}
+		switch {
+		case json.Unmarshal(raw, &s) == nil:
+		case json.Unmarshal(raw, &obj) == nil:
+			s = obj.Type
+			if s == "" {
+				s = obj.URL
+			}
+		}
+		if s == "" {
+			continue
+		}
+		if file, ok := seeLicenseIn(s); ok {
+			d.Files = append(d.Files, file)
+			continue
+		}
+		if s == "UNLICENSED" {
+			// Proprietary; no license granted.
+			d.Names = append(d.Names, s)
+			continue
+		}
+		names = append(names, s)
+	}
+	if len(names) > 0 {
+		d.setAll("OR", names)
+	}
+	return nil
+}
+
+// seeLicenseIn reports whether s has the form "SEE LICENSE IN <file>",
+// returning the file name if so.
+func seeLicenseIn(s string) (string, bool) {
+	const prefix = "SEE LICENSE IN "
+	if len(s) > len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return strings.TrimSpace(s[len(prefix):]), true
+	}
+	return "", false
+}
+
+// parseComposerJSON parses a PHP Composer composer.json.
+// The license field is a string or a list of strings,
+// in which case any one of the licenses may be chosen.
+func parseComposerJSON(d *Declaration, data []byte) error {
+	var pkg struct {
+		License json.RawMessage
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return err
+	}
+	if len(pkg.License) == 0 {
+		return nil
+	}
+	var list []string
+	var s string
+	switch {
+	case json.Unmarshal(pkg.License, &s) == nil:
+		list = []string{s}
+	case json.Unmarshal(pkg.License, &list) == nil:
+	}
+	var names []string
+	for _, s := range list {
+		if s == "proprietary" {
+			d.Names = append(d.Names, s)
+			continue
+		}
+		names = append(names, s)
+	}
+	if len(names) > 0 {
+		d.setAll("OR", names)
+	}
+	return nil
+}
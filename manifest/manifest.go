@@ -0,0 +1,208 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package manifest extracts the licenses declared in package manifests,
+// such as package.json, Cargo.toml, and pom.xml.
+//
+// Each declaration is normalized to an SPDX license expression using the
+// licensecheck license IDs, so that it can be compared directly with the
+// licenses that licensecheck.Scan finds in the same package.
+//
+// The supported manifests are:
+//
+//	package.json               npm: license, licenses
+//	composer.json              Composer: license
+//	Cargo.toml                 Cargo: package.license, package.license-file
+//	pyproject.toml             Python: project.license, project.classifiers, tool.poetry
+//	PKG-INFO, METADATA         Python: License, License-Expression, Classifier
+//	pom.xml                    Maven: licenses
+//	*.gemspec                  RubyGems: license, licenses
+//	*.nuspec                   NuGet: license, licenseUrl
+//	DESCRIPTION                R: License
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// A Declaration describes the licenses declared by a single manifest.
+type Declaration struct {
+	// File is the manifest file name, as passed to Parse.
+	File string
+
+	// Format is the kind of manifest, one of the names listed in
+	// the package documentation: "package.json", "Cargo.toml", "pom.xml",
+	// "gemspec", "nuspec", and so on.
+	Format string
+
+	// Names lists the declared license names, IDs, and URLs,
+	// as written in the manifest.
+	Names []string
+
+	// Expression is an SPDX license expression for Names,
+	// using licensecheck IDs, or the empty string if Names
+	// could not all be recognized.
+	Expression string
+
+	// Confidence is the confidence in Expression.
+	// See licensecheck.ParseName.
+	Confidence licensecheck.Confidence
+
+	// Files lists license files named by the manifest,
+	// relative to the manifest's directory,
+	// like Cargo's license-file or R's "file LICENSE".
+	Files []string
+}
+
+// formats lists the parsers for the known manifest formats.
+var formats = []struct {
+	format string
+	match  func(base string) bool
+	parse  func(d *Declaration, data []byte) error
+}{
+	{"package.json", isName("package.json"), parsePackageJSON},
+	{"composer.json", isName("composer.json"), parseComposerJSON},
+	{"Cargo.toml", isName("Cargo.toml"), parseCargoTOML},
+	{"pyproject.toml", isName("pyproject.toml"), parsePyprojectTOML},
+	{"PKG-INFO", isName("PKG-INFO", "METADATA"), parsePkgInfo},
+	{"pom.xml", isPOM, parsePOM},
+	{"gemspec", isExt(".gemspec"), parseGemspec},
+	{"nuspec", isExt(".nuspec"), parseNuspec},
+	{"DESCRIPTION", isName("DESCRIPTION"), parseDESCRIPTION},
+}
+
+func isName(names ...string) func(string) bool {
+	return func(base string) bool {
+		for _, name := range names {
+			if base == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isExt(ext string) func(string) bool {
+	return func(base string) bool {
+		return strings.HasSuffix(base, ext) && len(base) > len(ext)
+	}
+}
+
+// isPOM reports whether base names a Maven POM:
+// pom.xml or a published artifact-version.pom.
+func isPOM(base string) bool {
+	return base == "pom.xml" || isExt(".pom")(base)
+}
+
+// IsManifest reports whether the file name names a known manifest format.
+// Only the final path element of name is considered.
+func IsManifest(name string) bool {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	for _, f := range formats {
+		if f.match(base) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses the manifest file with the given name and content
+// and returns its license declaration.
+// The name selects the manifest format (see IsManifest).
+// If the manifest declares no licenses, the result has no Names.
+func Parse(name string, data []byte) (*Declaration, error) {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	for _, f := range formats {
+		if f.match(base) {
+			d := &Declaration{File: name, Format: f.format}
+			if err := f.parse(d, data); err != nil {
+				return nil, fmt.Errorf("%s: %v", name, err)
+			}
+			return d, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: unknown manifest format", name)
+}
+
+// setAll sets d.Names to names and d.Expression to the combination
+// of their parsed IDs using op ("OR" or "AND").
+// If any name is not recognized, d.Expression is left empty.
+func (d *Declaration) setAll(op string, names []string) {
+	var exprs []string
+	conf := licensecheck.ExactConfidence
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		d.Names = append(d.Names, name)
+		p := licensecheck.ParseName(name)
+		if p.Confidence == licensecheck.NoConfidence {
+			if id, ok := urlID(name); ok {
+				p = licensecheck.ParsedName{ID: id, Confidence: licensecheck.HighConfidence}
+			}
+		}
+		if p.Confidence < conf {
+			conf = p.Confidence
+		}
+		exprs = append(exprs, p.ID)
+	}
+	if len(exprs) == 0 || conf == licensecheck.NoConfidence {
+		return
+	}
+	d.Expression = combine(op, exprs)
+	d.Confidence = conf
+}
+
+// combine returns the SPDX expression combining exprs with op.
+// Duplicate expressions are removed,
+// and compound expressions are parenthesized.
+func combine(op string, exprs []string) string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, x := range exprs {
+		if seen[x] {
+			continue
+		}
+		seen[x] = true
+		out = append(out, x)
+	}
+	if len(out) == 1 {
+		return out[0]
+	}
+	for i, x := range out {
+		if strings.Contains(x, " ") {
+			out[i] = "(" + x + ")"
+		}
+	}
+	return strings.Join(out, " "+op+" ")
+}
+
+// urlID returns the license ID for a known license URL.
+func urlID(u string) (string, bool) {
+	if !strings.HasPrefix(u, "http://") && !strings.HasPrefix(u, "https://") {
+		return "", false
+	}
+	for _, try := range []string{u, trimExt(u)} {
+		cov := licensecheck.Scan([]byte(try))
+		if len(cov.Match) == 1 && cov.Match[0].IsURL {
+			return cov.Match[0].ID, true
+		}
+	}
+	return "", false
+}
+
+// trimExt trims a trailing .txt, .html, .htm, or .php from the URL u.
+func trimExt(u string) string {
+	for _, ext := range []string{".txt", ".html", ".htm", ".php"} {
+		if strings.HasSuffix(strings.ToLower(u), ext) {
+			return u[:len(u)-len(ext)]
+		}
+	}
+	return u
+}
@@ -0,0 +1,351 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck"
+)
+
+var parseTests = []struct {
+	file  string
+	data  string
+	names []string
+	expr  string
+	conf  licensecheck.Confidence
+	files []string
+}{
+	// npm
+	{
+		file:  "package.json",
+		data:  `{"name": "x", "license": "MIT"}`,
+		names: []string{"MIT"},
+		expr:  "MIT",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file:  "package.json",
+		data:  `{"license": "(MIT OR Apache-2.0)"}`,
+		names: []string{"(MIT OR Apache-2.0)"},
+		expr:  "(MIT OR Apache-2.0)",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file:  "node_modules/x/package.json",
+		data:  `{"licenses": [{"type": "MIT", "url": "http://x"}, {"type": "GPL-2.0"}]}`,
+		names: []string{"MIT", "GPL-2.0"},
+		expr:  "MIT OR GPL-2.0",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file:  "package.json",
+		data:  `{"license": {"type": "ISC"}}`,
+		names: []string{"ISC"},
+		expr:  "ISC",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file:  "package.json",
+		data:  `{"license": "SEE LICENSE IN LICENSE.md"}`,
+		files: []string{"LICENSE.md"},
+	},
+	{
+		file:  "package.json",
+		data:  `{"license": "UNLICENSED"}`,
+		names: []string{"UNLICENSED"},
+	},
+	{
+		file: "package.json",
+		data: `{"name": "x"}`,
+	},
+
+	// Composer
+	{
+		file:  "composer.json",
+		data:  `{"license": ["LGPL-2.1-only", "GPL-3.0-or-later"]}`,
+		names: []string{"LGPL-2.1-only", "GPL-3.0-or-later"},
+		expr:  "LGPL-2.1-only OR GPL-3.0-or-later",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file:  "composer.json",
+		data:  `{"license": "proprietary"}`,
+		names: []string{"proprietary"},
+	},
+
+	// Cargo
+	{
+		file: "Cargo.toml",
+		data: `
+[package]
+name = "x"
+license = "MIT/Apache-2.0" # old style
+
+[dependencies]
+license = "not this"
+`,
+		names: []string{"MIT/Apache-2.0"},
+		expr:  "MIT OR Apache-2.0",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file: "Cargo.toml",
+		data: `
+[package]
+license-file = "LICENSE.txt"
+`,
+		files: []string{"LICENSE.txt"},
+	},
+
+	// pyproject.toml
+	{
+		file: "pyproject.toml",
+		data: `
+[project]
+name = "x"
+license = "BSD-3-Clause"
+license-files = ["LICEN[CS]E*"]
+`,
+		names: []string{"BSD-3-Clause"},
+		expr:  "BSD-3-Clause",
+		conf:  licensecheck.ExactConfidence,
+		files: []string{"LICEN[CS]E*"},
+	},
+	{
+		file: "pyproject.toml",
+		data: `
+[project]
+license = {text = "Custom"}
+classifiers = [
+    "Programming Language :: Python",
+    "License :: OSI Approved :: MIT License",
+]
+`,
+		names: []string{"Custom", "MIT License"},
+		expr:  "MIT",
+		conf:  licensecheck.HighConfidence,
+	},
+	{
+		file: "pyproject.toml",
+		data: `
+[tool.poetry]
+license = "Apache-2.0"
+`,
+		names: []string{"Apache-2.0"},
+		expr:  "Apache-2.0",
+		conf:  licensecheck.ExactConfidence,
+	},
+
+	// Python core metadata
+	{
+		file: "PKG-INFO",
+		data: `Metadata-Version: 2.1
+Name: x
+License: UNKNOWN
+Classifier: License :: OSI Approved :: GNU General Public License v2 or later (GPLv2+)
+Classifier: License :: OSI Approved :: MIT License
+
+License: this is the description
+`,
+		names: []string{"GNU General Public License v2 or later (GPLv2+)", "MIT License"},
+		expr:  "GPL-2.0-or-later OR MIT",
+		conf:  licensecheck.HighConfidence,
+	},
+	{
+		file: "x-1.0.dist-info/METADATA",
+		data: `Metadata-Version: 2.4
+License-Expression: MIT AND Apache-2.0
+License-File: LICENSE
+License-File: NOTICE
+`,
+		names: []string{"MIT AND Apache-2.0"},
+		expr:  "MIT AND Apache-2.0",
+		conf:  licensecheck.ExactConfidence,
+		files: []string{"LICENSE", "NOTICE"},
+	},
+	{
+		file: "PKG-INFO",
+		data: `Metadata-Version: 1.0
+License: Copyright (c) 2020 Someone
+        Permission is hereby granted...
+Classifier: License :: OSI Approved :: BSD License
+`,
+		names: []string{"BSD License"},
+		expr:  "BSD-3-Clause",
+		conf:  licensecheck.LowConfidence,
+	},
+
+	// Maven
+	{
+		file: "pom.xml",
+		data: `<?xml version="1.0" encoding="ISO-8859-1"?>
+<project xmlns="http://maven.apache.org/POM/4.0.0">
+  <licenses>
+    <license>
+      <name>The Apache Software License, Version 2.0</name>
+      <url>https://www.apache.org/licenses/LICENSE-2.0.txt</url>
+    </license>
+  </licenses>
+</project>`,
+		names: []string{"The Apache Software License, Version 2.0"},
+		expr:  "Apache-2.0",
+		conf:  licensecheck.HighConfidence,
+	},
+	{
+		file: "x-1.0.pom",
+		data: `<project><licenses><license>
+<name>Something Else</name>
+<url>http://www.opensource.org/licenses/MIT</url>
+</license></licenses></project>`,
+		names: []string{"http://www.opensource.org/licenses/MIT"},
+		expr:  "MIT",
+		conf:  licensecheck.HighConfidence,
+	},
+
+	// RubyGems
+	{
+		file: "x.gemspec",
+		data: `Gem::Specification.new do |s|
+  s.name = "x"
+  s.license = 'MIT'
+end
+`,
+		names: []string{"MIT"},
+		expr:  "MIT",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file: "x.gemspec",
+		data: `Gem::Specification.new do |spec|
+  spec.licenses = ["Ruby", "BSD-2-Clause"]
+end
+`,
+		names: []string{"Ruby", "BSD-2-Clause"},
+		expr:  "Ruby OR BSD-2-Clause",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file: "x.gemspec",
+		data: `Gem::Specification.new do |spec|
+  spec.licenses = %w[MIT Apache-2.0]
+end
+`,
+		names: []string{"MIT", "Apache-2.0"},
+		expr:  "MIT OR Apache-2.0",
+		conf:  licensecheck.ExactConfidence,
+	},
+
+	// NuGet
+	{
+		file: "x.nuspec",
+		data: `<package><metadata>
+<license type="expression">MIT</license>
+<licenseUrl>https://aka.ms/deprecateLicenseUrl</licenseUrl>
+</metadata></package>`,
+		names: []string{"MIT"},
+		expr:  "MIT",
+		conf:  licensecheck.ExactConfidence,
+	},
+	{
+		file: "x.nuspec",
+		data: `<package><metadata>
+<license type="file">docs\LICENSE.txt</license>
+</metadata></package>`,
+		files: []string{`docs\LICENSE.txt`},
+	},
+	{
+		file: "x.nuspec",
+		data: `<package><metadata>
+<licenseUrl>http://www.apache.org/licenses/LICENSE-2.0</licenseUrl>
+</metadata></package>`,
+		names: []string{"http://www.apache.org/licenses/LICENSE-2.0"},
+		expr:  "Apache-2.0",
+		conf:  licensecheck.HighConfidence,
+	},
+
+	// R
+	{
+		file: "DESCRIPTION",
+		data: `Package: x
+License: GPL (>= 2) | MIT + file LICENSE
+Title: X
+`,
+		names: []string{"GPL 2 or later", "MIT"},
+		expr:  "GPL-2.0-or-later OR MIT",
+		conf:  licensecheck.HighConfidence,
+		files: []string{"LICENSE"},
+	},
+	{
+		file: "DESCRIPTION",
+		data: `Package: x
+License: file LICENSE
+`,
+		files: []string{"LICENSE"},
+	},
+	{
+		file: "DESCRIPTION",
+		data: `Package: x
+License: Apache License (== 2.0) |
+    CC_BY_4.0
+`,
+		names: []string{"Apache License 2.0", "CC BY 4.0"},
+		expr:  "Apache-2.0 OR CC-BY-4.0",
+		conf:  licensecheck.HighConfidence,
+	},
+}
+
+func TestParse(t *testing.T) {
+	for _, tt := range parseTests {
+		d, err := Parse(tt.file, []byte(tt.data))
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.file, err)
+			continue
+		}
+		if !reflect.DeepEqual(d.Names, tt.names) || d.Expression != tt.expr || d.Confidence != tt.conf || !reflect.DeepEqual(d.Files, tt.files) {
+			t.Errorf("Parse(%q, %q) = %q, %q, %v, files %q\nwant %q, %q, %v, files %q",
+				tt.file, tt.data, d.Names, d.Expression, d.Confidence, d.Files,
+				tt.names, tt.expr, tt.conf, tt.files)
+		}
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, tt := range []struct{ file, data string }{
+		{"package.json", `{"license": `},
+		{"Cargo.toml", "[package\nlicense = \"MIT\""},
+		{"Cargo.toml", "[package]\nlicense = \"MIT"},
+		{"pom.xml", "<project><licenses>"},
+		{"README", "MIT"},
+	} {
+		if d, err := Parse(tt.file, []byte(tt.data)); err == nil || !strings.HasPrefix(err.Error(), tt.file+":") {
+			t.Errorf("Parse(%q, %q) = %+v, %v, want error", tt.file, tt.data, d, err)
+		}
+	}
+}
+
+func TestIsManifest(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		ok   bool
+	}{
+		{"package.json", true},
+		{"a/b/Cargo.toml", true},
+		{`a\b\pom.xml`, true},
+		{"x-1.0.pom", true},
+		{"foo.gemspec", true},
+		{".gemspec", false},
+		{"METADATA", true},
+		{"DESCRIPTION", true},
+		{"LICENSE", false},
+		{"package.json.orig", false},
+	} {
+		if ok := IsManifest(tt.name); ok != tt.ok {
+			t.Errorf("IsManifest(%q) = %v, want %v", tt.name, ok, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Python core metadata: PKG-INFO and METADATA.
+
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// parsePkgInfo parses Python core metadata,
+// the PKG-INFO file in an sdist or the METADATA file in a wheel.
+//
+// A License-Expression header (metadata 2.4) is an SPDX expression
+// and takes precedence. Otherwise the License header is used,
+// falling back to the "License ::" trove classifiers.
+// Many packages put the entire license text in the License header,
+// so a multi-line License is not treated as a name.
+func parsePkgInfo(d *Declaration, data []byte) error {
+	var expr, license string
+	var classifiers []string
+	multiline := false
+	var last *string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(nil, 1<<20)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			break // end of headers; description follows
+		}
+		if line[0] == ' ' || line[0] == '\t' {
+			if last == &license {
+				multiline = true
+			}
+			continue
+		}
+		last = nil
+		i := strings.Index(line, ":")
+		if i < 0 {
+			continue
+		}
+		key, val := line[:i], strings.TrimSpace(line[i+1:])
+		switch strings.ToLower(key) {
+		case "license-expression":
+			expr = val
+		case "license":
+			license = val
+			last = &license
+		case "classifier":
+			classifiers = append(classifiers, val)
+		case "license-file":
+			d.Files = append(d.Files, val)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+
+	if expr != "" {
+		d.setAll("OR", []string{expr})
+		return nil
+	}
+	var names []string
+	if license != "" && !multiline {
+		names = append(names, license)
+	}
+	d.setPython(names, classifiers)
+	return nil
+}
+
+// setPython sets d's names and expression from a Python license field
+// and list of trove classifiers.
+// The license field is used if it is recognized;
+// otherwise the license classifiers are used, meaning any one of them.
+func (d *Declaration) setPython(license, classifiers []string) {
+	var names []string
+	for _, name := range license {
+		name = strings.TrimSpace(name)
+		if name == "" || strings.EqualFold(name, "UNKNOWN") {
+			continue
+		}
+		names = append(names, name)
+	}
+
+	var trove []string
+	for _, c := range classifiers {
+		f := strings.Split(c, "::")
+		if len(f) < 2 || strings.TrimSpace(f[0]) != "License" {
+			continue
+		}
+		name := strings.TrimSpace(f[len(f)-1])
+		if name == "OSI Approved" || name == "Other/Proprietary License" {
+			continue
+		}
+		trove = append(trove, name)
+	}
+
+	if len(names) > 0 {
+		d.setAll("OR", names)
+		if d.Expression != "" || len(trove) == 0 {
+			return
+		}
+	}
+	if len(trove) > 0 {
+		save := d.Names
+		d.Names = nil
+		d.setAll("OR", trove)
+		d.Names = append(save, d.Names...)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Text manifests: .gemspec and R DESCRIPTION.
+
+package manifest
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// gemLicenseRE matches a license or licenses assignment in a gemspec,
+	// like "s.license = 'MIT'" or "spec.licenses = ['MIT', 'GPL-2.0']".
+	// Group 1 is "s" in licenses, and group 2 is the value,
+	// which continues to the end of the line or the closing bracket.
+	gemLicenseRE = regexp.MustCompile(`(?m)^\s*\w+\.licen[sc]e(s?)\s*=\s*(\[[^\]]*\]|%[wW][\[({][^\])}]*[\])}]|[^\n#]*)`)
+
+	// gemStringRE matches a quoted Ruby string.
+	gemStringRE = regexp.MustCompile(`"([^"\\]*)"|'([^'\\]*)'`)
+)
+
+// parseGemspec parses a RubyGems .gemspec.
+// The gemspec is Ruby code, but license declarations are nearly always
+// simple assignments of string literals, which is all that is recognized.
+// A licenses list means any one of the licenses may be chosen.
+func parseGemspec(d *Declaration, data []byte) error {
+	var names []string
+	for _, m := range gemLicenseRE.FindAllSubmatch(data, -1) {
+		val := strings.TrimSpace(string(m[2]))
+		if strings.HasPrefix(val, "%") {
+			// %w[MIT Apache-2.0]
+			names = append(names, strings.Fields(val[3:len(val)-1])...)
+			continue
+		}
+		for _, s := range gemStringRE.FindAllStringSubmatch(val, -1) {
+			names = append(names, s[1]+s[2])
+		}
+	}
+	if len(names) > 0 {
+		d.setAll("OR", names)
+	}
+	return nil
+}
+
+var (
+	// rVersionRE matches an R version restriction like "(>= 2)".
+	rVersionRE = regexp.MustCompile(`\(\s*(>=|>|==)?\s*([0-9.]+)\s*\)`)
+
+	// rFileRE matches an R license file reference,
+	// like "+ file LICENSE" or "file LICENCE".
+	rFileRE = regexp.MustCompile(`(?:^|\+)\s*file\s+(\S+)$`)
+)
+
+// parseDESCRIPTION parses an R package DESCRIPTION file.
+//
+// The License field lists alternatives separated by "|".
+// Each may carry a version restriction such as "GPL (>= 2)",
+// meaning that version or later, and may be augmented by
+// "+ file LICENSE" naming a file with additional terms.
+// A bare "file LICENSE" means the license is only in that file.
+func parseDESCRIPTION(d *Declaration, data []byte) error {
+	license := dcfField(string(data), "License")
+	if license == "" {
+		return nil
+	}
+	var names []string
+	for _, alt := range strings.Split(license, "|") {
+		alt = strings.TrimSpace(alt)
+		if m := rFileRE.FindStringSubmatchIndex(alt); m != nil {
+			d.Files = append(d.Files, alt[m[2]:m[3]])
+			alt = strings.TrimSpace(alt[:m[0]])
+		}
+		if alt == "" {
+			continue
+		}
+		alt = rVersionRE.ReplaceAllStringFunc(alt, func(v string) string {
+			m := rVersionRE.FindStringSubmatch(v)
+			if m[1] == ">=" || m[1] == ">" {
+				return m[2] + " or later"
+			}
+			return m[2]
+		})
+		// CRAN abbreviations use underscores: "Apache License 2.0" is
+		// "Apache License (== 2.0)", and "CC BY 4.0" is "CC_BY_4.0" in places.
+		alt = strings.ReplaceAll(alt, "_", " ")
+		names = append(names, alt)
+	}
+	if len(names) > 0 {
+		d.setAll("OR", names)
+	}
+	return nil
+}
+
+// dcfField returns the value of the named field in the
+// Debian control file format text, joining continuation lines.
+func dcfField(text, name string) string {
+	var val []string
+	in := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if in {
+			if line != "" && (line[0] == ' ' || line[0] == '\t') {
+				val = append(val, strings.TrimSpace(line))
+				continue
+			}
+			break
+		}
+		if i := strings.Index(line, ":"); i > 0 && strings.EqualFold(line[:i], name) {
+			val = append(val, strings.TrimSpace(line[i+1:]))
+			in = true
+		}
+	}
+	return strings.Join(val, " ")
+}
@@ -0,0 +1,404 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// TOML manifests: Cargo.toml and pyproject.toml.
+
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseCargoTOML parses a Rust Cargo.toml.
+// The package.license field is an SPDX expression,
+// though older crates use "/" to separate alternatives;
+// package.license-file names a license file.
+func parseCargoTOML(d *Declaration, data []byte) error {
+	t, err := parseTOML(data)
+	if err != nil {
+		return err
+	}
+	if s, ok := t["package.license"].(string); ok {
+		d.setAll("OR", []string{s})
+	}
+	if s, ok := t["package.license-file"].(string); ok && s != "" {
+		d.Files = append(d.Files, s)
+	}
+	return nil
+}
+
+// parsePyprojectTOML parses a Python pyproject.toml.
+//
+// The project.license field is an SPDX expression string (PEP 639)
+// or, in the older PEP 621 form, a table with a text or file key.
+// If the license is missing or not recognized, the "License ::"
+// trove classifiers are used instead, meaning any one of them.
+// Poetry projects use tool.poetry.license and tool.poetry.classifiers.
+func parsePyprojectTOML(d *Declaration, data []byte) error {
+	t, err := parseTOML(data)
+	if err != nil {
+		return err
+	}
+	for _, prefix := range []string{"project.", "tool.poetry."} {
+		var names []string
+		switch v := t[prefix+"license"].(type) {
+		case string:
+			names = append(names, v)
+		case map[string]interface{}:
+			if s, ok := v["text"].(string); ok {
+				names = append(names, s)
+			}
+			if s, ok := v["file"].(string); ok && s != "" {
+				d.Files = append(d.Files, s)
+			}
+		}
+		if files, ok := t[prefix+"license-files"].([]interface{}); ok {
+			for _, f := range files {
+				if s, ok := f.(string); ok && s != "" {
+					d.Files = append(d.Files, s)
+				}
+			}
+		}
+		var classifiers []string
+		if list, ok := t[prefix+"classifiers"].([]interface{}); ok {
+			for _, c := range list {
+				if s, ok := c.(string); ok {
+					classifiers = append(classifiers, s)
+				}
+			}
+		}
+		d.setPython(names, classifiers)
+		if len(d.Names) > 0 {
+			break
+		}
+	}
+	return nil
+}
+
+// parseTOML parses the subset of TOML used by package manifests:
+// tables, dotted keys, strings, numbers, booleans,
+// arrays (possibly spanning lines), and inline tables.
+// It returns the values keyed by full dotted name, like "package.license".
+// Arrays of tables ([[bin]]) are skipped.
+func parseTOML(data []byte) (map[string]interface{}, error) {
+	p := &tomlParser{s: string(data), line: 1}
+	t := make(map[string]interface{})
+	prefix := ""
+	skip := false
+	for {
+		p.skipSpace(true)
+		if p.eof() {
+			break
+		}
+		switch {
+		case strings.HasPrefix(p.s, "[["):
+			p.skipLine()
+			skip = true
+		case p.s[0] == '[':
+			end := strings.IndexByte(p.s, ']')
+			if end < 0 {
+				return nil, p.errorf("unterminated table header")
+			}
+			keys, err := splitTOMLKey(p.s[1:end])
+			if err != nil {
+				return nil, p.errorf("%v", err)
+			}
+			prefix = strings.Join(keys, ".") + "."
+			p.s = p.s[end+1:]
+			skip = false
+			p.skipLine()
+		default:
+			key, val, err := p.keyValue()
+			if err != nil {
+				return nil, err
+			}
+			if !skip {
+				t[prefix+key] = val
+			}
+			p.skipLine()
+		}
+	}
+	return t, nil
+}
+
+type tomlParser struct {
+	s    string
+	line int
+}
+
+func (p *tomlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *tomlParser) eof() bool { return p.s == "" }
+
+// skipSpace skips spaces and comments,
+// and also newlines if newlines is true.
+func (p *tomlParser) skipSpace(newlines bool) {
+	for p.s != "" {
+		switch c := p.s[0]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.s = p.s[1:]
+		case c == '\n' && newlines:
+			p.s = p.s[1:]
+			p.line++
+		case c == '#':
+			i := strings.IndexByte(p.s, '\n')
+			if i < 0 {
+				i = len(p.s)
+			}
+			p.s = p.s[i:]
+		default:
+			return
+		}
+	}
+}
+
+// skipLine skips the rest of the current line.
+// Only a comment may follow the value on the line,
+// but stray text is ignored rather than reported.
+func (p *tomlParser) skipLine() {
+	i := strings.IndexByte(p.s, '\n')
+	if i < 0 {
+		p.s = ""
+		return
+	}
+	p.s = p.s[i+1:]
+	p.line++
+}
+
+// keyValue parses a key = value pair.
+func (p *tomlParser) keyValue() (string, interface{}, error) {
+	i := strings.IndexByte(p.s, '=')
+	if j := strings.IndexByte(p.s, '\n'); i < 0 || j >= 0 && j < i {
+		return "", nil, p.errorf("expected key = value")
+	}
+	keys, err := splitTOMLKey(p.s[:i])
+	if err != nil {
+		return "", nil, p.errorf("%v", err)
+	}
+	p.s = p.s[i+1:]
+	p.skipSpace(false)
+	val, err := p.value()
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.Join(keys, "."), val, nil
+}
+
+// splitTOMLKey splits a possibly dotted, possibly quoted key.
+func splitTOMLKey(s string) ([]string, error) {
+	var keys []string
+	s = strings.TrimSpace(s)
+	for s != "" {
+		var key string
+		if s[0] == '"' || s[0] == '\'' {
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted key")
+			}
+			key, s = s[1:1+end], s[2+end:]
+		} else {
+			i := strings.IndexByte(s, '.')
+			if i < 0 {
+				i = len(s)
+			}
+			key, s = strings.TrimSpace(s[:i]), s[i:]
+		}
+		if key == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+		keys = append(keys, key)
+		s = strings.TrimSpace(s)
+		if s != "" {
+			if s[0] != '.' {
+				return nil, fmt.Errorf("malformed key")
+			}
+			s = strings.TrimSpace(s[1:])
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("empty key")
+	}
+	return keys, nil
+}
+
+// value parses a single TOML value.
+func (p *tomlParser) value() (interface{}, error) {
+	if p.eof() {
+		return nil, p.errorf("missing value")
+	}
+	switch p.s[0] {
+	case '"', '\'':
+		return p.str()
+	case '[':
+		p.s = p.s[1:]
+		var list []interface{}
+		for {
+			p.skipSpace(true)
+			if p.eof() {
+				return nil, p.errorf("unterminated array")
+			}
+			if p.s[0] == ']' {
+				p.s = p.s[1:]
+				return list, nil
+			}
+			v, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+			p.skipSpace(true)
+			switch {
+			case strings.HasPrefix(p.s, ","):
+				p.s = p.s[1:]
+			case !strings.HasPrefix(p.s, "]"):
+				return nil, p.errorf("expected , or ] in array")
+			}
+		}
+	case '{':
+		p.s = p.s[1:]
+		m := make(map[string]interface{})
+		for {
+			p.skipSpace(false)
+			if p.eof() {
+				return nil, p.errorf("unterminated inline table")
+			}
+			if p.s[0] == '}' {
+				p.s = p.s[1:]
+				return m, nil
+			}
+			key, v, err := p.keyValue()
+			if err != nil {
+				return nil, err
+			}
+			m[key] = v
+			p.skipSpace(false)
+			switch {
+			case strings.HasPrefix(p.s, ","):
+				p.s = p.s[1:]
+			case !strings.HasPrefix(p.s, "}"):
+				return nil, p.errorf("expected , or } in inline table")
+			}
+		}
+	}
+
+	// Bare value: number, boolean, or date.
+	i := strings.IndexAny(p.s, ",]}#\n")
+	if i < 0 {
+		i = len(p.s)
+	}
+	word := strings.TrimSpace(p.s[:i])
+	if word == "" {
+		return nil, p.errorf("missing value")
+	}
+	p.s = p.s[i:]
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return word, nil
+}
+
+// str parses a basic, literal, or multi-line string.
+func (p *tomlParser) str() (string, error) {
+	q := p.s[:1]
+	if strings.HasPrefix(p.s, q+q+q) {
+		q += q + q
+	}
+	p.s = p.s[len(q):]
+	if len(q) == 3 && strings.HasPrefix(p.s, "\n") {
+		p.s = p.s[1:]
+		p.line++
+	}
+	end := -1
+	for i := 0; i < len(p.s); i++ {
+		if p.s[i] == '\\' && q[0] == '"' {
+			i++
+			continue
+		}
+		if strings.HasPrefix(p.s[i:], q) {
+			end = i
+			break
+		}
+		if p.s[i] == '\n' && len(q) == 1 {
+			break
+		}
+	}
+	if end < 0 {
+		return "", p.errorf("unterminated string")
+	}
+	raw := p.s[:end]
+	p.s = p.s[end+len(q):]
+	p.line += strings.Count(raw, "\n")
+	if q[0] == '\'' {
+		return raw, nil
+	}
+	s, err := unescapeTOML(raw)
+	if err != nil {
+		return "", p.errorf("%v", err)
+	}
+	return s, nil
+}
+
+// unescapeTOML processes the escapes in a basic string.
+func unescapeTOML(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(s) {
+			return "", fmt.Errorf("invalid string escape")
+		}
+		switch c := s[i]; c {
+		case 'b':
+			b.WriteByte('\b')
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case '"', '\\':
+			b.WriteByte(c)
+		case 'u', 'U':
+			n := 4
+			if c == 'U' {
+				n = 8
+			}
+			if i+n >= len(s) {
+				return "", fmt.Errorf("invalid string escape")
+			}
+			r, err := strconv.ParseUint(s[i+1:i+1+n], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid string escape")
+			}
+			b.WriteRune(rune(r))
+			i += n
+		case ' ', '\t', '\r', '\n':
+			// Line-ending backslash: trim through the next non-space.
+			j := i
+			for j < len(s) && strings.IndexByte(" \t\r\n", s[j]) >= 0 {
+				j++
+			}
+			i = j - 1
+		default:
+			return "", fmt.Errorf("invalid string escape \\%c", c)
+		}
+	}
+	return b.String(), nil
+}
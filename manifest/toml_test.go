@@ -0,0 +1,70 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package manifest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	data := `
+# comment
+top = 1
+[package]
+name = "x" # trailing
+"quoted.key" = 'literal \n'
+a.b = true
+esc = "tab\thereé"
+multi = """
+line1
+line2"""
+list = [
+  "a", # one
+  "b",
+]
+inline = { text = "MIT", n = 2 }
+
+[[bin]]
+name = "skipped"
+
+[ other . table ]
+k = "v"
+`
+	want := map[string]interface{}{
+		"top":                "1",
+		"package.name":       "x",
+		"package.quoted.key": `literal \n`,
+		"package.a.b":        true,
+		"package.esc":        "tab\thereé",
+		"package.multi":      "line1\nline2",
+		"package.list":       []interface{}{"a", "b"},
+		"package.inline":     map[string]interface{}{"text": "MIT", "n": "2"},
+		"other.table.k":      "v",
+	}
+	have, err := parseTOML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("parseTOML:\nhave %v\nwant %v", have, want)
+	}
+}
+
+var tomlErrorTests = []string{
+	"[package]\nlicense = [}]\n",
+	"[package]\nlicense = [,]\n",
+	"[package]\nlicense = [\"MIT\" \"BSD\"]\n",
+	"[package]\nlicense = { text = \"MIT\" ]\n",
+	"[package]\nlicense =\n",
+}
+
+func TestParseTOMLError(t *testing.T) {
+	for _, data := range tomlErrorTests {
+		if _, err := parseTOML([]byte(data)); err == nil {
+			t.Errorf("parseTOML(%q) succeeded, want error", data)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// XML manifests: pom.xml and .nuspec.
+
+package manifest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/google/licensecheck"
+)
+
+// parsePOM parses a Maven pom.xml.
+// Each <license> in <licenses> has a name and a url;
+// multiple licenses mean any one of them may be chosen.
+// When the name is missing or not recognized, the URL is used.
+func parsePOM(d *Declaration, data []byte) error {
+	var pom struct {
+		Licenses []struct {
+			Name string `xml:"name"`
+			URL  string `xml:"url"`
+		} `xml:"licenses>license"`
+	}
+	if err := unmarshalXML(data, &pom); err != nil {
+		return err
+	}
+	var names []string
+	for _, l := range pom.Licenses {
+		name := strings.TrimSpace(l.Name)
+		url := strings.TrimSpace(l.URL)
+		if name == "" {
+			name = url
+		} else if licensecheck.ParseName(name).Confidence == licensecheck.NoConfidence {
+			if _, ok := urlID(url); ok {
+				name = url
+			}
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		d.setAll("OR", names)
+	}
+	return nil
+}
+
+// parseNuspec parses a NuGet .nuspec.
+// The <license> element has type "expression" (an SPDX expression)
+// or "file" (a license file in the package).
+// Older packages have only a <licenseUrl>.
+func parseNuspec(d *Declaration, data []byte) error {
+	var spec struct {
+		Metadata struct {
+			License struct {
+				Type  string `xml:"type,attr"`
+				Value string `xml:",chardata"`
+			} `xml:"license"`
+			LicenseURL string `xml:"licenseUrl"`
+		} `xml:"metadata"`
+	}
+	if err := unmarshalXML(data, &spec); err != nil {
+		return err
+	}
+	m := &spec.Metadata
+	val := strings.TrimSpace(m.License.Value)
+	switch {
+	case val != "" && m.License.Type == "file":
+		d.Files = append(d.Files, val)
+	case val != "":
+		d.setAll("OR", []string{val})
+		return nil
+	}
+	// NuGet sets licenseUrl to a placeholder when license is used.
+	if url := strings.TrimSpace(m.LicenseURL); url != "" && !strings.Contains(url, "aka.ms/deprecateLicenseUrl") {
+		d.setAll("OR", []string{url})
+	}
+	return nil
+}
+
+// unmarshalXML is like xml.Unmarshal but
+// does not reject documents declaring non-UTF-8 encodings.
+// License names are ASCII in practice.
+func unmarshalXML(data []byte, v interface{}) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Strict = false
+	dec.CharsetReader = func(charset string, r io.Reader) (io.Reader, error) { return r, nil }
+	return dec.Decode(v)
+}
@@ -0,0 +1,633 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/google/licensecheck/internal/fold"
+	"github.com/google/licensecheck/lre"
+)
+
+// A Confidence describes how confident ParseName is in its result.
+type Confidence int
+
+const (
+	// NoConfidence indicates that the name was not recognized.
+	NoConfidence Confidence = iota
+
+	// LowConfidence indicates that the name is ambiguous,
+	// like "BSD" or "Apache", and the result is only its most likely meaning.
+	LowConfidence
+
+	// MediumConfidence indicates that the name was recognized
+	// only after correcting its spelling.
+	MediumConfidence
+
+	// HighConfidence indicates that the name is a known name for the license,
+	// like "Apache License, Version 2.0" or "GPLv3+".
+	HighConfidence
+
+	// ExactConfidence indicates that the name is already a license ID
+	// or an SPDX expression, possibly using different case or deprecated IDs.
+	ExactConfidence
+)
+
+var confidenceNames = []string{
+	NoConfidence:     "NoConfidence",
+	LowConfidence:    "LowConfidence",
+	MediumConfidence: "MediumConfidence",
+	HighConfidence:   "HighConfidence",
+	ExactConfidence:  "ExactConfidence",
+}
+
+func (c Confidence) String() string {
+	if 0 <= c && int(c) < len(confidenceNames) {
+		return confidenceNames[c]
+	}
+	return fmt.Sprintf("Confidence(%d)", int(c))
+}
+
+// A ParsedName is the result of ParseName.
+type ParsedName struct {
+	ID         string     // license ID, or SPDX expression for a combination of licenses
+	Confidence Confidence // confidence in ID
+}
+
+// ParseName parses a free-form license name, like those found in package
+// metadata, and returns the corresponding license ID.
+//
+// The name can be a license ID or SPDX expression ("MIT", "GPL-2.0+ OR MIT"),
+// in which case ParseName returns it using current IDs (see CanonicalID).
+// It can also be a common name for a license ("Apache License, Version 2.0",
+// "GPLv3+", "BSD 3-clause", "MIT/X11") or a combination of names separated
+// by "or", "and", or "/", in which case ParseName returns an SPDX expression
+// combining the IDs.
+//
+// ParseName reads names the same way Scan reads license texts:
+// it ignores case and punctuation and corrects small spelling mistakes.
+// The Confidence in the result reports how much guessing that took.
+// If the name is not recognized, ParseName returns a ParsedName with
+// an empty ID and NoConfidence.
+//
+// A version restriction written as "(>= N)" or ">= N", as in the R package
+// license "GPL (>= 2)", means version N or later, and one written as
+// "(== N)" means version N only.
+//
+// ParseName reports "Public Domain" as the SPDX expression "LicenseRef-Public-Domain",
+// since there is no SPDX ID for it.
+func ParseName(name string) ParsedName {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ParsedName{}
+	}
+	if expr, ok := canonicalExpr(name); ok {
+		return ParsedName{expr, ExactConfidence}
+	}
+	name = nameVersionRE.ReplaceAllStringFunc(name, func(v string) string {
+		m := nameVersionRE.FindStringSubmatch(v)
+		if m[1] == ">=" || m[3] == ">=" {
+			return m[2] + m[4] + " or later"
+		}
+		return m[2] + m[4] + " only"
+	})
+	if p := parseNameParen(name); p.Confidence != NoConfidence {
+		return p
+	}
+	return parseNameList(name)
+}
+
+// nameVersionRE matches a version restriction like "(>= 2)" or ">= 2",
+// as in the R package license "GPL (>= 2)".
+var nameVersionRE = regexp.MustCompile(`\(\s*(>=|==)\s*([0-9.]+)\s*\)|(>=|==)\s*([0-9.]+)`)
+
+// canonicalExpr reports whether s is an SPDX license expression
+// using known license IDs, and if so returns it using canonical IDs.
+func canonicalExpr(s string) (string, bool) {
+	s = strings.NewReplacer("(", " ( ", ")", " ) ").Replace(s)
+	var out []string
+	depth := 0
+	want := "license" // or "op" or "exception"
+	for _, tok := range strings.Fields(s) {
+		switch want {
+		case "license":
+			if tok == "(" {
+				depth++
+				out = append(out, tok)
+				continue
+			}
+			id, ok := CanonicalID(tok)
+			if !ok && strings.HasSuffix(tok, "+") {
+				id, ok = CanonicalID(strings.TrimSuffix(tok, "+"))
+				id += "+"
+			}
+			if !ok && (strings.HasPrefix(tok, "LicenseRef-") || strings.HasPrefix(tok, "DocumentRef-")) {
+				id, ok = tok, true
+			}
+			if !ok {
+				return "", false
+			}
+			out = append(out, id)
+			want = "op"
+
+		case "exception":
+			if !isExprToken(tok) {
+				return "", false
+			}
+			out = append(out, tok)
+			want = "op"
+
+		case "op":
+			switch strings.ToUpper(tok) {
+			case ")":
+				if depth--; depth < 0 {
+					return "", false
+				}
+				out = append(out, tok)
+			case "AND", "OR":
+				out = append(out, strings.ToUpper(tok))
+				want = "license"
+			case "WITH":
+				out = append(out, "WITH")
+				want = "exception"
+			default:
+				return "", false
+			}
+		}
+	}
+	if want != "op" || depth != 0 {
+		return "", false
+	}
+	return strings.NewReplacer("( ", "(", " )", ")").Replace(strings.Join(out, " ")), true
+}
+
+// isExprToken reports whether tok is a valid SPDX ID token.
+func isExprToken(tok string) bool {
+	for _, r := range tok {
+		if !('A' <= r && r <= 'Z' || 'a' <= r && r <= 'z' || '0' <= r && r <= '9' || r == '-' || r == '.') {
+			return false
+		}
+	}
+	return tok != ""
+}
+
+// parseNameParen parses a single license name.
+// If that fails and the name ends in a parenthesized alternate form,
+// as in "GNU General Public License v2 (GPLv2)", it tries each form separately.
+func parseNameParen(name string) ParsedName {
+	if p := parseOneName(name); p.Confidence != NoConfidence {
+		return p
+	}
+	if strings.HasSuffix(name, ")") {
+		if i := strings.LastIndex(name, "("); i > 0 {
+			if p := parseOneName(name[:i]); p.Confidence != NoConfidence {
+				return p
+			}
+			if p := parseOneName(name[i+1 : len(name)-1]); p.Confidence != NoConfidence {
+				return p
+			}
+		}
+	}
+	return ParsedName{}
+}
+
+// nameListRE matches the separators between names in a list of license names.
+var nameListRE = regexp.MustCompile(`(?i)\s+(or|and)\s+|\s*([/|&])\s*`)
+
+// parseNameList parses a list of license names separated by "or", "and", "/", and so on.
+func parseNameList(name string) ParsedName {
+	var parts, ops []string
+	start := 0
+	for _, m := range nameListRE.FindAllStringSubmatchIndex(name, -1) {
+		op := "OR"
+		if m[2] >= 0 {
+			if strings.EqualFold(name[m[2]:m[3]], "and") {
+				op = "AND"
+			}
+			// Not a separator: "or later", "or any later version", and so on.
+			rest := strings.ToLower(name[m[1]:])
+			if strings.HasPrefix(rest, "later") || strings.HasPrefix(rest, "any later") ||
+				strings.HasPrefix(rest, "newer") || strings.HasPrefix(rest, "greater") {
+				continue
+			}
+		} else if name[m[4]:m[5]] == "&" {
+			op = "AND"
+		}
+		parts = append(parts, name[start:m[0]])
+		ops = append(ops, op)
+		start = m[1]
+	}
+	if len(parts) == 0 {
+		return ParsedName{}
+	}
+	parts = append(parts, name[start:])
+
+	conf := ExactConfidence
+	var out []string
+	seen := make(map[string]bool)
+	for i, part := range parts {
+		var p ParsedName
+		if expr, ok := canonicalExpr(strings.TrimSpace(part)); ok {
+			p = ParsedName{expr, ExactConfidence}
+		} else {
+			p = parseNameParen(part)
+		}
+		if p.Confidence == NoConfidence {
+			return ParsedName{}
+		}
+		if p.Confidence < conf {
+			conf = p.Confidence
+		}
+		if seen[p.ID] {
+			continue
+		}
+		seen[p.ID] = true
+		if strings.Contains(p.ID, " ") {
+			p.ID = "(" + p.ID + ")"
+		}
+		if len(out) > 0 {
+			out = append(out, ops[i-1])
+		}
+		out = append(out, p.ID)
+	}
+	return ParsedName{strings.Join(out, " "), conf}
+}
+
+// A nameSuffix records the "only" or "or later" qualifier on a license name.
+type nameSuffix int
+
+const (
+	suffixNone nameSuffix = iota
+	suffixOnly
+	suffixOrLater
+)
+
+// A nameEntry records the IDs for a single license name.
+type nameEntry struct {
+	ids  [3]string  // ID for name with each nameSuffix
+	conf Confidence // HighConfidence, or LowConfidence for ambiguous names
+}
+
+var nameIndex struct {
+	once    sync.Once
+	dict    *lre.Dict
+	vocab   []string        // sorted list of known words
+	isVocab map[string]bool // set of known words
+	entries map[string]*nameEntry
+}
+
+// nameSynonyms lists common license names that are not derived
+// from the license IDs themselves.
+var nameSynonyms = []struct {
+	name string
+	id   string
+	conf Confidence
+}{
+	{"Expat", "MIT", HighConfidence},
+	{"MIT/X11", "MIT", HighConfidence},
+	{"New BSD", "BSD-3-Clause", HighConfidence},
+	{"Modified BSD", "BSD-3-Clause", HighConfidence},
+	{"Revised BSD", "BSD-3-Clause", HighConfidence},
+	{"BSD New", "BSD-3-Clause", HighConfidence},
+	{"BSD 3", "BSD-3-Clause", HighConfidence},
+	{"3-Clause BSD", "BSD-3-Clause", HighConfidence},
+	{"Simplified BSD", "BSD-2-Clause", HighConfidence},
+	{"BSD 2", "BSD-2-Clause", HighConfidence},
+	{"2-Clause BSD", "BSD-2-Clause", HighConfidence},
+	{"Original BSD", "BSD-4-Clause", HighConfidence},
+	{"BSD 4", "BSD-4-Clause", HighConfidence},
+	{"4-Clause BSD", "BSD-4-Clause", HighConfidence},
+	{"Zero-Clause BSD", "0BSD", HighConfidence},
+	{"BSD Zero Clause", "0BSD", HighConfidence},
+	{"Boost Software License", "BSL-1.0", HighConfidence},
+	{"Boost Software License 1.0", "BSL-1.0", HighConfidence},
+	{"CC0", "CC0-1.0", HighConfidence},
+	{"Creative Commons Zero", "CC0-1.0", HighConfidence},
+	{"zlib/libpng", "Zlib", HighConfidence},
+	{"Public Domain", "LicenseRef-Public-Domain", HighConfidence},
+
+	// Ambiguous names.
+	{"BSD", "BSD-3-Clause", LowConfidence},
+	{"Apache", "Apache-2.0", LowConfidence},
+}
+
+// nameFamilies lists phrases that are rewritten to the words used in license IDs
+// when they appear at the start of a license name.
+// Longer phrases must appear before their prefixes.
+var nameFamilies = []struct {
+	phrase string
+	id     string
+}{
+	{"GNU General Public", "GPL"},
+	{"GNU GPL", "GPL"},
+	{"General Public", "GPL"},
+	{"GNU Lesser General Public", "LGPL"},
+	{"GNU Library General Public", "LGPL"},
+	{"GNU Library or Lesser General Public", "LGPL"},
+	{"GNU LGPL", "LGPL"},
+	{"Lesser General Public", "LGPL"},
+	{"Library General Public", "LGPL"},
+	{"GNU Affero General Public", "AGPL"},
+	{"GNU AGPL", "AGPL"},
+	{"Affero General Public", "AGPL"},
+	{"Affero GPL", "AGPL"},
+	{"GNU Free Documentation", "GFDL"},
+	{"GNU FDL", "GFDL"},
+	{"Free Documentation", "GFDL"},
+	{"Apache Software", "Apache"},
+	{"ASL", "Apache"},
+	{"Mozilla Public", "MPL"},
+	{"Mozilla", "MPL"},
+	{"Eclipse Public", "EPL"},
+	{"Eclipse", "EPL"},
+	{"Common Development and Distribution", "CDDL"},
+	{"Common Public", "CPL"},
+	{"European Union Public", "EUPL"},
+	{"Academic Free", "AFL"},
+	{"Open Software", "OSL"},
+	{"Zope Public", "ZPL"},
+	{"Python Software Foundation", "PSF"},
+	{"Microsoft Public", "MS-PL"},
+	{"Microsoft Reciprocal", "MS-RL"},
+	{"Universal Permissive", "UPL"},
+	{"Educational Community", "ECL"},
+	{"SIL Open Font", "OFL"},
+	{"Open Font", "OFL"},
+	{"Creative Commons Attribution NonCommercial NoDerivatives", "CC-BY-NC-ND"},
+	{"Creative Commons Attribution NonCommercial ShareAlike", "CC-BY-NC-SA"},
+	{"Creative Commons Attribution NonCommercial", "CC-BY-NC"},
+	{"Creative Commons Attribution NoDerivatives", "CC-BY-ND"},
+	{"Creative Commons Attribution ShareAlike", "CC-BY-SA"},
+	{"Creative Commons Attribution Share Alike", "CC-BY-SA"},
+	{"Creative Commons Attribution", "CC-BY"},
+}
+
+// nameNoise lists words that are ignored in license names.
+var nameNoise = map[string]bool{
+	"international": true,
+	"licence":       true,
+	"license":       true,
+	"licensed":      true,
+	"licenses":      true,
+	"the":           true,
+	"universal":     true,
+	"unported":      true,
+	"v":             true,
+	"ver":           true,
+	"version":       true,
+}
+
+// nameOrLater lists the phrases that mark a license name as "or later".
+// Longer phrases must appear before their prefixes.
+var nameOrLater = [][]string{
+	{"or", "any", "later"},
+	{"or", "later"},
+	{"or", "newer"},
+	{"or", "greater"},
+	{"and", "later"},
+	{"+"},
+}
+
+func initNameIndex() {
+	d := new(lre.Dict)
+	nameIndex.dict = d
+	nameIndex.isVocab = make(map[string]bool)
+	nameIndex.entries = make(map[string]*nameEntry)
+
+	// Record all words in the dictionary and the vocabulary.
+	addWords := func(text string) {
+		for _, w := range nameWords(d, text, true, nil) {
+			if !nameIndex.isVocab[w] {
+				nameIndex.isVocab[w] = true
+				nameIndex.vocab = append(nameIndex.vocab, w)
+			}
+		}
+	}
+	for _, l := range builtinLREs {
+		addWords(l.ID)
+	}
+	for _, s := range nameSynonyms {
+		addWords(s.name)
+	}
+	for _, f := range nameFamilies {
+		addWords(f.phrase)
+	}
+	for w := range nameNoise {
+		addWords(w)
+	}
+	for _, p := range nameOrLater {
+		addWords(strings.Join(p, " "))
+	}
+	addWords("only")
+	sort.Strings(nameIndex.vocab)
+
+	add := func(name, id string, conf Confidence) {
+		key, suffix := nameKey(nameWords(d, name, false, nil))
+		e := nameIndex.entries[key]
+		if e == nil {
+			e = &nameEntry{conf: conf}
+			nameIndex.entries[key] = e
+		}
+		if e.ids[suffix] == "" {
+			e.ids[suffix] = id
+		}
+	}
+	for _, l := range builtinLREs {
+		add(l.ID, l.ID, HighConfidence)
+	}
+	for _, s := range nameSynonyms {
+		add(s.name, s.id, s.conf)
+	}
+}
+
+// parseOneName parses a single license name.
+func parseOneName(name string) ParsedName {
+	nameIndex.once.Do(initNameIndex)
+
+	corrected := false
+	key, suffix := nameKey(nameWords(nameIndex.dict, name, false, &corrected))
+	e := nameIndex.entries[key]
+	if e == nil {
+		return ParsedName{}
+	}
+
+	id := e.ids[suffix]
+	if id == "" && suffix != suffixNone && e.ids[suffixNone] != "" {
+		// No -only or -or-later form of this license.
+		// Use the plain ID, with the SPDX + operator for "or later".
+		id = e.ids[suffixNone]
+		if suffix == suffixOrLater {
+			id += "+"
+		}
+	}
+	if id == "" {
+		return ParsedName{}
+	}
+
+	conf := e.conf
+	if corrected && conf > MediumConfidence {
+		conf = MediumConfidence
+	}
+	return ParsedName{id, conf}
+}
+
+// nameWords splits the license name text into folded words,
+// using d to canonicalize the words the same way Scan does.
+// Each word is also split at letter-digit boundaries,
+// so that "GPLv3" is read as "gpl v 3".
+// A + immediately following a word is returned as a separate "+" word.
+//
+// If insert is true, nameWords adds new words to d.
+// Otherwise, if corrected is non-nil, nameWords corrects misspelled words
+// using the known vocabulary and sets *corrected if it does.
+func nameWords(d *lre.Dict, text string, insert bool, corrected *bool) []string {
+	var split []lre.Word
+	if insert {
+		split = d.InsertSplit(text)
+	} else {
+		split = d.Split(text)
+	}
+
+	var words []string
+	for _, w := range split {
+		var word string
+		if w.ID >= 0 {
+			word = d.Words()[w.ID]
+		} else {
+			word = fold.String(text[w.Lo:w.Hi])
+		}
+		for _, piece := range splitLetterDigit(word) {
+			if corrected != nil && !nameIndex.isVocab[piece] {
+				if fix, ok := correctName(piece); ok {
+					piece = fix
+					*corrected = true
+				}
+			}
+			words = append(words, piece)
+		}
+		if int(w.Hi) < len(text) && text[w.Hi] == '+' {
+			words = append(words, "+")
+		}
+	}
+	return words
+}
+
+// correctName returns the single vocabulary word that have is a misspelling of.
+func correctName(have string) (string, bool) {
+	fix := ""
+	for _, want := range nameIndex.vocab {
+		if lre.SpellDefault.CanMisspell(want, have) {
+			if fix != "" {
+				// Ambiguous.
+				return "", false
+			}
+			fix = want
+		}
+	}
+	return fix, fix != ""
+}
+
+// splitLetterDigit splits w at boundaries between letters and digits.
+// A "v" just before a digit is split off too, so that "gplv3" is "gpl v 3".
+func splitLetterDigit(w string) []string {
+	var list []string
+	start := 0
+	prevDigit := false
+	for i, r := range w {
+		digit := unicode.IsDigit(r)
+		if i > 0 && digit != prevDigit {
+			if digit && i-start > 1 && w[i-1] == 'v' {
+				list = append(list, w[start:i-1])
+				start = i - 1
+			}
+			list = append(list, w[start:i])
+			start = i
+		}
+		prevDigit = digit
+	}
+	return append(list, w[start:])
+}
+
+// nameKey returns the lookup key for a license name split into words,
+// along with the suffix ("only" or "or later") found in the name.
+func nameKey(words []string) (string, nameSuffix) {
+	suffix := suffixNone
+	var out []string
+Words:
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		if w == "only" {
+			suffix = suffixOnly
+			continue
+		}
+		for _, p := range nameOrLater {
+			if hasWords(words[i:], p) {
+				suffix = suffixOrLater
+				i += len(p) - 1
+				continue Words
+			}
+		}
+		if nameNoise[w] {
+			continue
+		}
+		out = append(out, w)
+	}
+
+	for _, f := range nameFamilies {
+		// The phrases are split lazily: nameFamilies is only read
+		// after initNameIndex has added all its words to the dictionary.
+		p := nameWords(nameIndex.dict, f.phrase, false, nil)
+		if hasWords(out, p) {
+			out = append(nameWords(nameIndex.dict, f.id, false, nil), out[len(p):]...)
+			break
+		}
+	}
+
+	// Drop trailing zeros from version numbers, so that
+	// "2", "2.0", and "2.0.0" are all the same version.
+	// A lone 0, as in "0BSD" and "MIT-0", is kept.
+	var key []string
+	for i, w := range out {
+		if w == "0" && i > 0 && isDigits(out[i-1]) {
+			j := i
+			for j < len(out) && out[j] == "0" {
+				j++
+			}
+			if j == len(out) || !isDigits(out[j]) {
+				continue
+			}
+		}
+		key = append(key, w)
+	}
+	return strings.Join(key, " "), suffix
+}
+
+// hasWords reports whether words begins with prefix.
+func hasWords(words, prefix []string) bool {
+	if len(words) < len(prefix) {
+		return false
+	}
+	for i, w := range prefix {
+		if words[i] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits reports whether w is a non-empty string of digits.
+func isDigits(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return w != ""
+}
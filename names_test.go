@@ -0,0 +1,105 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "testing"
+
+var parseNameTests = []struct {
+	in   string
+	id   string
+	conf Confidence
+}{
+	{"MIT", "MIT", ExactConfidence},
+	{"mit", "MIT", ExactConfidence},
+	{"GPL-2.0+", "GPL-2.0-or-later", ExactConfidence},
+	{"MIT OR Apache-2.0", "MIT OR Apache-2.0", ExactConfidence},
+	{"(mit or apache-2.0) and bsd-3-clause", "(MIT OR Apache-2.0) AND BSD-3-Clause", ExactConfidence},
+	{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0", ExactConfidence},
+	{"LicenseRef-Mine", "LicenseRef-Mine", ExactConfidence},
+	{"Apache-2.0+", "Apache-2.0+", ExactConfidence},
+
+	{"MIT License", "MIT", HighConfidence},
+	{"The MIT License (MIT)", "MIT", HighConfidence},
+	{"Apache 2", "Apache-2.0", HighConfidence},
+	{"Apache License, Version 2.0", "Apache-2.0", HighConfidence},
+	{"Apache Software License 2.0", "Apache-2.0", HighConfidence},
+	{"ASL 2.0", "Apache-2.0", HighConfidence},
+	{"apache2", "Apache-2.0", HighConfidence},
+	{"GPLv3+", "GPL-3.0-or-later", HighConfidence},
+	{"GPLv3", "GPL-3.0", HighConfidence},
+	{"GPL v2 only", "GPL-2.0-only", HighConfidence},
+	{"GNU General Public License v2 or later (GPLv2+)", "GPL-2.0-or-later", HighConfidence},
+	{"GNU Lesser General Public License v2.1 or later", "LGPL-2.1-or-later", HighConfidence},
+	{"LGPLv2.1", "LGPL-2.1", HighConfidence},
+	{"GPL (>= 2)", "GPL-2.0-or-later", HighConfidence},
+	{"GPL (>= 3)", "GPL-3.0-or-later", HighConfidence},
+	{"LGPL (>= 2.1)", "LGPL-2.1-or-later", HighConfidence},
+	{"GPL >= 2", "GPL-2.0-or-later", HighConfidence},
+	{"GPL (== 2)", "GPL-2.0-only", HighConfidence},
+	{"GPL-2 | GPL (>= 3)", "GPL-2.0 OR GPL-3.0-or-later", HighConfidence},
+	{"GNU Affero General Public License v3", "AGPL-3.0", HighConfidence},
+	{"BSD 3-clause", "BSD-3-Clause", HighConfidence},
+	{"BSD-3", "BSD-3-Clause", HighConfidence},
+	{"3-Clause BSD License", "BSD-3-Clause", HighConfidence},
+	{"New BSD License", "BSD-3-Clause", HighConfidence},
+	{"Simplified BSD", "BSD-2-Clause", HighConfidence},
+	{"MIT/X11", "MIT", HighConfidence},
+	{"Expat", "MIT", HighConfidence},
+	{"Public Domain", "LicenseRef-Public-Domain", HighConfidence},
+	{"Mozilla Public License 2.0", "MPL-2.0", HighConfidence},
+	{"MPL 1.1", "MPL-1.1", HighConfidence},
+	{"Eclipse Public License - v 1.0", "EPL-1.0", HighConfidence},
+	{"CC0", "CC0-1.0", HighConfidence},
+	{"CC0 1.0 Universal", "CC0-1.0", HighConfidence},
+	{"Creative Commons Attribution 4.0 International", "CC-BY-4.0", HighConfidence},
+	{"Creative Commons Attribution-ShareAlike 4.0 International", "CC-BY-SA-4.0", HighConfidence},
+	{"ISC License", "ISC", HighConfidence},
+	{"Boost Software License", "BSL-1.0", HighConfidence},
+	{"Boost Software License 1.0", "BSL-1.0", HighConfidence},
+	{"Unlicense", "Unlicense", ExactConfidence},
+	{"The Unlicense", "Unlicense", HighConfidence},
+	{"zlib/libpng", "Zlib", HighConfidence},
+	{"0BSD", "0BSD", ExactConfidence},
+	{"MPL 2.0+", "MPL-2.0+", HighConfidence},
+
+	{"Apach License 2.0", "Apache-2.0", MediumConfidence},
+	{"Mozila Public License 2.0", "MPL-2.0", MediumConfidence},
+
+	{"BSD", "BSD-3-Clause", LowConfidence},
+	{"BSD License", "BSD-3-Clause", LowConfidence},
+	{"Apache", "Apache-2.0", LowConfidence},
+
+	{"MIT or Apache 2", "MIT OR Apache-2.0", HighConfidence},
+	{"GPLv2 or later or MIT", "GPL-2.0-or-later OR MIT", HighConfidence},
+	{"MIT/Apache-2.0", "MIT OR Apache-2.0", ExactConfidence},
+	{"Apache-2.0 / MIT", "Apache-2.0 OR MIT", ExactConfidence},
+	{"MIT and BSD 3-clause", "MIT AND BSD-3-Clause", HighConfidence},
+	{"MIT/Expat", "MIT", HighConfidence},
+	{"BSD or MIT", "BSD-3-Clause OR MIT", LowConfidence},
+
+	{"", "", NoConfidence},
+	{"Proprietary", "", NoConfidence},
+	{"GPL", "", NoConfidence},
+	{"MIT or Proprietary", "", NoConfidence},
+}
+
+func TestParseName(t *testing.T) {
+	for _, tt := range parseNameTests {
+		p := ParseName(tt.in)
+		if p.ID != tt.id || p.Confidence != tt.conf {
+			t.Errorf("ParseName(%q) = %q, %v, want %q, %v", tt.in, p.ID, p.Confidence, tt.id, tt.conf)
+		}
+	}
+}
+
+func TestParseNameBuiltin(t *testing.T) {
+	// Every built-in ID must be reachable by name,
+	// even with its punctuation changed.
+	for _, l := range builtinLREs {
+		if p := parseOneName(l.ID); p.ID != l.ID {
+			t.Errorf("parseOneName(%q) = %q, %v", l.ID, p.ID, p.Confidence)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import "github.com/google/licensecheck/extract"
+
+// A DocumentCoverage describes the licenses found in an office document
+// by ScanDocument.
+type DocumentCoverage struct {
+	// Text is the text of the document that was scanned,
+	// with each paragraph on its own line (see extract.Document).
+	Text []byte
+
+	// Percent is the percentage of Text, in normalized words,
+	// that matches any valid license.
+	Percent float64
+
+	// Match lists the matches in Text, as in Coverage.
+	Match []DocumentMatch
+
+	// Corrections lists the misspellings accepted in the matches,
+	// with offsets in Text.
+	Corrections []Correction
+}
+
+// A DocumentMatch is a Match in an office document.
+// The Start and End offsets of the Match refer to DocumentCoverage.Text,
+// and From and To give the same positions as paragraph and character
+// indexes in the document.
+type DocumentMatch struct {
+	Match
+	From extract.Position
+	To   extract.Position
+}
+
+// ScanDocument scans the word processing document data, such as a
+// .docx or .odt file, using the built-in license set.
+// See the ScanDocument method for details.
+func ScanDocument(data []byte) (*DocumentCoverage, error) {
+	return builtinScanner.ScanDocument(data)
+}
+
+// ScanDocument reads the paragraphs of the word processing document data,
+// which must be an Office Open XML (.docx) or OpenDocument (.odt) document
+// (see extract.Office), and scans their text for licenses.
+func (s *Scanner) ScanDocument(data []byte) (*DocumentCoverage, error) {
+	doc, err := extract.Office(data)
+	if err != nil {
+		return nil, err
+	}
+	text := doc.Text()
+	c := s.ScanWithOptions(text, ScanOptions{})
+	dc := &DocumentCoverage{Text: text, Percent: c.Percent, Corrections: c.Corrections}
+	for _, m := range c.Match {
+		// Report the positions of the first and last words of the match,
+		// not of the empty paragraphs around it.
+		for m.Start < m.End && isSpace(text[m.Start]) {
+			m.Start++
+		}
+		for m.End > m.Start && isSpace(text[m.End-1]) {
+			m.End--
+		}
+		dc.Match = append(dc.Match, DocumentMatch{Match: m, From: doc.Position(m.Start), To: doc.Position(m.End)})
+	}
+	return dc, nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/licensecheck/extract"
+	"github.com/google/licensecheck/internal/archivetest"
+)
+
+func TestScanDocument(t *testing.T) {
+	// Write the MIT license as a DOCX document, after a title paragraph
+	// and an empty one, with each paragraph of the license split into two runs.
+	var body strings.Builder
+	body.WriteString(`<w:p><w:r><w:t>END USER LICENSE AGREEMENT</w:t></w:r></w:p><w:p/>`)
+	paras := strings.Split(strings.TrimSpace(license_MIT), "\n\n")
+	for _, para := range paras {
+		para = strings.ReplaceAll(para, "\n", " ")
+		i := strings.Index(para, " ")
+		body.WriteString(`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t xml:space="preserve">`)
+		xml.EscapeText(&body, []byte(para[:i+1]))
+		body.WriteString(`</w:t></w:r><w:r><w:t>`)
+		xml.EscapeText(&body, []byte(para[i+1:]))
+		body.WriteString(`</w:t></w:r></w:p>`)
+	}
+	docx := archivetest.Zip(t, "word/document.xml",
+		`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`+
+			body.String()+`</w:body></w:document>`)
+
+	cov, err := ScanDocument(docx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+		t.Fatalf("ScanDocument = %+v, want MIT", cov)
+	}
+	m := cov.Match[0]
+	first := strings.ReplaceAll(paras[0], "\n", " ")
+	last := strings.ReplaceAll(paras[len(paras)-1], "\n", " ")
+	from := extract.Position{Paragraph: 2, Char: utf8.RuneCountInString(first[:strings.Index(first, "copyright")])}
+	to := extract.Position{Paragraph: len(paras) + 1, Char: utf8.RuneCountInString(last)}
+	if m.From != from || m.To != to {
+		t.Errorf("ScanDocument match at %v-%v, want %v-%v", m.From, m.To, from, to)
+	}
+	if match := string(cov.Text[m.Start:m.End]); !strings.HasPrefix(match, "copyright") || !strings.HasSuffix(match, last) {
+		t.Errorf("ScanDocument matched %q, want license paragraphs", match)
+	}
+
+	if _, err := ScanDocument([]byte(license_MIT)); err == nil {
+		t.Errorf("ScanDocument(text) succeeded, want error")
+	}
+}
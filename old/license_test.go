@@ -16,11 +16,11 @@ import (
 	"strings"
 	"testing"
 
-	"github.com/google/licensecheck/internal/match"
+	"github.com/google/licensecheck/lre"
 )
 
 func init() {
-	flag.IntVar(&match.TraceDFA, "tracedfa", match.TraceDFA, "trace DFA execution that bails out after `n` non-matching steps")
+	flag.IntVar(&lre.TraceDFA, "tracedfa", lre.TraceDFA, "trace DFA execution that bails out after `n` non-matching steps")
 }
 
 func TestTestdata(t *testing.T) {
@@ -0,0 +1,175 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// SARIF JSON format.
+
+package sarif
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// The json* types mirror the SARIF 2.1.0 JSON schema.
+
+type jsonLog struct {
+	Schema  string    `json:"$schema"`
+	Version string    `json:"version"`
+	Runs    []jsonRun `json:"runs"`
+}
+
+type jsonRun struct {
+	Tool       jsonTool     `json:"tool"`
+	ColumnKind string       `json:"columnKind"`
+	Results    []jsonResult `json:"results"`
+}
+
+type jsonTool struct {
+	Driver jsonDriver `json:"driver"`
+}
+
+type jsonDriver struct {
+	Name           string     `json:"name"`
+	InformationURI string     `json:"informationUri"`
+	Rules          []jsonRule `json:"rules"`
+}
+
+type jsonRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     jsonMessage            `json:"shortDescription"`
+	HelpURI              string                 `json:"helpUri,omitempty"`
+	DefaultConfiguration jsonConfiguration      `json:"defaultConfiguration"`
+	Properties           map[string]interface{} `json:"properties,omitempty"`
+}
+
+type jsonMessage struct {
+	Text string `json:"text"`
+}
+
+type jsonConfiguration struct {
+	Level string `json:"level"`
+}
+
+type jsonResult struct {
+	RuleID     string                 `json:"ruleId"`
+	RuleIndex  int                    `json:"ruleIndex"`
+	Level      string                 `json:"level"`
+	Message    jsonMessage            `json:"message"`
+	Locations  []jsonLocation         `json:"locations,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+type jsonLocation struct {
+	PhysicalLocation jsonPhysicalLocation `json:"physicalLocation"`
+}
+
+type jsonPhysicalLocation struct {
+	ArtifactLocation jsonArtifactLocation `json:"artifactLocation"`
+	Region           *jsonRegion          `json:"region,omitempty"`
+}
+
+type jsonArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId"`
+}
+
+type jsonRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn"`
+	EndLine     int `json:"endLine"`
+	EndColumn   int `json:"endColumn"`
+	ByteOffset  int `json:"byteOffset"`
+	ByteLength  int `json:"byteLength"`
+}
+
+// Property names used in rules and results.
+const (
+	propType    = "licensecheck:type"
+	propPercent = "licensecheck:percent"
+	propIsURL   = "licensecheck:url"
+)
+
+// WriteJSON writes the log to w in SARIF 2.1.0 JSON format.
+func (l *Log) WriteJSON(w io.Writer) error {
+	js, err := l.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(js, '\n'))
+	return err
+}
+
+// MarshalJSON returns the log in SARIF 2.1.0 JSON format.
+func (l *Log) MarshalJSON() ([]byte, error) {
+	run := jsonRun{
+		Tool: jsonTool{jsonDriver{
+			Name:           "licensecheck",
+			InformationURI: "https://github.com/google/licensecheck",
+			Rules:          []jsonRule{},
+		}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    []jsonResult{},
+	}
+	for _, r := range l.Rules {
+		jr := jsonRule{
+			ID:                   r.ID,
+			ShortDescription:     jsonMessage{r.Description},
+			HelpURI:              r.HelpURI,
+			DefaultConfiguration: jsonConfiguration{r.Level},
+		}
+		if isAuditRule(r.ID) {
+			jr.Properties = map[string]interface{}{"tags": []string{"audit"}}
+		} else {
+			tags := []string{"license"}
+			if r.Type != 0 {
+				tags = append(tags, strings.Split(r.Type.String(), "|")...)
+			}
+			jr.Properties = map[string]interface{}{
+				"tags":   tags,
+				propType: r.Type.String(),
+			}
+		}
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, jr)
+	}
+	for _, r := range l.Results {
+		jr := jsonResult{
+			RuleID:    r.RuleID,
+			RuleIndex: r.RuleIndex,
+			Level:     r.Level,
+			Message:   jsonMessage{r.Message},
+		}
+		if r.Path != "" {
+			loc := jsonLocation{jsonPhysicalLocation{
+				ArtifactLocation: jsonArtifactLocation{r.Path, "%SRCROOT%"},
+			}}
+			if g := r.Region; g != nil {
+				loc.PhysicalLocation.Region = &jsonRegion{g.StartLine, g.StartColumn, g.EndLine, g.EndColumn, g.ByteOffset, g.ByteLength}
+			}
+			jr.Locations = []jsonLocation{loc}
+		}
+		if !isAuditRule(r.RuleID) {
+			jr.Properties = map[string]interface{}{
+				propType:    r.Type.String(),
+				propPercent: r.Percent,
+			}
+			if r.IsURL {
+				jr.Properties[propIsURL] = true
+			}
+		} else if r.Type != 0 {
+			jr.Properties = map[string]interface{}{propType: r.Type.String()}
+		}
+		run.Results = append(run.Results, jr)
+	}
+	return json.MarshalIndent(jsonLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []jsonRun{run},
+	}, "", "\t")
+}
+
+// isAuditRule reports whether id is the ID of an audit problem rule.
+func isAuditRule(id string) bool {
+	return strings.HasPrefix(id, "audit/")
+}
@@ -0,0 +1,314 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sarif writes license scan findings as SARIF 2.1.0 logs,
+// the Static Analysis Results Interchange Format read by
+// code scanning tools, so that they can show findings inline.
+//
+// The log has a single run whose rule catalog has one rule
+// for each built-in license ID, in sorted order, followed by
+// one rule for each kind of audit problem (see package audit).
+// The catalog depends only on the built-in licenses,
+// so a rule's index is the same from one log to the next.
+// A scan with a custom Scanner may report other license IDs;
+// their rules are appended to the catalog as needed.
+//
+// Each license match becomes a result for the license's rule,
+// located at the match's line and column range in its file,
+// with the license type and coverage recorded as result properties.
+// Each audit problem becomes a result for the problem's rule.
+//
+// For example:
+//
+//	fsys := os.DirFS(dir)
+//	scan, err := licensecheck.ScanFS(fsys, ".")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	l := sarif.New()
+//	if err := l.AddScan(fsys, scan); err != nil {
+//		log.Fatal(err)
+//	}
+//	l.WriteJSON(os.Stdout)
+package sarif
+
+import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/audit"
+	"github.com/google/licensecheck/internal/textpos"
+)
+
+// SARIF result levels.
+const (
+	Note    = "note"
+	Warning = "warning"
+	Error   = "error"
+)
+
+// A Log is a SARIF log holding a single licensecheck run.
+// Use New to create a Log.
+type Log struct {
+	Rules   []*Rule
+	Results []*Result
+
+	ruleIndex map[string]int // rule ID -> index in Rules
+}
+
+// A Rule is a single entry in the rule catalog.
+type Rule struct {
+	ID          string            // license ID, or "audit/" plus a problem kind
+	Description string            // short description
+	HelpURI     string            // page describing the license, if known
+	Level       string            // default result level
+	Type        licensecheck.Type // license type, for license rules
+}
+
+// A Result is a single finding.
+type Result struct {
+	RuleID    string
+	RuleIndex int
+	Level     string
+	Message   string
+	Path      string // slash-separated path of file in the file system
+	Region    *Region
+
+	// Set for license matches.
+	Type    licensecheck.Type
+	Percent float64 // percentage of the file covered by licenses
+	IsURL   bool
+}
+
+// A Region is the location of a result within its file.
+// Lines and columns are 1-based; columns count Unicode code points.
+// The end position is exclusive: EndColumn is the column
+// just after the last character in the region.
+type Region struct {
+	StartLine   int
+	StartColumn int
+	EndLine     int
+	EndColumn   int
+	ByteOffset  int
+	ByteLength  int
+}
+
+// auditKinds lists the audit problem kinds, in catalog order.
+var auditKinds = []audit.Kind{
+	audit.Mismatch,
+	audit.MissingText,
+	audit.ExtraCopyleft,
+	audit.Unrecognized,
+	audit.Malformed,
+}
+
+// auditRuleID returns the rule ID for problems of kind k.
+func auditRuleID(k audit.Kind) string {
+	return "audit/" + k.String()
+}
+
+// Rules returns the standard rule catalog:
+// one rule for each built-in license ID, in sorted order,
+// followed by one rule for each kind of audit problem.
+func Rules() []*Rule {
+	types := make(map[string]licensecheck.Type)
+	var ids []string
+	for _, l := range licensecheck.BuiltinLicenses() {
+		if _, ok := types[l.ID]; !ok {
+			ids = append(ids, l.ID)
+		}
+		types[l.ID] |= l.Type
+	}
+	sort.Strings(ids)
+
+	var rules []*Rule
+	for _, id := range ids {
+		rules = append(rules, licenseRule(id, types[id]))
+	}
+	for _, k := range auditKinds {
+		r := &Rule{ID: auditRuleID(k), Level: Warning}
+		switch k {
+		case audit.Mismatch:
+			r.Description = "License file text is not a declared license"
+		case audit.MissingText:
+			r.Description = "Declared license has no license text"
+		case audit.ExtraCopyleft:
+			r.Description = "Copyleft license is not declared"
+			r.Level = Error
+		case audit.Unrecognized:
+			r.Description = "Declared license is not recognized"
+		case audit.Malformed:
+			r.Description = "Manifest cannot be parsed"
+		}
+		rules = append(rules, r)
+	}
+	return rules
+}
+
+// licenseRule returns the rule for the license ID id.
+func licenseRule(id string, typ licensecheck.Type) *Rule {
+	r := &Rule{
+		ID:          id,
+		Description: "License " + id,
+		Level:       Note,
+		Type:        typ,
+	}
+	if licensecheck.IsSPDX(id) {
+		r.HelpURI = "https://spdx.org/licenses/" + id + ".html"
+	}
+	return r
+}
+
+// New returns a new Log with the standard rule catalog and no results.
+func New() *Log {
+	l := &Log{Rules: Rules(), ruleIndex: make(map[string]int)}
+	for i, r := range l.Rules {
+		l.ruleIndex[r.ID] = i
+	}
+	return l
+}
+
+// rule returns the index of the rule with the given ID,
+// adding a new license rule to the catalog if needed.
+func (l *Log) rule(id string, typ licensecheck.Type) int {
+	if i, ok := l.ruleIndex[id]; ok {
+		return i
+	}
+	l.Rules = append(l.Rules, licenseRule(id, typ))
+	l.ruleIndex[id] = len(l.Rules) - 1
+	return len(l.Rules) - 1
+}
+
+// AddScan adds a result for each license match in scan,
+// which should be the result of licensecheck.ScanFS
+// (or a Scanner's ScanFS method) for a tree in fsys.
+// AddScan reads each file with matches from fsys
+// to convert the matches' byte offsets to lines and columns.
+func (l *Log) AddScan(fsys fs.FS, scan []licensecheck.FileCoverage) error {
+	for _, f := range scan {
+		if len(f.Match) == 0 {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, f.Path)
+		if err != nil {
+			return err
+		}
+		l.AddFile(f.Path, data, f.Coverage)
+	}
+	return nil
+}
+
+// AddFile adds a result for each license match in cov,
+// the coverage of the file with the given path and content.
+func (l *Log) AddFile(path string, data []byte, cov licensecheck.Coverage) {
+	tab := textpos.NewTable(data)
+	for _, m := range cov.Match {
+		i := l.rule(m.ID, m.Type)
+		msg := fmt.Sprintf("License %s (%.1f%% of file)", m.ID, cov.Percent)
+		if m.IsURL {
+			msg = fmt.Sprintf("License %s URL", m.ID)
+		}
+		l.Results = append(l.Results, &Result{
+			RuleID:    m.ID,
+			RuleIndex: i,
+			Level:     l.Rules[i].Level,
+			Message:   msg,
+			Path:      uri(path),
+			Region:    region(tab, m.Start, m.End),
+			Type:      m.Type,
+			Percent:   cov.Percent,
+			IsURL:     m.IsURL,
+		})
+	}
+}
+
+// uri returns the relative URI used to refer to the file path.
+func uri(path string) string {
+	if path == "" {
+		return ""
+	}
+	u := &url.URL{Path: strings.TrimPrefix(path, "./")}
+	return u.String()
+}
+
+// region returns the Region for the byte range [start, end) in tab.
+func region(tab *textpos.Table, start, end int) *Region {
+	p, q := tab.Position(start), tab.Position(end)
+	return &Region{
+		StartLine:   p.Line,
+		StartColumn: p.Column,
+		EndLine:     q.Line,
+		EndColumn:   q.Column,
+		ByteOffset:  start,
+		ByteLength:  end - start,
+	}
+}
+
+// AddAudit adds a result for each problem in pkgs,
+// which should be the result of audit.Check for a tree in fsys.
+// Problems about a license file or source file are located
+// at the first match for the license concerned in that file;
+// problems about the declaration are located at the package's
+// first manifest declaring a license.
+func (l *Log) AddAudit(fsys fs.FS, pkgs []*audit.Package) error {
+	for _, p := range pkgs {
+		for _, prob := range p.Problems {
+			id := auditRuleID(prob.Kind)
+			i, ok := l.ruleIndex[id]
+			if !ok {
+				return fmt.Errorf("sarif: unknown audit problem kind %v", prob.Kind)
+			}
+			r := &Result{
+				RuleID:    id,
+				RuleIndex: i,
+				Level:     l.Rules[i].Level,
+				Message:   prob.String(),
+				Path:      uri(prob.Path),
+			}
+			if r.Path == "" {
+				for _, d := range p.Declared {
+					if len(d.Names) > 0 {
+						r.Path = uri(d.File)
+						break
+					}
+				}
+			}
+			if m, ok := findMatch(p, prob); ok {
+				data, err := fs.ReadFile(fsys, prob.Path)
+				if err != nil {
+					return err
+				}
+				r.Region = region(textpos.NewTable(data), m.Start, m.End)
+				r.Type = m.Type
+			}
+			l.Results = append(l.Results, r)
+		}
+	}
+	return nil
+}
+
+// findMatch returns the first match for prob's license
+// in the scan results for prob's file.
+func findMatch(p *audit.Package, prob audit.Problem) (licensecheck.Match, bool) {
+	if prob.Path == "" || prob.ID == "" {
+		return licensecheck.Match{}, false
+	}
+	for _, list := range [][]licensecheck.FileCoverage{p.LicenseFiles, p.Files} {
+		for _, fc := range list {
+			if fc.Path != prob.Path {
+				continue
+			}
+			for _, m := range fc.Match {
+				if m.ID == prob.ID {
+					return m, true
+				}
+			}
+		}
+	}
+	return licensecheck.Match{}, false
+}
@@ -0,0 +1,218 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sarif
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/audit"
+)
+
+// testText returns the license text from ../testdata/name,
+// without the test header.
+func testText(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.Index(string(data), "\n\n")
+	if i < 0 {
+		t.Fatalf("%s: malformed test file", name)
+	}
+	return data[i+2:]
+}
+
+func TestRules(t *testing.T) {
+	rules := Rules()
+	var ids []string
+	seen := make(map[string]bool)
+	for _, r := range rules {
+		if seen[r.ID] {
+			t.Errorf("duplicate rule %s", r.ID)
+		}
+		seen[r.ID] = true
+		if !isAuditRule(r.ID) {
+			ids = append(ids, r.ID)
+		}
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("license rules not sorted")
+	}
+	n := len(ids)
+	if n == 0 || n+len(auditKinds) != len(rules) {
+		t.Fatalf("have %d license rules and %d total, want %d audit rules at end", n, len(rules), len(auditKinds))
+	}
+	for i, k := range auditKinds {
+		if r := rules[n+i]; r.ID != auditRuleID(k) || r.Description == "" {
+			t.Errorf("rules[%d] = %+v, want audit rule for %v", n+i, r, k)
+		}
+	}
+
+	// The catalog must be stable from call to call.
+	again := Rules()
+	for i := range rules {
+		if *rules[i] != *again[i] {
+			t.Fatalf("Rules()[%d] = %+v, then %+v", i, rules[i], again[i])
+		}
+	}
+
+	i := New().ruleIndex["MIT"]
+	if r := rules[i]; r.ID != "MIT" || r.HelpURI != "https://spdx.org/licenses/MIT.html" || r.Level != Note {
+		t.Errorf("MIT rule = %+v", r)
+	}
+	if r := rules[New().ruleIndex["GooglePatentsFile"]]; r.HelpURI != "" {
+		t.Errorf("GooglePatentsFile rule has HelpURI %q", r.HelpURI)
+	}
+}
+
+func TestAddScan(t *testing.T) {
+	mit := testText(t, "MIT.t1")
+	fsys := fstest.MapFS{
+		"LICENSE":    {Data: append([]byte("Copyright 2020 Gophers\n\n"), mit...)},
+		"main.go":    {Data: []byte("package main\n")},
+		"x/y z.html": {Data: []byte("<p>Licensed under\n  https://www.apache.org/licenses/LICENSE-2.0</p>\n")},
+	}
+	scan, err := licensecheck.ScanFS(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New()
+	if err := l.AddScan(fsys, scan); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Results) != 2 {
+		t.Fatalf("have %d results, want 2", len(l.Results))
+	}
+
+	r := l.Results[0]
+	if r.RuleID != "MIT" || l.Rules[r.RuleIndex].ID != "MIT" || r.Level != Note || r.Path != "LICENSE" || r.IsURL {
+		t.Errorf("results[0] = %+v", r)
+	}
+	m := scan[0].Match[0]
+	if g := r.Region; g.StartLine != 1 || g.StartColumn != 1 || g.ByteOffset != m.Start || g.ByteLength != m.End-m.Start || g.EndLine < 20 {
+		t.Errorf("results[0].Region = %+v", g)
+	}
+
+	r = l.Results[1]
+	if r.RuleID != "Apache-2.0" || !r.IsURL || r.Path != "x/y%20z.html" {
+		t.Errorf("results[1] = %+v", r)
+	}
+	if g := r.Region; g.StartLine != 2 || g.StartColumn != 3 || g.EndLine != 2 || g.EndColumn != 3+g.ByteLength {
+		t.Errorf("results[1].Region = %+v", g)
+	}
+}
+
+func TestAddFileCustom(t *testing.T) {
+	l := New()
+	n := len(l.Rules)
+	cov := licensecheck.Coverage{Percent: 100, Match: []licensecheck.Match{
+		{ID: "Custom", Type: licensecheck.Notice, Start: 0, End: 6},
+		{ID: "Custom", Type: licensecheck.Notice, Start: 7, End: 13},
+	}}
+	l.AddFile("a/b", []byte("custom\ncustom\n"), cov)
+	if len(l.Rules) != n+1 || l.Rules[n].ID != "Custom" || l.Rules[n].Type != licensecheck.Notice {
+		t.Fatalf("rules not extended with Custom: %d rules", len(l.Rules))
+	}
+	for _, r := range l.Results {
+		if r.RuleIndex != n {
+			t.Errorf("result %+v, want rule index %d", r, n)
+		}
+	}
+	if g := l.Results[1].Region; g.StartLine != 2 || g.EndColumn != 7 {
+		t.Errorf("results[1].Region = %+v", g)
+	}
+}
+
+func TestAddAudit(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pkg/Cargo.toml": {Data: []byte("[package]\nlicense = \"MIT\"\n")},
+		"pkg/LICENSE":    {Data: testText(t, "BSD-3-Clause.t1")},
+	}
+	pkgs, err := audit.Check(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := New()
+	if err := l.AddAudit(fsys, pkgs); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.Results) != 2 {
+		t.Fatalf("have %d results, want 2", len(l.Results))
+	}
+	r := l.Results[0]
+	if r.RuleID != "audit/Mismatch" || r.Level != Warning || r.Path != "pkg/LICENSE" || r.Region == nil || r.Region.EndLine < 10 {
+		t.Errorf("results[0] = %+v", r)
+	}
+	r = l.Results[1]
+	if r.RuleID != "audit/MissingText" || r.Path != "pkg/Cargo.toml" || r.Region != nil {
+		t.Errorf("results[1] = %+v", r)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	l := New()
+	l.AddFile("LICENSE", testText(t, "MIT.t1"), licensecheck.Scan(testText(t, "MIT.t1")))
+	var buf bytes.Buffer
+	if err := l.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var js struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Name  string
+					Rules []struct {
+						ID                   string
+						DefaultConfiguration struct{ Level string }
+						Properties           map[string]interface{}
+					}
+				}
+			}
+			ColumnKind string
+			Results    []struct {
+				RuleID    string
+				RuleIndex int
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI, URIBaseID string }
+						Region           struct{ StartLine, StartColumn, EndLine, EndColumn int }
+					}
+				}
+				Properties map[string]interface{}
+			}
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &js); err != nil {
+		t.Fatal(err)
+	}
+	ok := js.Version == "2.1.0" && len(js.Runs) == 1
+	if ok {
+		run := js.Runs[0]
+		ok = run.Tool.Driver.Name == "licensecheck" && run.ColumnKind == "unicodeCodePoints" &&
+			len(run.Tool.Driver.Rules) == len(l.Rules) && len(run.Results) == 1
+		if ok {
+			res := run.Results[0]
+			rule := run.Tool.Driver.Rules[res.RuleIndex]
+			ok = res.RuleID == "MIT" && rule.ID == "MIT" && rule.DefaultConfiguration.Level == "note" &&
+				rule.Properties[propType] == "Unknown" &&
+				strings.HasPrefix(res.Message.Text, "License MIT") &&
+				len(res.Locations) == 1 && res.Locations[0].PhysicalLocation.ArtifactLocation.URI == "LICENSE" &&
+				res.Locations[0].PhysicalLocation.Region.StartLine == 1 &&
+				res.Properties[propType] == "Unknown" && res.Properties[propPercent] != nil
+		}
+	}
+	if !ok {
+		t.Errorf("WriteJSON output:\n%.2000s", buf.String())
+	}
+}
@@ -12,7 +12,8 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/google/licensecheck/internal/match"
+	"github.com/google/licensecheck/extract"
+	"github.com/google/licensecheck/lre"
 )
 
 var (
@@ -49,25 +50,76 @@ func BuiltinLicenses() []License {
 type Scanner struct {
 	licenses []License
 	urls     map[string]License
-	re       *match.MultiLRE
+	re       *lre.MultiLRE
 }
 
 // NewScanner returns a new Scanner that recognizes the given set of licenses.
 // See the description of Scan more information.
+//
+// License IDs that are deprecated SPDX IDs or legacy licensecheck IDs
+// (see CanonicalID) are replaced by their current IDs. It is an error
+// for that replacement to give two license patterns the same ID.
 func NewScanner(licenses []License) (*Scanner, error) {
+	return NewScannerWithOptions(licenses, ScannerOptions{})
+}
+
+// ScannerOptions holds optional settings for NewScannerWithOptions.
+// The zero ScannerOptions gives the same Scanner as NewScanner.
+type ScannerOptions struct {
+	// Rewrites lists word rewrites to apply, in order, to both the license
+	// patterns and the scanned texts, in addition to the built-in ones
+	// (such as "(c)" to "copyright" and "these" to "the").
+	// They can canonicalize synonyms, such as "licenser" to "licensor",
+	// spelling variants, such as "organisation" to "organization",
+	// or phrases, such as "free of charge" to "free".
+	// Rewrites apply only to the Scanner created with them.
+	Rewrites []Rewrite
+
+	// Segmentation sets how Chinese and Japanese text, which does not
+	// use spaces between words, is split into words, in both the
+	// license patterns and the scanned texts. The default,
+	// lre.SegmentWords, treats each run of such text as a single word;
+	// lre.SegmentChars and lre.SegmentBigrams allow license patterns
+	// written in those languages.
+	Segmentation lre.Segmentation
+}
+
+// A Rewrite rewrites the word sequence From to the word sequence To.
+// The words are matched and replaced the same way as in license patterns,
+// ignoring case and punctuation.
+// See the Dict.Rewrite method in package lre for details.
+type Rewrite struct {
+	From string
+	To   string
+}
+
+// NewScannerWithOptions is like NewScanner
+// but applies the settings in opts to the Scanner.
+func NewScannerWithOptions(licenses []License, opts ScannerOptions) (*Scanner, error) {
 	s := new(Scanner)
-	err := s.init(licenses)
+	err := s.init(licenses, opts)
 	if err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
-func (s *Scanner) init(licenses []License) error {
-	d := new(match.Dict)
+func (s *Scanner) init(licenses []License, opts ScannerOptions) error {
+	licenses, err := resolveAliases(licenses)
+	if err != nil {
+		return err
+	}
+
+	d := new(lre.Dict)
+	d.SetSegmentation(opts.Segmentation)
 	d.Insert("copyright")
 	d.Insert("http")
-	var list []*match.LRE
+	for _, r := range opts.Rewrites {
+		if err := d.Rewrite(r.From, r.To); err != nil {
+			return err
+		}
+	}
+	var list []*lre.LRE
 	s.urls = make(map[string]License)
 	for _, l := range licenses {
 		if l.URL != "" {
@@ -75,14 +127,14 @@ func (s *Scanner) init(licenses []License) error {
 		}
 		if l.LRE != "" {
 			s.licenses = append(s.licenses, l)
-			re, err := match.ParseLRE(d, l.ID, l.LRE)
+			re, err := lre.ParseLRE(d, l.ID, l.LRE)
 			if err != nil {
 				return fmt.Errorf("parsing %v: %v", l.ID, err)
 			}
 			list = append(list, re)
 		}
 	}
-	re, err := match.NewMultiLRE(list)
+	re, err := lre.NewMultiLRE(list)
 	if err != nil {
 		return err
 	}
@@ -115,20 +167,130 @@ func Scan(text []byte) Coverage {
 	return builtinScanner.Scan(text)
 }
 
+// ScanOptions holds optional settings for ScanWithOptions.
+// The zero ScanOptions gives the same results as Scan.
+type ScanOptions struct {
+	// SpellCheck is the policy for accepting misspelled words
+	// in license texts. The policies range from lre.SpellNone,
+	// which accepts only exact matches, for strict audits,
+	// to lre.SpellLoose, which accepts more errors, for texts
+	// obtained by optical character recognition.
+	// The accepted misspellings are listed in Coverage.Corrections.
+	SpellCheck lre.SpellCheck
+
+	// Comments, if true, limits the scan to the text of the comments
+	// in a source file, with comment markers and decorations removed,
+	// so that they neither break matches nor count as unmatched words
+	// in Coverage.Percent. The language is chosen by FileName or by a
+	// #! line at the start of the text (see extract.Comments).
+	// If it is not known, the whole text is scanned.
+	// Either way, the offsets in the Coverage refer to the original text.
+	Comments bool
+
+	// FileName is the name of the file being scanned, if known.
+	FileName string
+
+	// Decode, if true, transcodes text that is not UTF-8,
+	// such as UTF-16 with a byte order mark or Latin-1,
+	// before scanning it (see extract.Decode).
+	// The offsets in the Coverage still refer to the original bytes.
+	Decode bool
+
+	// Markup, if true, removes the markup from documents in RTF,
+	// troff, Texinfo, reStructuredText, or another markup language
+	// registered with extract.RegisterMarkup, before scanning them.
+	// The language is chosen by FileName or by the text itself
+	// (see extract.StripMarkup). If it is not known, the whole text
+	// is scanned. The offsets in the Coverage refer to the original text.
+	Markup bool
+}
+
+// ScanWithOptions is like Scan but uses the settings in opts.
+func ScanWithOptions(text []byte, opts ScanOptions) Coverage {
+	return builtinScanner.ScanWithOptions(text, opts)
+}
+
 var urlScanRE = regexp.MustCompile(`^(?i)https?://[-a-z0-9_.]+\.(org|com)(/[-a-z0-9_.#?=]+)+/?`)
 
 // Scan is like the top-level function Scan,
 // but it uses the set of licenses in the Scanner instead of the built-in license set.
 func (s *Scanner) Scan(text []byte) Coverage {
+	return s.ScanWithOptions(text, ScanOptions{})
+}
+
+// ScanWithOptions is like the top-level function ScanWithOptions,
+// but it uses the set of licenses in the Scanner instead of the built-in license set.
+func (s *Scanner) ScanWithOptions(text []byte, opts ScanOptions) Coverage {
 	if s == builtinScanner {
 		builtinScannerOnce.Do(func() {
-			if err := builtinScanner.init(BuiltinLicenses()); err != nil {
+			if err := builtinScanner.init(BuiltinLicenses(), ScannerOptions{}); err != nil {
 				panic("licensecheck: initializing Scan: " + err.Error())
 			}
 		})
 	}
 
-	matches := s.re.Match(string(text)) // TODO remove conversion
+	// Each extraction maps the text scanned so far to a new text.
+	// The matches are mapped back through them in reverse order.
+	// Blank lines at the ends of matches are trimmed if the last
+	// extraction removed markup or comment delimiters.
+	var texts []*extract.Text
+	trim := false
+	if opts.Decode {
+		if t, ok := extract.Decode(text); ok {
+			texts = append(texts, t)
+			text = t.Data
+		}
+	}
+	if opts.Markup {
+		if t, ok := extract.StripMarkup(opts.FileName, text); ok {
+			texts = append(texts, t)
+			text = t.Data
+			trim = true
+		}
+	}
+	if opts.Comments {
+		if t, ok := extract.Comments(opts.FileName, text); ok {
+			texts = append(texts, t)
+			text = t.Data
+			trim = true
+		}
+	}
+	c := s.scan(text, opts)
+	for i := len(texts) - 1; i >= 0; i-- {
+		c.remap(texts[i], trim && i == len(texts)-1)
+	}
+	return c
+}
+
+// remap changes the offsets in c, which refer to t.Data,
+// to refer to the original text from which t was extracted.
+// If trim is true, blank lines at the start or end of a match
+// are trimmed first, since in extracted text they stand for
+// markup or comment delimiters that are not part of the match.
+func (c *Coverage) remap(t *extract.Text, trim bool) {
+	for i := range c.Match {
+		m := &c.Match[i]
+		start, end := m.Start, m.End
+		for trim && start < end && isSpace(t.Data[start]) {
+			start++
+		}
+		for trim && end > start && isSpace(t.Data[end-1]) {
+			end--
+		}
+		if trim && end < len(t.Data) && t.Data[end] == '\n' {
+			end++
+		}
+		m.Start, m.End = t.Start(start), t.End(end)
+	}
+	for i := range c.Corrections {
+		f := &c.Corrections[i]
+		f.Start, f.End = t.Start(f.Start), t.End(f.End)
+	}
+}
+
+// scan scans text for licenses.
+func (s *Scanner) scan(text []byte, opts ScanOptions) Coverage {
+	matches := s.re.MatchWithOptions(string(text), lre.MatchOptions{SpellCheck: opts.SpellCheck}) // TODO remove conversion
 
 	var c Coverage
 	words := matches.Words
@@ -138,7 +300,7 @@ func (s *Scanner) Scan(text []byte) Coverage {
 	http := s.re.Dict().Lookup("http")
 
 	// Add sentinel match trigger URL scan from last match to end of text.
-	matches.List = append(matches.List, match.Match{Start: len(words), ID: -1})
+	matches.List = append(matches.List, lre.Match{Start: len(words), ID: -1})
 
 	for _, m := range matches.List {
 		if m.Start < len(words) && lastEnd < m.Start && copyright >= 0 {
@@ -190,7 +352,12 @@ func (s *Scanner) Scan(text []byte) Coverage {
 		if m.Start == 0 {
 			start = 0
 		} else {
+			// Words can overlap when split into bigrams
+			// or rewritten (see ScannerOptions).
 			prev := int(words[m.Start-1].Hi)
+			if prev > start {
+				prev = start
+			}
 			if i := bytes.LastIndexByte(text[prev:start], '\n'); i >= 0 {
 				start = prev + i + 1
 			}
@@ -200,21 +367,33 @@ func (s *Scanner) Scan(text []byte) Coverage {
 			end = len(text)
 		} else {
 			next := int(words[m.End].Lo)
+			if next < end {
+				next = end
+			}
 			if i := bytes.IndexByte(text[end:next], '\n'); i >= 0 {
 				end = end + i + 1
 			}
 		}
 		l := &s.licenses[m.ID]
 		c.Match = append(c.Match, Match{
-			ID:    l.ID,
-			Type:  l.Type,
-			Start: start,
-			End:   end,
+			ID:       l.ID,
+			Type:     l.Type,
+			Start:    start,
+			End:      end,
+			Language: l.Language,
 		})
 		total += m.End - m.Start
 		lastEnd = m.End
 	}
 
+	for _, f := range matches.Corrections {
+		c.Corrections = append(c.Corrections, Correction{
+			Start: int(words[f.Start].Lo),
+			End:   int(words[f.End-1].Hi),
+			Want:  f.Want,
+		})
+	}
+
 	if len(words) > 0 { // len(words)==0 should be impossible, but avoid NaN
 		c.Percent = 100.0 * float64(total) / float64(len(words))
 	}
@@ -222,6 +401,10 @@ func (s *Scanner) Scan(text []byte) Coverage {
 	return c
 }
 
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
 // licenseURL reports whether url is a known URL, and returns its name if it is.
 func (s *Scanner) licenseURL(url string) (License, bool) {
 	// We need to canonicalize the text for lookup.
@@ -0,0 +1,245 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck/lre"
+)
+
+func TestNewScannerWithOptions(t *testing.T) {
+	licenses := []License{
+		{ID: "Mine", LRE: "The licensor grants each organization the right to use this program free of charge."},
+	}
+	text := []byte("The Licenser grants each organisation the right to use this software free.")
+
+	s, err := NewScanner(licenses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cov := s.Scan(text); len(cov.Match) != 0 {
+		t.Errorf("NewScanner: Scan matches = %+v, want none", cov.Match)
+	}
+
+	s, err = NewScannerWithOptions(licenses, ScannerOptions{
+		Rewrites: []Rewrite{
+			{"licenser", "licensor"},
+			{"organisation", "organization"},
+			{"software", "program"},
+			{"free of charge", "free"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov := s.Scan(text)
+	if len(cov.Match) != 1 || cov.Match[0].ID != "Mine" || cov.Match[0].Start != 0 || cov.Match[0].End != len(text) {
+		t.Errorf("NewScannerWithOptions: Scan matches = %+v, want Mine at [0:%d]", cov.Match, len(text))
+	}
+
+	// The rewrites do not affect other scanners.
+	if cov := Scan(text); len(cov.Match) != 0 {
+		t.Errorf("Scan matches = %+v, want none", cov.Match)
+	}
+
+	_, err = NewScannerWithOptions(licenses, ScannerOptions{Rewrites: []Rewrite{{"x", ""}}})
+	if err == nil || !strings.Contains(err.Error(), "no words") {
+		t.Errorf("NewScannerWithOptions with empty rewrite: err = %v, want no words error", err)
+	}
+}
+
+func TestScanWithOptions(t *testing.T) {
+	s, err := NewScanner([]License{
+		{ID: "Mine", LRE: "Permission is hereby granted to use and modify this software."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := []byte("Permission is hereby grantd to use and rnodify this software.")
+	i := bytes.Index(text, []byte("grantd"))
+	j := bytes.Index(text, []byte("rnodify"))
+
+	tests := []struct {
+		spell lre.SpellCheck
+		fixes []Correction
+	}{
+		{lre.SpellNone, nil},
+		{lre.SpellDefault, nil},
+		{lre.SpellLoose, []Correction{{i, i + 6, "granted"}, {j, j + 7, "modify"}}},
+	}
+	for _, tt := range tests {
+		cov := s.ScanWithOptions(text, ScanOptions{SpellCheck: tt.spell})
+		if match := len(cov.Match) == 1; match != (tt.fixes != nil) {
+			t.Errorf("ScanWithOptions(SpellCheck: %d): matches = %+v, want match %v", tt.spell, cov.Match, tt.fixes != nil)
+		}
+		if !reflect.DeepEqual(cov.Corrections, tt.fixes) {
+			t.Errorf("ScanWithOptions(SpellCheck: %d): corrections = %+v, want %+v", tt.spell, cov.Corrections, tt.fixes)
+		}
+	}
+
+	// Scan reports corrections made by the default policy.
+	text = []byte("Permission is hereby grantd to use and modify this software.")
+	cov := s.Scan(text)
+	if want := []Correction{{i, i + 6, "granted"}}; len(cov.Match) != 1 || !reflect.DeepEqual(cov.Corrections, want) {
+		t.Errorf("Scan: matches = %+v, corrections = %+v, want match with corrections %+v", cov.Match, cov.Corrections, want)
+	}
+}
+
+func TestNewScannerSegmentation(t *testing.T) {
+	licenses := []License{
+		{ID: "Mine-zh", LRE: "本软件按原样提供 __5__ 不提供任何担保"},
+	}
+	text := []byte("许可证\n\n本软件按原样提供，\n作者不提供任何担保。\n")
+
+	if _, err := NewScanner(licenses); err == nil {
+		t.Errorf("NewScanner with Chinese pattern succeeded, want error")
+	}
+
+	for _, seg := range []lre.Segmentation{lre.SegmentChars, lre.SegmentBigrams} {
+		s, err := NewScannerWithOptions(licenses, ScannerOptions{Segmentation: seg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cov := s.Scan(text)
+		start := bytes.Index(text, []byte("本"))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "Mine-zh" || cov.Match[0].Start != start || cov.Match[0].End != len(text) {
+			t.Errorf("Segmentation %d: Scan matches = %+v, want Mine-zh at [%d:%d]", seg, cov.Match, start, len(text))
+		}
+	}
+}
+
+func TestScanLanguage(t *testing.T) {
+	s, err := NewScanner([]License{
+		{ID: "Mine-1.0", Type: Notice, LRE: "This software is provided without any warranty by its authors."},
+		{ID: "Mine-1.0", Type: Notice, Language: "de", LRE: "Diese Software wird von ihren Urhebern ohne jede Gewährleistung bereitgestellt."},
+		{ID: "Mine-1.0", Type: Notice, Language: "fr", LRE: "Ce logiciel est fourni par ses auteurs sans aucune garantie."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct{ text, lang string }{
+		{"This software is provided without any warranty by its authors.", ""},
+		{"DIESE SOFTWARE WIRD VON IHREN URHEBERN OHNE JEDE GEWAHRLEISTUNG BEREITGESTELLT.", "de"},
+		{"Ce logiciel est fourni par ses auteurs sans aucune garantie.", "fr"},
+	} {
+		cov := s.Scan([]byte(tt.text))
+		if len(cov.Match) != 1 || cov.Match[0].ID != "Mine-1.0" || cov.Match[0].Language != tt.lang {
+			t.Errorf("Scan(%q) = %+v, want Mine-1.0 in language %q", tt.text, cov.Match, tt.lang)
+		}
+	}
+}
+
+func TestScanComments(t *testing.T) {
+	s, err := NewScanner([]License{
+		{ID: "Mine", LRE: "Permission is hereby granted to use __3__ this software."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := []byte(`package main
+
+/*
+ * Permission is hereby granted
+ * to use and modify this software.
+ */
+
+func main() {}
+`)
+	start := bytes.Index(text, []byte("Permission"))
+	end := bytes.Index(text, []byte("software.\n")) + len("software.\n")
+
+	cov := s.ScanWithOptions(text, ScanOptions{Comments: true, FileName: "main.go"})
+	if len(cov.Match) != 1 || cov.Match[0].Start != start || cov.Match[0].End != end || cov.Percent != 100 {
+		t.Errorf("ScanWithOptions(Comments) = %+v, want Mine at [%d:%d], 100%%", cov, start, end)
+	}
+
+	plain := s.Scan(text)
+	if len(plain.Match) != 1 || plain.Percent >= 100 {
+		t.Errorf("Scan = %+v, want one match, less than 100%%", plain)
+	}
+
+	// Unknown languages are scanned as plain text.
+	cov = s.ScanWithOptions(text, ScanOptions{Comments: true, FileName: "main.txt"})
+	if !reflect.DeepEqual(cov, plain) {
+		t.Errorf("ScanWithOptions(Comments, main.txt) = %+v, want %+v", cov, plain)
+	}
+}
+
+func TestScanDecode(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/MIT.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := data[bytes.Index(data, []byte("\n\n"))+2:] // skip test header
+	want := Scan(text)
+	if len(want.Match) != 1 || want.Match[0].ID != "MIT" {
+		t.Fatalf("Scan(MIT.t1) = %+v, want MIT", want)
+	}
+
+	// UTF-16LE with a byte order mark, as written by Windows editors.
+	utf16 := []byte{0xFF, 0xFE}
+	for _, c := range text {
+		utf16 = append(utf16, c, 0)
+	}
+	if cov := Scan(utf16); len(cov.Match) != 0 {
+		t.Errorf("Scan(UTF-16) = %+v, want no matches", cov)
+	}
+	cov := ScanWithOptions(utf16, ScanOptions{Decode: true})
+	if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" || cov.Percent != want.Percent ||
+		cov.Match[0].Start != 2+2*want.Match[0].Start || cov.Match[0].End != 2+2*want.Match[0].End {
+		t.Errorf("ScanWithOptions(UTF-16, Decode) = %+v, want %+v at doubled offsets after BOM", cov, want)
+	}
+
+	// Latin-1 text in a source file comment.
+	src := []byte("# Copyright 2020 Jos\xe9 N\xfa\xf1ez\n# Permission is hereby granted to use and modify this software.\n")
+	s, err := NewScanner([]License{
+		{ID: "Mine", LRE: "José Núñez Permission is hereby granted to use and modify this software."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cov = s.ScanWithOptions(src, ScanOptions{Decode: true, Comments: true, FileName: "x.py"})
+	if len(cov.Match) != 1 || cov.Match[0].Start != 2 || cov.Match[0].End != len(src) {
+		t.Errorf("ScanWithOptions(Latin-1, Decode, Comments) = %+v, want Mine at [2:%d]", cov, len(src))
+	}
+}
+
+func TestScanMarkup(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/MIT.t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data[bytes.Index(data, []byte("\n\n"))+2:]) // skip test header
+
+	// Write the license as RTF, with the paragraphs in bold
+	// and quotes written as control words.
+	// Line breaks in RTF source are not text.
+	var rtf strings.Builder
+	rtf.WriteString(`{\rtf1\ansi{\fonttbl{\f0 Arial;}}\f0` + "\r\n")
+	for _, para := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		para = strings.ReplaceAll(para, "\n", " \r\n")
+		para = strings.ReplaceAll(para, `"`, `\ldblquote `)
+		rtf.WriteString(`\pard\b ` + para + `\b0\par` + "\r\n")
+	}
+	rtf.WriteString("}")
+	src := []byte(rtf.String())
+
+	if cov := Scan(src); len(cov.Match) != 0 {
+		t.Errorf("Scan(RTF) = %+v, want no matches", cov)
+	}
+	cov := ScanWithOptions(src, ScanOptions{Markup: true, FileName: "LICENSE.rtf"})
+	if len(cov.Match) != 1 || cov.Match[0].ID != "MIT" {
+		t.Fatalf("ScanWithOptions(RTF, Markup) = %+v, want MIT", cov)
+	}
+	m := cov.Match[0]
+	if match := string(src[m.Start:m.End]); !strings.HasPrefix(match, "Copyright") || !strings.HasSuffix(match, `SOFTWARE.\b0\par`) {
+		t.Errorf("ScanWithOptions(RTF, Markup) matched %q, want from Copyright to end of last paragraph", match)
+	}
+}
@@ -0,0 +1,140 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// SPDX JSON format.
+
+package spdx
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// The json* types mirror the SPDX 2.3 JSON schema.
+
+type jsonDocument struct {
+	SPDXVersion       string                 `json:"spdxVersion"`
+	DataLicense       string                 `json:"dataLicense"`
+	SPDXID            string                 `json:"SPDXID"`
+	Name              string                 `json:"name"`
+	DocumentNamespace string                 `json:"documentNamespace"`
+	CreationInfo      jsonCreationInfo       `json:"creationInfo"`
+	DocumentDescribes []string               `json:"documentDescribes,omitempty"`
+	Packages          []jsonPackage          `json:"packages,omitempty"`
+	Files             []jsonFile             `json:"files,omitempty"`
+	Licenses          []jsonExtractedLicense `json:"hasExtractedLicensingInfos,omitempty"`
+	Relationships     []jsonRelationship     `json:"relationships,omitempty"`
+}
+
+type jsonCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type jsonPackage struct {
+	SPDXID                  string               `json:"SPDXID"`
+	Name                    string               `json:"name"`
+	VersionInfo             string               `json:"versionInfo,omitempty"`
+	Supplier                string               `json:"supplier"`
+	DownloadLocation        string               `json:"downloadLocation"`
+	FilesAnalyzed           bool                 `json:"filesAnalyzed"`
+	PackageVerificationCode jsonVerificationCode `json:"packageVerificationCode"`
+	LicenseConcluded        string               `json:"licenseConcluded"`
+	LicenseInfoFromFiles    []string             `json:"licenseInfoFromFiles"`
+	LicenseDeclared         string               `json:"licenseDeclared"`
+	CopyrightText           string               `json:"copyrightText"`
+	HasFiles                []string             `json:"hasFiles,omitempty"`
+}
+
+type jsonVerificationCode struct {
+	Value string `json:"packageVerificationCodeValue"`
+}
+
+type jsonFile struct {
+	SPDXID             string         `json:"SPDXID"`
+	FileName           string         `json:"fileName"`
+	Checksums          []jsonChecksum `json:"checksums"`
+	LicenseConcluded   string         `json:"licenseConcluded"`
+	LicenseInfoInFiles []string       `json:"licenseInfoInFiles"`
+	CopyrightText      string         `json:"copyrightText"`
+}
+
+type jsonChecksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+type jsonExtractedLicense struct {
+	LicenseID     string `json:"licenseId"`
+	ExtractedText string `json:"extractedText"`
+	Name          string `json:"name"`
+}
+
+type jsonRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+// WriteJSON writes the document to w in SPDX 2.3 JSON format.
+func (d *Document) WriteJSON(w io.Writer) error {
+	js, err := d.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(js, '\n'))
+	return err
+}
+
+// MarshalJSON returns the document in SPDX 2.3 JSON format.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	jd := jsonDocument{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              d.Name,
+		DocumentNamespace: d.Namespace,
+		CreationInfo: jsonCreationInfo{
+			Created:  d.Created.UTC().Format(time.RFC3339),
+			Creators: d.Creators,
+		},
+	}
+	for _, p := range d.Packages {
+		jd.DocumentDescribes = append(jd.DocumentDescribes, p.SPDXID)
+		jp := jsonPackage{
+			SPDXID:                  p.SPDXID,
+			Name:                    p.Name,
+			VersionInfo:             p.Version,
+			Supplier:                orNoAssertion(p.Supplier),
+			DownloadLocation:        orNoAssertion(p.DownloadLocation),
+			FilesAnalyzed:           true,
+			PackageVerificationCode: jsonVerificationCode{p.VerificationCode},
+			LicenseConcluded:        p.LicenseConcluded,
+			LicenseInfoFromFiles:    p.LicenseInfoFromFiles,
+			LicenseDeclared:         orNoAssertion(p.LicenseDeclared),
+			CopyrightText:           p.CopyrightText,
+		}
+		for _, f := range p.Files {
+			jp.HasFiles = append(jp.HasFiles, f.SPDXID)
+			jd.Files = append(jd.Files, jsonFile{
+				SPDXID:   f.SPDXID,
+				FileName: f.Name,
+				Checksums: []jsonChecksum{
+					{"SHA1", f.SHA1},
+					{"SHA256", f.SHA256},
+				},
+				LicenseConcluded:   NoAssertion,
+				LicenseInfoInFiles: f.LicenseInfoInFile,
+				CopyrightText:      f.CopyrightText,
+			})
+		}
+		jd.Packages = append(jd.Packages, jp)
+		jd.Relationships = append(jd.Relationships, jsonRelationship{"SPDXRef-DOCUMENT", "DESCRIBES", p.SPDXID})
+	}
+	for _, l := range d.Licenses {
+		jd.Licenses = append(jd.Licenses, jsonExtractedLicense{l.ID, l.Text, l.Name})
+	}
+	return json.MarshalIndent(jd, "", "\t")
+}
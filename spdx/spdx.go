@@ -0,0 +1,275 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package spdx writes the results of scanning file trees
+// as SPDX 2.3 documents, in tag-value or JSON format.
+//
+// A Document holds one or more packages, each describing one scanned tree.
+// Each file in a package lists the licenses found in it (LicenseInfoInFile)
+// and the copyright notices extracted from it. Each package records
+// a concluded license: the conjunction of all the licenses found in its files.
+//
+// License IDs that licensecheck defines but SPDX does not, like MIT-NoAd,
+// are written as LicenseRef- IDs (see licensecheck.SPDXLicenseID),
+// and the document defines each one with the license text found in the scan.
+//
+// For example:
+//
+//	doc := spdx.New("example", "https://example.com/spdx/example-1.0")
+//	if _, err := doc.AddPackage(&spdx.Package{Name: "example"}, os.DirFS(dir), ".", nil); err != nil {
+//		log.Fatal(err)
+//	}
+//	doc.WriteTagValue(os.Stdout)
+package spdx
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/internal/sbom"
+)
+
+// NoAssertion is the SPDX value meaning that no assertion is made.
+const NoAssertion = "NOASSERTION"
+
+// None is the SPDX value meaning that a field has no value,
+// such as a file with no license information.
+const None = "NONE"
+
+// A Document is an SPDX document describing scanned packages.
+type Document struct {
+	Name      string    // document name
+	Namespace string    // unique URI identifying the document
+	Creators  []string  // like "Tool: licensecheck" or "Organization: Example"
+	Created   time.Time // creation time
+
+	Packages []*Package
+	Licenses []*ExtractedLicense // definitions of LicenseRef- IDs used in the document
+
+	nfile   int             // number of files added, for SPDX IDs
+	urlText map[string]bool // Licenses whose Text is only a URL
+}
+
+// A Package describes a single package in a Document.
+// The caller of AddPackage sets the identifying fields;
+// AddPackage sets the rest from the scan.
+type Package struct {
+	// Set by the caller.
+	Name             string
+	Version          string
+	Supplier         string // like "Organization: Example"; empty means NOASSERTION
+	DownloadLocation string // empty means NOASSERTION
+	LicenseDeclared  string // SPDX expression; empty means NOASSERTION
+
+	// Set by AddPackage.
+	SPDXID               string
+	VerificationCode     string
+	LicenseConcluded     string   // SPDX expression, NOASSERTION, or NONE
+	LicenseInfoFromFiles []string // SPDX license IDs found in files, sorted
+	CopyrightText        string   // copyright notices, one per line, or NONE
+	Files                []*File
+}
+
+// A File describes a single file in a Package.
+type File struct {
+	SPDXID            string
+	Name              string   // relative path starting with "./"
+	SHA1              string   // hex SHA-1 checksum
+	SHA256            string   // hex SHA-256 checksum
+	LicenseInfoInFile []string // SPDX license IDs, or [NONE]
+	CopyrightText     string   // copyright notices, one per line, or NONE
+}
+
+// An ExtractedLicense defines a LicenseRef- ID used in the document.
+type ExtractedLicense struct {
+	ID   string // LicenseRef-...
+	Name string // licensecheck license ID
+	Text string // license text, as found in the scanned files
+}
+
+// New returns a new, empty document with the given name and namespace.
+// The document's creator is "Tool: licensecheck",
+// and its creation time is the current time.
+func New(name, namespace string) *Document {
+	return &Document{
+		Name:      name,
+		Namespace: namespace,
+		Creators:  []string{"Tool: licensecheck"},
+		Created:   time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+// AddPackage adds the package p, whose files are the file tree rooted at root in fsys,
+// to the document. The scan is the result of licensecheck.ScanFS (or a Scanner's
+// ScanFS method) for the same tree; if scan is nil, AddPackage calls licensecheck.ScanFS.
+//
+// AddPackage fills in p's SPDXID, VerificationCode, LicenseConcluded,
+// LicenseInfoFromFiles, CopyrightText, and Files fields, and it rewrites
+// any licensecheck-only IDs in p.LicenseDeclared as LicenseRef- IDs.
+// It returns p.
+func (d *Document) AddPackage(p *Package, fsys fs.FS, root string, scan []licensecheck.FileCoverage) (*Package, error) {
+	files, err := sbom.Walk(fsys, root, scan)
+	if err != nil {
+		return nil, err
+	}
+
+	p.SPDXID = d.uniquePackageID(p.Name)
+	p.LicenseDeclared = Expression(p.LicenseDeclared)
+	p.Files = nil
+
+	var sums []string
+	var copyrights []string
+	seenCopyright := make(map[string]bool)
+	var concluded []string
+	seenID := make(map[string]bool)
+	for _, f := range files {
+		d.nfile++
+		rel := strings.TrimPrefix(f.Path, root+"/")
+		if root == "." {
+			rel = f.Path
+		}
+		sf := &File{
+			SPDXID:        fmt.Sprintf("SPDXRef-File-%d", d.nfile),
+			Name:          "./" + rel,
+			SHA1:          f.SHA1,
+			SHA256:        f.SHA256,
+			CopyrightText: copyrightText(f.Copyrights),
+		}
+		for i, m := range f.Match {
+			id := licensecheck.SPDXLicenseID(m.ID)
+			if !contains(sf.LicenseInfoInFile, id) {
+				sf.LicenseInfoInFile = append(sf.LicenseInfoInFile, id)
+			}
+			if !seenID[id] {
+				seenID[id] = true
+				concluded = append(concluded, id)
+			}
+			if id != m.ID {
+				d.addLicense(id, m.ID, f.Texts[i], m.IsURL)
+			}
+		}
+		if len(sf.LicenseInfoInFile) == 0 {
+			sf.LicenseInfoInFile = []string{None}
+		}
+		for _, c := range f.Copyrights {
+			if !seenCopyright[c] {
+				seenCopyright[c] = true
+				copyrights = append(copyrights, c)
+			}
+		}
+		sums = append(sums, f.SHA1)
+		p.Files = append(p.Files, sf)
+	}
+
+	p.VerificationCode = verificationCode(sums)
+	sort.Strings(concluded)
+	p.LicenseInfoFromFiles = concluded
+	switch len(concluded) {
+	case 0:
+		p.LicenseConcluded = NoAssertion
+		p.LicenseInfoFromFiles = []string{None}
+	default:
+		p.LicenseConcluded = strings.Join(concluded, " AND ")
+	}
+	p.CopyrightText = copyrightText(copyrights)
+	d.Packages = append(d.Packages, p)
+	return p, nil
+}
+
+// uniquePackageID returns a new SPDX ID for a package with the given name.
+func (d *Document) uniquePackageID(name string) string {
+	base := "SPDXRef-Package-" + idString(name)
+	id := base
+	for n := 2; ; n++ {
+		dup := false
+		for _, p := range d.Packages {
+			if p.SPDXID == id {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			return id
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// addLicense records the definition of a LicenseRef- ID,
+// unless the document already has one.
+// A definition using the license text replaces one using only a URL.
+func (d *Document) addLicense(id, name, text string, isURL bool) {
+	if d.urlText == nil {
+		d.urlText = make(map[string]bool)
+	}
+	for _, l := range d.Licenses {
+		if l.ID == id {
+			if d.urlText[id] && !isURL {
+				l.Text = text
+				d.urlText[id] = false
+			}
+			return
+		}
+	}
+	d.urlText[id] = isURL
+	d.Licenses = append(d.Licenses, &ExtractedLicense{ID: id, Name: name, Text: text})
+	sort.Slice(d.Licenses, func(i, j int) bool { return d.Licenses[i].ID < d.Licenses[j].ID })
+}
+
+// Expression returns the SPDX expression x with each licensecheck-only
+// license ID replaced by its LicenseRef- ID (see licensecheck.SPDXExpression).
+// If x is empty, Expression returns NOASSERTION.
+func Expression(x string) string {
+	if strings.TrimSpace(x) == "" {
+		return NoAssertion
+	}
+	return licensecheck.SPDXExpression(x)
+}
+
+// verificationCode returns the SPDX package verification code
+// for the files with the given SHA-1 checksums.
+func verificationCode(sums []string) string {
+	list := append([]string{}, sums...)
+	sort.Strings(list)
+	sum := sha1.Sum([]byte(strings.Join(list, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// copyrightText returns the SPDX copyright text for the list of notices.
+func copyrightText(list []string) string {
+	if len(list) == 0 {
+		return None
+	}
+	return strings.Join(list, "\n")
+}
+
+// idString returns s with all characters not allowed
+// in an SPDX identifier replaced by "-".
+func idString(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '-'
+	}, s)
+	if s == "" {
+		s = "root"
+	}
+	return s
+}
+
+func contains(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,174 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spdx
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// testText returns the license text from ../testdata/name,
+// without the test header.
+func testText(t *testing.T, name string) []byte {
+	data, err := ioutil.ReadFile("../testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := strings.Index(string(data), "\n\n")
+	if i < 0 {
+		t.Fatalf("%s: malformed test file", name)
+	}
+	return data[i+2:]
+}
+
+func testDocument(t *testing.T) *Document {
+	fsys := fstest.MapFS{
+		"pkg/LICENSE":   {Data: append([]byte("Copyright (c) 2020 The Authors\n\n"), testText(t, "MIT.t1")...)},
+		"pkg/PATENTS":   {Data: testText(t, "GooglePatentsFile.t1")},
+		"pkg/main.go":   {Data: []byte("// Copyright 2020 The Authors. All rights reserved.\n\npackage main\n")},
+		"pkg/.git/HEAD": {Data: []byte("ref: refs/heads/main\n")},
+	}
+	d := New("pkg", "https://example.com/spdx/pkg")
+	d.Created = time.Date(2020, 12, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := d.AddPackage(&Package{Name: "pkg", Version: "v1.0.0", LicenseDeclared: "MIT AND GooglePatentsFile"}, fsys, "pkg", nil); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestAddPackage(t *testing.T) {
+	d := testDocument(t)
+	if len(d.Packages) != 1 {
+		t.Fatalf("have %d packages, want 1", len(d.Packages))
+	}
+	p := d.Packages[0]
+	if p.SPDXID != "SPDXRef-Package-pkg" {
+		t.Errorf("SPDXID = %q", p.SPDXID)
+	}
+	if want := "LicenseRef-GooglePatentsFile AND MIT"; p.LicenseConcluded != want {
+		t.Errorf("LicenseConcluded = %q, want %q", p.LicenseConcluded, want)
+	}
+	if want := "MIT AND LicenseRef-GooglePatentsFile"; p.LicenseDeclared != want {
+		t.Errorf("LicenseDeclared = %q, want %q", p.LicenseDeclared, want)
+	}
+	if want := "Copyright (c) 2020 The Authors\nCopyright 2020 The Authors. All rights reserved."; p.CopyrightText != want {
+		t.Errorf("CopyrightText = %q, want %q", p.CopyrightText, want)
+	}
+
+	var names []string
+	var infos [][]string
+	for _, f := range p.Files {
+		names = append(names, f.Name)
+		infos = append(infos, f.LicenseInfoInFile)
+	}
+	if want := []string{"./LICENSE", "./PATENTS", "./main.go"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %q, want %q", names, want)
+	}
+	if want := [][]string{{"MIT"}, {"LicenseRef-GooglePatentsFile"}, {"NONE"}}; !reflect.DeepEqual(infos, want) {
+		t.Errorf("LicenseInfoInFile = %q, want %q", infos, want)
+	}
+
+	if len(d.Licenses) != 1 || d.Licenses[0].ID != "LicenseRef-GooglePatentsFile" || d.Licenses[0].Name != "GooglePatentsFile" ||
+		!strings.Contains(d.Licenses[0].Text, "patent") {
+		t.Errorf("Licenses = %+v, want LicenseRef-GooglePatentsFile with text", d.Licenses)
+	}
+
+	// Adding another package with the same name gets a new ID and file IDs.
+	fsys := fstest.MapFS{"x": {Data: []byte("x")}}
+	p2, err := d.AddPackage(&Package{Name: "pkg"}, fsys, ".", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p2.SPDXID != "SPDXRef-Package-pkg-2" || len(p2.Files) != 1 || p2.Files[0].SPDXID != "SPDXRef-File-4" || p2.Files[0].Name != "./x" {
+		t.Errorf("second package = %s %+v", p2.SPDXID, p2.Files[0])
+	}
+	if p2.LicenseConcluded != NoAssertion || p2.LicenseDeclared != NoAssertion {
+		t.Errorf("second package licenses = %q, %q, want NOASSERTION", p2.LicenseConcluded, p2.LicenseDeclared)
+	}
+}
+
+func TestWriteTagValue(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testDocument(t).WriteTagValue(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"SPDXVersion: SPDX-2.3\n",
+		"DocumentNamespace: https://example.com/spdx/pkg\n",
+		"Created: 2020-12-01T00:00:00Z\n",
+		"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-Package-pkg\n",
+		"PackageVersion: v1.0.0\n",
+		"PackageLicenseConcluded: LicenseRef-GooglePatentsFile AND MIT\n",
+		"PackageLicenseInfoFromFiles: MIT\n",
+		"PackageLicenseDeclared: MIT AND LicenseRef-GooglePatentsFile\n",
+		"FileName: ./LICENSE\nSPDXID: SPDXRef-File-1\nFileChecksum: SHA1: ",
+		"LicenseInfoInFile: MIT\nFileCopyrightText: <text>Copyright (c) 2020 The Authors</text>\n",
+		"LicenseInfoInFile: NONE\n",
+		"Relationship: SPDXRef-Package-pkg CONTAINS SPDXRef-File-3\n",
+		"LicenseID: LicenseRef-GooglePatentsFile\nExtractedText: <text>",
+		"LicenseName: GooglePatentsFile\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("WriteTagValue output missing %q", line)
+		}
+	}
+	if strings.Contains(out, ".git") {
+		t.Errorf("WriteTagValue output includes .git directory")
+	}
+	if t.Failed() {
+		t.Logf("output:\n%s", out)
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := testDocument(t).WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var js struct {
+		SPDXVersion string
+		Packages    []struct {
+			LicenseConcluded string
+			HasFiles         []string
+		}
+		Files []struct {
+			FileName           string
+			LicenseInfoInFiles []string
+			Checksums          []struct{ Algorithm string }
+		}
+		HasExtractedLicensingInfos []struct{ LicenseID string }
+	}
+	if err := json.Unmarshal(buf.Bytes(), &js); err != nil {
+		t.Fatal(err)
+	}
+	if js.SPDXVersion != "SPDX-2.3" ||
+		len(js.Packages) != 1 || js.Packages[0].LicenseConcluded != "LicenseRef-GooglePatentsFile AND MIT" || len(js.Packages[0].HasFiles) != 3 ||
+		len(js.Files) != 3 || js.Files[1].FileName != "./PATENTS" || js.Files[1].LicenseInfoInFiles[0] != "LicenseRef-GooglePatentsFile" ||
+		len(js.Files[0].Checksums) != 2 || js.Files[0].Checksums[0].Algorithm != "SHA1" ||
+		len(js.HasExtractedLicensingInfos) != 1 || js.HasExtractedLicensingInfos[0].LicenseID != "LicenseRef-GooglePatentsFile" {
+		t.Errorf("WriteJSON output:\n%s", buf.String())
+	}
+}
+
+func TestExpression(t *testing.T) {
+	for _, tt := range []struct{ in, out string }{
+		{"", "NOASSERTION"},
+		{"MIT", "MIT"},
+		{"(MIT-NoAd OR Apache-2.0) AND Anti996", "(LicenseRef-MIT-NoAd OR Apache-2.0) AND LicenseRef-Anti996"},
+		{"GPL-2.0-or-later WITH Classpath-exception-2.0", "GPL-2.0-or-later WITH Classpath-exception-2.0"},
+		{"LicenseRef-Mine", "LicenseRef-Mine"},
+	} {
+		if out := Expression(tt.in); out != tt.out {
+			t.Errorf("Expression(%q) = %q, want %q", tt.in, out, tt.out)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// SPDX tag-value format.
+
+package spdx
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteTagValue writes the document to w in SPDX 2.3 tag-value format.
+func (d *Document) WriteTagValue(w io.Writer) error {
+	b := bufio.NewWriter(w)
+	tag := func(name, value string) {
+		fmt.Fprintf(b, "%s: %s\n", name, value)
+	}
+
+	tag("SPDXVersion", "SPDX-2.3")
+	tag("DataLicense", "CC0-1.0")
+	tag("SPDXID", "SPDXRef-DOCUMENT")
+	tag("DocumentName", d.Name)
+	tag("DocumentNamespace", d.Namespace)
+	for _, c := range d.Creators {
+		tag("Creator", c)
+	}
+	tag("Created", d.Created.UTC().Format(time.RFC3339))
+	for _, p := range d.Packages {
+		tag("Relationship", "SPDXRef-DOCUMENT DESCRIBES "+p.SPDXID)
+	}
+
+	for _, p := range d.Packages {
+		fmt.Fprintf(b, "\n##### Package: %s\n\n", p.Name)
+		tag("PackageName", p.Name)
+		tag("SPDXID", p.SPDXID)
+		if p.Version != "" {
+			tag("PackageVersion", p.Version)
+		}
+		tag("PackageSupplier", orNoAssertion(p.Supplier))
+		tag("PackageDownloadLocation", orNoAssertion(p.DownloadLocation))
+		tag("FilesAnalyzed", "true")
+		tag("PackageVerificationCode", p.VerificationCode)
+		tag("PackageLicenseConcluded", p.LicenseConcluded)
+		for _, id := range p.LicenseInfoFromFiles {
+			tag("PackageLicenseInfoFromFiles", id)
+		}
+		tag("PackageLicenseDeclared", orNoAssertion(p.LicenseDeclared))
+		tag("PackageCopyrightText", text(p.CopyrightText))
+
+		for _, f := range p.Files {
+			fmt.Fprintf(b, "\n")
+			tag("FileName", f.Name)
+			tag("SPDXID", f.SPDXID)
+			tag("FileChecksum", "SHA1: "+f.SHA1)
+			tag("FileChecksum", "SHA256: "+f.SHA256)
+			tag("LicenseConcluded", NoAssertion)
+			for _, id := range f.LicenseInfoInFile {
+				tag("LicenseInfoInFile", id)
+			}
+			tag("FileCopyrightText", text(f.CopyrightText))
+			tag("Relationship", p.SPDXID+" CONTAINS "+f.SPDXID)
+		}
+	}
+
+	if len(d.Licenses) > 0 {
+		fmt.Fprintf(b, "\n##### Other Licenses\n")
+	}
+	for _, l := range d.Licenses {
+		fmt.Fprintf(b, "\n")
+		tag("LicenseID", l.ID)
+		tag("ExtractedText", text(l.Text))
+		tag("LicenseName", l.Name)
+	}
+
+	return b.Flush()
+}
+
+// text returns the tag-value form of a free-form text value:
+// NONE and NOASSERTION are written as is, and
+// anything else is wrapped in <text> and </text>.
+func text(s string) string {
+	if s == None || s == NoAssertion {
+		return s
+	}
+	// The text cannot contain the closing tag.
+	s = strings.ReplaceAll(s, "</text>", "&lt;/text>")
+	return "<text>" + s + "</text>"
+}
+
+func orNoAssertion(s string) string {
+	if s == "" {
+		return NoAssertion
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+// Copyright 2020 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package licensecheck_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/licensecheck"
+	"github.com/google/licensecheck/lretest"
+)
+
+// TestTestdata runs the test files in testdata (see testdata/README)
+// against the built-in licenses. It lives in package licensecheck_test
+// because lretest imports licensecheck.
+func TestTestdata(t *testing.T) {
+	files, err := filepath.Glob("testdata/*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no testdata files found")
+	}
+
+	types := make(map[string]licensecheck.Type)
+	for _, l := range licensecheck.BuiltinLicenses() {
+		// An ID can appear more than once; the first entry gives its type.
+		if _, ok := types[l.ID]; !ok {
+			types[l.ID] = l.Type
+		}
+	}
+
+	for _, file := range files {
+		name := filepath.Base(file)
+		if name == "README" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil && info.IsDir() {
+			continue
+		}
+		if !strings.Contains(file, ".t") {
+			t.Errorf("unexpected file: %v", file)
+		}
+		file := file
+		t.Run(name, func(t *testing.T) {
+			t.Parallel() // faster and tests for races in parallel usage
+
+			data, err := ioutil.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c, err := lretest.ParseCase(file, data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := c.Run(nil)
+			for _, m := range r.Have.Match {
+				if typ := types[m.ID]; m.Type != typ {
+					t.Errorf("%s: match %s has Type=%s, want %s", file, m.ID, m.Type, typ)
+				}
+			}
+			if r.Failed() {
+				t.Errorf("%s: diff -want +have:\n%s", file, r.Diff)
+			}
+		})
+	}
+}
@@ -54,15 +54,6 @@ func TestTypeMerge(t *testing.T) {
 	}
 }
 
-func licenseType(id string) Type {
-	for _, l := range builtinLREs {
-		if l.ID == id {
-			return l.Type
-		}
-	}
-	return Unknown
-}
-
 var licenseTypeTests = map[string]Type{
 	"WTFPL": Discouraged,
 }